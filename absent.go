@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// PutIfAbsent puts a value in cache at the given key, like Put does,
+// only if there is no item at the key, and returns whether it has been
+// put, e.g., to claim the key. Items that have expired, but not been
+// dropped yet, are replaced.
+func (c *Cache[K, V]) PutIfAbsent(key K, value V, opts ...CallOption) bool {
+	o := c.callOptions(key, opts)
+	if !c.keyOK(key) {
+		return false
+	}
+	c.lock()
+	defer c.unlock()
+	if val, found := c.d[key]; found && val.t.x.After(time.Now()) {
+		return false
+	}
+	return c.set(key, value, o) == nil
+}
+
+// DropIf drops the cached item at key, like Drop does, only if f
+// reports true for its value, and returns whether it has been dropped,
+// e.g., to release a claim on the key, but not a value put since.
+//
+// The cache is locked while f is called, so f must not use it.
+func (c *Cache[K, V]) DropIf(key K, f func(value V) bool) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found || !f(val.Value()) {
+		return false
+	}
+	c.drop(key, val, ReasonDropped)
+	return true
+}
@@ -0,0 +1,41 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestPutIfAbsent(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.Assert(c.PutIfAbsent("a", 1, TTL(ttl)), "should put a")
+	req.AssertNot(c.PutIfAbsent("a", 2), "should not put a again")
+	req.Assert(req.Get("a") == 1, "a should be 1")
+	time.Sleep(3 * ttl)
+	req.Assert(c.PutIfAbsent("a", 3), "should put a once expired")
+	req.Assert(req.Get("a") == 3, "a should be 3")
+}
+
+func TestDropIf(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	c.Put("a", 1)
+
+	odd := func(v int) bool { return v%2 == 1 }
+	req.AssertNot(c.DropIf("b", odd), "should not drop missing b")
+	c.Put("a", 2)
+	req.AssertNot(c.DropIf("a", odd), "should not drop even a")
+	req.Has("a")
+	c.Put("a", 3)
+	req.Assert(c.DropIf("a", odd), "should drop odd a")
+	req.HasNot("a")
+}
@@ -0,0 +1,60 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "sync"
+
+// Acquire gets a cached item, like Get does, and borrows its value
+// until release is called. Should the item leave the cache while its
+// value is borrowed, the OnEvict function is not called with it until
+// all borrowers have released it, so that, e.g., a connection is not
+// closed while in use.
+//
+// Putting a new value at the key ends the borrowing of the old one for
+// the purposes of OnEvict, which is not called with replaced values.
+func (c *Cache[K, V]) Acquire(key K) (value V, release func(), ok bool) {
+	c.lock()
+	val, found := c.find(key, true)
+	c.lookup(key, found)
+	if !found {
+		c.unlock()
+		return
+	}
+	l := val.lease
+	if l == nil {
+		l = &lease[K, V]{}
+		val.lease = l
+		c.d[key] = val
+	}
+	l.n++
+	c.unlock()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() { c.release(l) })
+	}
+	value, ok = c.read(key, val.Value(), true)
+	return value, release, ok
+}
+
+// Internals.
+
+// A lease of a value to its borrowers.
+type lease[K comparable, V any] struct {
+	n int             // Number of borrowers.
+	e *eviction[K, V] // Of the item, if it has left the cache.
+}
+
+// release a borrowed value, reporting the eviction of its item, if it
+// has left the cache, once the last borrower releases it.
+func (c *Cache[K, V]) release(l *lease[K, V]) {
+	c.lock()
+	defer c.unlock()
+	l.n--
+	if l.n == 0 && l.e != nil {
+		c.evicted = append(c.evicted, *l.e)
+		l.e = nil
+	}
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestAcquire(t *testing.T) {
+	var closed []int
+	c := New[string, int](time.Minute, WithOnEvict(func(_ string, v int, _ EvictReason) {
+		closed = append(closed, v)
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, _, ok := c.Acquire("a")
+	req.AssertNot(ok, "should not acquire an absent item")
+
+	c.Put("a", 1)
+	v, release1, ok := c.Acquire("a")
+	req.Assert(ok && v == 1, "Acquire(a) got=%d, %t, want=1, true", v, ok)
+	_, release2, _ := c.Acquire("a")
+	c.Drop("a")
+	req.HasNot("a")
+	req.Assert(len(closed) == 0, "closed got=%v while borrowed", closed)
+
+	release1()
+	release1() // Releasing again has no effect.
+	req.Assert(len(closed) == 0, "closed got=%v while borrowed", closed)
+	release2()
+	req.Assert(len(closed) == 1 && closed[0] == 1, "closed got=%v, want=[1]", closed)
+
+	// Items released before they leave the cache are reported right away.
+	c.Put("b", 2)
+	_, release, _ := c.Acquire("b")
+	release()
+	c.Drop("b")
+	req.Assert(len(closed) == 2 && closed[1] == 2, "closed got=%v, want=[1 2]", closed)
+
+	// A new value is not borrowed.
+	c.Put("c", 3)
+	_, release, _ = c.Acquire("c")
+	c.Put("c", 4)
+	c.Drop("c")
+	req.Assert(len(closed) == 3 && closed[2] == 4, "closed got=%v, want=[1 2 4]", closed)
+	release()
+	req.Assert(len(closed) == 3, "closed got=%v, want=[1 2 4]", closed)
+}
@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// Age returns the time since the value of the cached item at key was
+// put, e.g., for the Age header of an HTTP response, without extending
+// the lifetime of the item. Returns false if the key has not been found
+// in the cache.
+func (c *Cache[K, V]) Age(key K) (time.Duration, bool) {
+	c.lock()
+	defer c.unlock()
+	val, found := c.find(key, false)
+	if !found {
+		return 0, false
+	}
+	return time.Since(val.put), true
+}
+
+// GetIfNewer gets a cached item, like Get does, only if its value has
+// been put after since, e.g., for delta synchronization. Returns false
+// if the key has not been found in the cache, or the value is older.
+func (c *Cache[K, V]) GetIfNewer(key K, since time.Time) (value V, ok bool) {
+	c.lock()
+	val, found := c.find(key, false)
+	if ok = found && val.put.After(since); ok && !c.o.noSlide {
+		c.slide(val)
+	}
+	c.lookup(key, ok)
+	c.unlock()
+	return c.read(key, val.Value(), ok)
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestAge(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+
+	if _, ok := c.Age("a"); ok {
+		t.Fatal("Age(a) should not find 'a'")
+	}
+	c.Put("a", 1)
+	time.Sleep(ttl)
+	c.Get("a") // Lookups do not make it younger.
+	if age, ok := c.Age("a"); !ok || age < ttl || age > time.Minute {
+		t.Errorf("Age(a) got=%v,%v, want about %v", age, ok, ttl)
+	}
+	c.Put("a", 2)
+	if age, _ := c.Age("a"); age >= ttl {
+		t.Errorf("Age(a) after Put got=%v, want under %v", age, ttl)
+	}
+}
+
+func TestGetIfNewer(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	since := time.Now()
+	if _, ok := c.GetIfNewer("a", since); ok {
+		t.Error("GetIfNewer(a) should not return an older value")
+	}
+	if v, ok := c.GetIfNewer("a", since.Add(-time.Second)); !ok || v != 1 {
+		t.Errorf("GetIfNewer(a) got=%v,%v, want=1,true", v, ok)
+	}
+	c.Put("a", 2)
+	if v, ok := c.GetIfNewer("a", since); !ok || v != 2 {
+		t.Errorf("GetIfNewer(a) after Put got=%v,%v, want=2,true", v, ok)
+	}
+	if _, ok := c.GetIfNewer("b", since); ok {
+		t.Error("GetIfNewer(b) should not find 'b'")
+	}
+	req.Assert(c.Stats().Hits == 2, "Stats().Hits got=%d, want=2", c.Stats().Hits)
+}
@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// PutMany puts the values in cache at their keys, like Put does for
+// each of them, but locking the cache once, e.g., to warm it up with a
+// lot of items fast. The keys that fail the key checks are skipped.
+func (c *Cache[K, V]) PutMany(items map[K]V, opts ...CallOption) {
+	c.putMany(items, func(key K) callOptions {
+		return c.callOptions(key, opts)
+	})
+}
+
+// PutManyWithTTL puts the values in cache at their keys, with the given
+// time-to-live, like PutMany does.
+func (c *Cache[K, V]) PutManyWithTTL(items map[K]V, ttl time.Duration) {
+	o := callOptions{ttl: ttl, ttlSet: true}
+	c.putMany(items, func(K) callOptions { return o })
+}
+
+// DropMany drops the cached items at keys at once, so that no other
+// call sees only some of them dropped, e.g., to invalidate several
+// related items after an upstream write. Returns the number of the
+// items that have been found in the cache.
+func (c *Cache[K, V]) DropMany(keys ...K) (n int) {
+	c.lock()
+	defer c.unlock()
+	for _, k := range keys {
+		if val, found := c.d[k]; found {
+			c.drop(k, val, ReasonDropped)
+			n++
+		}
+	}
+	return n
+}
+
+// Clear drops all the items in the cache at once, like Drop does, and
+// returns their number.
+func (c *Cache[K, V]) Clear() (n int) {
+	c.lock()
+	defer c.unlock()
+	n = len(c.d)
+	for k, val := range c.d {
+		// The dependents dropped along with the items are not visited.
+		c.drop(k, val, ReasonDropped)
+	}
+	// Release the memory held by the timers.
+	c.th = nil
+	c.t.Reset(indefinite)
+	return n
+}
+
+// Internals.
+
+// putMany puts the values in cache at their keys with the options
+// returned by opts.
+func (c *Cache[K, V]) putMany(items map[K]V, opts func(K) callOptions) {
+	var skip map[K]bool
+	if c.checkKey != nil {
+		skip = make(map[K]bool)
+		for k := range items {
+			skip[k] = c.checkKey(k) != nil
+		}
+	}
+	c.lock()
+	defer c.unlock()
+	// Nothing is evicted from a cache without limits, so the order of
+	// its timers can be restored once, after they are all added.
+	c.bulk = !c.limited()
+	for k, v := range items {
+		if !skip[k] {
+			c.set(k, v, opts(k))
+		}
+	}
+	if c.bulk {
+		c.bulk = false
+		heap.Init(&c.th)
+		if len(c.th) > 0 {
+			c.t.Reset(time.Until(c.th[0].x))
+		}
+	}
+}
+
+// limited returns whether the cache has any limits to evict items at.
+func (c *Cache[K, V]) limited() bool {
+	return c.o.capacity > 0 || c.o.maxBytes > 0 || len(c.o.budgets) > 0
+}
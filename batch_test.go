@@ -0,0 +1,123 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestPutMany(t *testing.T) {
+	c := New[int, int](time.Minute, WithKeyCheck(func(k int) error {
+		if k < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put(1, 0)
+	items := map[int]int{-1: -1}
+	for i := 0; i < 100; i++ {
+		items[i] = i
+	}
+	c.PutMany(items)
+	req.LengthIs(100)
+	req.HasNot(-1)
+	if v, ok := c.Get(1); !ok || v != 1 {
+		t.Errorf("Get(1) got=%v,%v, want=1,true", v, ok)
+	}
+
+	c.PutManyWithTTL(map[int]int{200: 1, 201: 2}, ttl)
+	if vs := c.Victims(2); len(vs) != 2 || vs[0] < 200 || vs[1] < 200 {
+		t.Errorf("Victims(2) got=%v, want 200 and 201", vs)
+	}
+	time.Sleep(2 * ttl)
+	req.HasNot(200)
+	req.HasNot(201)
+	req.LengthIs(100)
+	if err := c.CheckIntegrity(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPutManyCapacity(t *testing.T) {
+	c := New[int, int](time.Minute, WithCapacity(10))
+	defer c.Shutdown()
+	items := map[int]int{}
+	for i := 0; i < 100; i++ {
+		items[i] = i
+	}
+	c.PutMany(items)
+	newAssert(t, c, true).LengthIs(10)
+	if err := c.CheckIntegrity(); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkPutMany(b *testing.B) {
+	items := make(map[int]int, 100000)
+	for i := 0; i < 100000; i++ {
+		items[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := New[int, int](time.Minute)
+		c.PutMany(items)
+		c.Shutdown()
+	}
+}
+
+func TestDropMany(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	c.PutMany(map[string]int{"a": 1, "b": 2, "c": 3})
+
+	if n := c.DropMany("a", "b", "x", "a"); n != 2 {
+		t.Errorf("DropMany(a, b, x, a) got=%d, want=2", n)
+	}
+	req.HasNot("a")
+	req.HasNot("b")
+	req.Has("c")
+	if n := c.DropMany(); n != 0 {
+		t.Errorf("DropMany() got=%d, want=0", n)
+	}
+}
+
+func TestClear(t *testing.T) {
+	var evicted []string
+	c := New[string, int](time.Minute, WithOnEvict(func(k string, _ int, _ EvictReason) {
+		evicted = append(evicted, k)
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	c.PutMany(map[string]int{"a": 1, "b": 2})
+	c.Put("c", 3, Pin())
+	c.Tag("a", "t")
+	c.DependOn("b", "c")
+
+	if n := c.Clear(); n != 3 {
+		t.Errorf("Clear() got=%d, want=3", n)
+	}
+	req.LengthIs(0)
+	if len(evicted) != 3 {
+		t.Errorf("evicted %v, want all 3", evicted)
+	}
+	if s := c.Stats(); s.Bytes != 0 {
+		t.Errorf("Stats().Bytes got=%d, want=0", s.Bytes)
+	}
+	if err := c.CheckIntegrity(); err != nil {
+		t.Error(err)
+	}
+
+	c.PutWithTTL("d", 4, ttl)
+	time.Sleep(2 * ttl)
+	req.HasNot("d")
+}
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// WithClassBudgets limits the total size of the items of each class in
+// a Cache of the same key and value types to its budget, in bytes,
+// independently of the other classes, so that, e.g., thumbnails cannot
+// starve metadata of space. Items of classes without a budget are only
+// subject to the limits of the cache.
+//
+// The class of an item is returned by class, e.g., the namespace of its
+// key, when its value is put. While a class exceeds its budget, its
+// items closest to expiry are evicted.
+//
+// New panics if the types do not match those of the cache.
+func WithClassBudgets[K comparable, V any](
+	class func(key K, value V) string,
+	budgets map[string]int64,
+) Option {
+	return func(o *options) {
+		o.classOf = class
+		o.budgets = budgets
+	}
+}
+
+// ClassBytes returns the total size of the cached items of class.
+func (c *Cache[K, V]) ClassBytes(class string) int64 {
+	c.lock()
+	defer c.m.Unlock()
+	return c.classBytes[class]
+}
+
+// Internals.
+
+// classOfOf returns the function set by WithClassBudgets.
+func classOfOf[K comparable, V any](o options) func(K, V) string {
+	if o.classOf == nil {
+		return nil
+	}
+	f, ok := o.classOf.(func(K, V) string)
+	if !ok {
+		panic("cache: WithClassBudgets types do not match those of the cache")
+	}
+	return f
+}
+
+// weigh the item val replacing old in its class, if classified.
+func (c *Cache[K, V]) weigh(key K, val *entry[K, V], old entry[K, V]) {
+	if c.classOf == nil {
+		return
+	}
+	if val.cold == nil {
+		// Compressing values does not change their classes.
+		val.class = c.classOf(key, val.v)
+	}
+	if c.classBytes == nil {
+		c.classBytes = make(map[string]int64)
+	}
+	c.classBytes[old.class] -= old.size
+	c.classBytes[val.class] += val.size
+}
+
+// unweigh the item val leaving its class, if classified.
+func (c *Cache[K, V]) unweigh(val entry[K, V]) {
+	if c.classOf != nil {
+		c.classBytes[val.class] -= val.size
+	}
+}
+
+// evictClasses evicts the items closest to expiry from the classes that
+// exceed their budgets. Pinned items are never evicted.
+func (c *Cache[K, V]) evictClasses() {
+	for class, budget := range c.o.budgets {
+		for budget > 0 && c.classBytes[class] > budget {
+			t := c.victim(func(val entry[K, V]) bool {
+				return val.class == class && !val.pin
+			})
+			if t == nil {
+				break
+			}
+			c.unschedule(t)
+			c.remove(t.k, ReasonEvicted)
+			c.stats.Evicted++
+		}
+	}
+}
+
+// soonest returns the timer of the item closest to expiry that match
+// reports true for, or nil if none does.
+func (c *Cache[K, V]) soonest(match func(entry[K, V]) bool) *itemTimer[K] {
+	for f := newHeapFrontier(&c.th); ; {
+		i := f.next()
+		if i < 0 {
+			return nil
+		}
+		if match(c.d[c.th[i].k]) {
+			return c.th[i]
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithClassBudgets(t *testing.T) {
+	namespace := func(key string, _ []byte) string {
+		return strings.SplitN(key, ":", 2)[0]
+	}
+	c := NewStringBytes(time.Minute, WithClassBudgets(namespace, map[string]int64{
+		"thumb": 6,
+		"meta":  2,
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.PutWithTTL("meta:a", []byte("1"), 4*time.Minute)
+	c.PutWithTTL("thumb:a", []byte("123"), time.Minute)
+	c.PutWithTTL("thumb:b", []byte("123"), 2*time.Minute)
+	c.PutWithTTL("other:a", []byte("123456789"), 3*time.Minute)
+	req.LengthIs(4)
+
+	c.PutWithTTL("thumb:c", []byte("12"), 3*time.Minute)
+	req.HasNot("thumb:a") // Closest to expiry in its class.
+	req.Has("meta:a")
+	req.Has("other:a")
+	if got := c.ClassBytes("thumb"); got != 5 {
+		t.Errorf("ClassBytes(thumb) got=%d, want=5", got)
+	}
+
+	c.PutWithTTL("meta:b", []byte("1"), 5*time.Minute)
+	req.Has("meta:a")
+	c.PutWithTTL("meta:b", []byte("12"), 5*time.Minute)
+	req.HasNot("meta:a")
+	req.Has("thumb:b")
+	if got := c.ClassBytes("meta"); got != 2 {
+		t.Errorf("ClassBytes(meta) got=%d, want=2", got)
+	}
+
+	c.Drop("thumb:b")
+	if got := c.ClassBytes("thumb"); got != 2 {
+		t.Errorf("ClassBytes(thumb) after Drop got=%d, want=2", got)
+	}
+}
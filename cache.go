@@ -4,22 +4,56 @@
 
 // Package cache implements a generic timed key-value in-memory data
 // store.
+//
+// Item lifetimes are measured with the monotonic clock, so that they
+// are not affected by changes to the wall clock. The monotonic clock
+// may stall, though, while the host is suspended or a virtual machine
+// is frozen, extending lifetimes by as much, unless the expiry schedule
+// is synchronized with the wall clock, see WithWallClockSync.
 package cache
 
 import (
 	"container/heap"
+	"context"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/antichris/go-cache/internal/window"
 )
 
 // New Cache instance.
-func New[K comparable, V any](defaultTTL time.Duration) *Cache[K, V] {
+func New[K comparable, V any](
+	defaultTTL time.Duration,
+	opts ...Option,
+) *Cache[K, V] {
 	c := &Cache[K, V]{
 		d:    make(map[K]entry[K, V]),
 		done: make(emptyChan),
 		t:    time.NewTimer(indefinite),
 		ttl:  defaultTTL,
 	}
+	for _, opt := range opts {
+		opt(&c.o)
+	}
+	c.size = sizerOf[K, V](c.o)
+	c.rules = rulesOf[K](c.o)
+	c.ttlFunc = ttlFuncOf[K, V](c.o)
+	c.ttler = mayBeTTLer[V]()
+	c.xform = transformOf[K, V](c.o)
+	c.onEvict = onEvictOf[K, V](c.o)
+	c.validate = validatorOf[K, V](c.o)
+	c.checkKey = keyCheckOf[K](c.o)
+	c.classOf = classOfOf[K, V](c.o)
+	c.digest = digestOf[V](c.o)
+	c.cold = coldCodecOf[V](c.o)
+	if c.cardHash = cardHashOf[K](c.o); c.cardHash != nil {
+		c.card = newKeyCounter(c.o.cardWindow)
+	}
+	if c.o.lockFree {
+		c.snap.Store(map[K]V{})
+	}
 	go c.loop()
 
 	return c
@@ -31,91 +65,270 @@ func NewByOf[K comparable, V any](
 	defaultTTL time.Duration,
 	sampleKey K,
 	sampleValue V,
+	opts ...Option,
 ) *Cache[K, V] {
-	return New[K, V](defaultTTL)
+	return New[K, V](defaultTTL, opts...)
 }
 
 // Cache of values.
 type Cache[K comparable, V any] struct {
+	n int64 // Number of items; first for alignment, accessed atomically.
+
+	hits, misses uint64 // Of lock-free reads, accessed atomically.
+	locks        uint64 // Times locked, if sampling contention; atomic.
+
 	d    map[K]entry[K, V]
 	done emptyChan
 	m    sync.Mutex
 	t    *time.Timer
+	o    options
 	th   timerHeap[K]
 	ttl  time.Duration
+
+	clk   clocks
+	gen   uint64       // Modification generation.
+	xgen  uint64       // Expiry schedule generation, see resetTimerAt.
+	tombs map[K]uint64 // Generations of dropped items, if tracked.
+	tags  map[string]map[K]struct{}
+	deps  map[K]map[K]struct{} // Dependents of the items.
+	calls map[K]*call[V]       // Provider calls in flight.
+	size  func(K, V) int64
+	rules []TTLRule[K]
+
+	ttlFunc func(K, V) (time.Duration, bool)
+	ttler   bool         // Whether values may be TTLers.
+	xform   func(K, V) V // Read transform.
+
+	onEvict  func(context.Context, K, V, EvictReason)
+	ctx      context.Context  // Of the call holding the lock, if given.
+	evicted  []eviction[K, V] // To report once unlocked.
+	validate func(K, V) bool
+	checkKey func(K) error
+
+	snap  atomic.Value // Of map[K]V, published for lock-free reads.
+	stale bool         // Whether the values were modified since published.
+
+	card     *keyCounter // Of distinct keys looked up, if estimated.
+	cardHash func(K) uint64
+
+	classOf    func(K, V) string
+	classBytes map[string]int64 // Total sizes of the items by class.
+
+	writes map[string]*window.Window // Of the origins, if limited.
+	pruned time.Time                 // When idle writes were last pruned.
+
+	refreshQ   refreshQueue[K, V] // Refreshes waiting, if limited.
+	recent     itemTimer[K]       // Sentinel of the order of recent use.
+	fh         freqHeap[K]        // Of the items, under EvictLFU.
+	refreshing int                // Number of refreshes in flight.
+
+	subs     map[chan Event[K, V]]struct{} // Event subscribers.
+	replay   []Event[K, V]                 // Ring of the last events.
+	replayAt int                           // Index of the oldest one.
+
+	digest   func(V) string          // Of the values, if deduplicated.
+	interned map[string]*interned[V] // Values by digest.
+
+	cold    *coldCodec[V] // Of the values of cold items, if compressed.
+	swept   time.Time     // When the cold items were last compressed.
+	coldSeg coldSegment   // Of the compressed values.
+	hot     itemTimer[K]  // Sentinel of the hot segment, if bounded.
+	hotN    int           // Number of the items in the hot segment.
+
+	bytes int64  // Total size of the items.
+	quiet bool   // Whether values are no longer put.
+	bulk  bool   // Whether the timers are added out of order.
+	seq   uint64 // Of the last entry put.
+	stats Stats
 }
 
 // Has returns whether an item for given key is present in the cache.
 //
 // Unlike Touch, this does not extend the lifetime of the item.
 func (c *Cache[K, T]) Has(key K) bool {
-	c.m.Lock()
-	defer c.m.Unlock()
-	_, found := c.d[key]
+	if c.o.lockFree {
+		return c.hasLockFree(key)
+	}
+	if atomic.LoadInt64(&c.n) == 0 {
+		return false
+	}
+	c.lock()
+	defer c.unlock()
+	_, found := c.find(key, false)
 	return found
 }
 
 // Lenght of cache is the number of items currently in the cache.
+//
+// It does not lock the cache, so it is cheap to poll frequently.
 func (c *Cache[K, V]) Length() int {
-	c.m.Lock()
+	return int(atomic.LoadInt64(&c.n))
+}
+
+// Keys returns the keys of all items currently in the cache, in no
+// particular order.
+func (c *Cache[K, V]) Keys() []K {
+	c.lock()
+	defer c.m.Unlock()
+	keys := make([]K, 0, len(c.d))
+	for k := range c.d {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Victims returns the keys of up to n items that are next in line to
+// be dropped from the cache, those soonest to expire first, or, under
+// EvictLRU and EvictLFU, the least recently and frequently used ones.
+func (c *Cache[K, V]) Victims(n int) []K {
+	c.lock()
+	defer c.m.Unlock()
+	if n > len(c.th) {
+		n = len(c.th)
+	}
+	if n <= 0 {
+		return nil
+	}
+	keys := make([]K, 0, n)
+	if c.o.eviction == EvictLRU {
+		for t := c.recent.prev; t != &c.recent && len(keys) < n; t = t.prev {
+			keys = append(keys, t.k)
+		}
+		return keys
+	}
+	// Walk the heap that orders the items by the policy in order.
+	ts, h := []*itemTimer[K](c.th), heap.Interface(&c.th)
+	if c.o.eviction == EvictLFU {
+		ts, h = c.fh, &c.fh
+	}
+	for f := newHeapFrontier(h); len(keys) < n; {
+		i := f.next()
+		if i < 0 {
+			break
+		}
+		keys = append(keys, ts[i].k)
+	}
+	return keys
+}
+
+// NextExpiry returns the key of the item that is next to expire, and
+// when it is due to, unless the cache holds no items that expire. Items
+// due at the same time expire in the order they were put in the cache.
+func (c *Cache[K, V]) NextExpiry() (key K, at time.Time, ok bool) {
+	c.lock()
 	defer c.m.Unlock()
-	return len(c.d)
+	if len(c.th) == 0 || c.d[c.th[0].k].pin {
+		return key, at, false
+	}
+	return c.th[0].k, c.th[0].x, true
+}
+
+// SampleKeys returns the keys of up to n items picked uniformly at
+// random from the cache, in no particular order, without iterating over
+// all of them, so that it is cheap to call on large caches.
+func (c *Cache[K, V]) SampleKeys(n int) []K {
+	c.lock()
+	defer c.m.Unlock()
+	m := len(c.th)
+	if n > m {
+		n = m
+	}
+	if n <= 0 {
+		return nil
+	}
+	// Floyd's algorithm picks n distinct indices of the timer heap,
+	// which has a timer for every item.
+	picked := make(map[int]struct{}, n)
+	keys := make([]K, 0, n)
+	for j := m - n; j < m; j++ {
+		i := rand.Intn(j + 1)
+		if _, dup := picked[i]; dup {
+			i = j
+		}
+		picked[i] = struct{}{}
+		keys = append(keys, c.th[i].k)
+	}
+	return keys
 }
 
 // Drop cached item and return its last value.
 func (c *Cache[K, V]) Drop(key K) (value V, ok bool) {
-	c.m.Lock()
-	defer c.m.Unlock()
+	c.lock()
+	defer c.unlock()
 	val, found := c.d[key]
 	if found {
-		c.resetTimer(val.t, 0)
-		c.processTimers()
+		c.drop(key, val, ReasonDropped)
 	}
 	return val.Value(), found
 }
 
+// DropEntry drops a cached item and returns a description of it.
+func (c *Cache[K, V]) DropEntry(key K) (e Entry[K, V], ok bool) {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return
+	}
+	c.drop(key, val, ReasonDropped)
+	return val.describe(key, time.Now()), true
+}
+
 // Get cached item.
 //
 // Since the cache can hold concrete value types, the second return
 // parameter indicates whether the value was actually found in cache.
 func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
-	c.m.Lock()
-	defer c.m.Unlock()
-	val, found := c.find(key)
-	return val.Value(), found
+	return c.get(nil, key)
 }
 
-// Put a value in cache at the given key, with the cache-default
-// time-to-live.
-func (c *Cache[K, V]) Put(key K, value V) {
-	c.PutWithTTL(key, value, c.ttl)
+// Peek gets a cached item, like Get does, but without extending its
+// lifetime, e.g., for monitoring that should not keep items alive.
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	if c.o.lockFree {
+		return c.getLockFree(key)
+	}
+	c.lock()
+	val, found := c.find(key, false)
+	c.lookup(key, found)
+	c.unlock()
+	return c.read(key, val.Value(), found)
+}
+
+// Put a value in cache at the given key, with the time-to-live set by
+// the rules, or the cache-default one, unless overridden by opts.
+func (c *Cache[K, V]) Put(key K, value V, opts ...CallOption) {
+	c.put(key, value, c.callOptions(key, opts))
 }
 
 // PutWithTTL puts a value in cache at the given key, with the given
 // time-to-live.
 func (c *Cache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
-	c.m.Lock()
-	defer c.m.Unlock()
+	c.put(key, value, callOptions{ttl: ttl, ttlSet: true})
+}
 
-	val, found := c.d[key]
-	val.v = value
-	val.ttl = ttl
-	if found {
-		c.resetTimer(val.t, ttl)
-	} else {
-		val.t = c.addTimer(key, ttl)
-	}
-	c.d[key] = val
+// PutUntil puts a value in cache at the given key, to expire at the
+// deadline, which lookups do not extend.
+func (c *Cache[K, V]) PutUntil(key K, value V, deadline time.Time) {
+	c.put(key, value, callOptions{until: deadline})
 }
 
 // GetOrPut returns the value in cache at the given key, or, if absent,
 // the one returned by provider, after having put it in the cache with
-// the cache-default time-to-live.
+// the time-to-live set by the rules, or the cache-default one, unless
+// overridden by opts.
+//
+// The cache is not locked while provider is called, and concurrent
+// calls for the same key share the result of a single provider call.
+// Should an item be put at the key while it is being loaded, the value
+// of that item is returned instead of the loaded one.
 func (c *Cache[K, V]) GetOrPut(
 	key K,
 	provider Getter[K, V],
+	opts ...CallOption,
 ) (value V, ok bool) {
-	return c.GetOrPutWithTTL(key, provider, c.ttl)
+	value, ok, _, _ = c.getOrPut(key, provider, c.callOptions(key, opts))
+	return
 }
 
 // GetOrPutWithTTL returns the value in cache at the given key, or, if
@@ -126,32 +339,122 @@ func (c *Cache[K, V]) GetOrPutWithTTL(
 	provider Getter[K, V],
 	ttl time.Duration,
 ) (value V, ok bool) {
-	c.m.Lock()
-	defer c.m.Unlock()
+	value, ok, _, _ = c.getOrPut(key, provider, callOptions{ttl: ttl, ttlSet: true})
+	return
+}
 
-	if val, found := c.find(key); found {
-		return val.v, true
-	}
-	if value, ok = provider.Get(key); !ok {
-		return
-	}
-	c.d[key] = entry[K, V]{
-		t:   c.addTimer(key, ttl),
-		ttl: ttl,
-		v:   value,
+// GetOrReload returns the value in cache at the given key, like
+// GetOrPut does, unless validate reports false for it, in which case
+// the item is dropped and the value is loaded from provider anew, e.g.,
+// for credentials that may be revoked before they expire.
+//
+// The cache is not locked while validate is called. Should the item be
+// modified meanwhile, it is not dropped.
+func (c *Cache[K, V]) GetOrReload(
+	key K,
+	validate func(value V) bool,
+	provider Getter[K, V],
+	opts ...CallOption,
+) (value V, ok bool) {
+	c.lock()
+	val, found := c.d[key]
+	c.unlock()
+	if found && !validate(val.Value()) {
+		c.lock()
+		c.invalidate(key, val.gen)
+		c.unlock()
 	}
+	value, ok, _, _ = c.getOrPut(key, provider, c.callOptions(key, opts))
 	return
 }
 
-// Touch a cached value, if present, to extend its lifetime. Returns
-// false if the key has not been found in the cache.
+// Touch a cached value, if present, to extend its lifetime, according
+// to the touch policy. Returns false if the key has not been found in
+// the cache.
 func (c *Cache[K, T]) Touch(key K) bool {
-	c.m.Lock()
-	defer c.m.Unlock()
-	_, found := c.find(key)
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if found {
+		c.touch(val)
+	}
 	return found
 }
 
+// GetAndTouch gets a cached item and sets its time-to-live to ttl,
+// rescheduling its expiry, in a single operation, like the memcached
+// "gat" command does. The new time-to-live also applies to later
+// extensions of the lifetime of the item, which is no longer pinned.
+func (c *Cache[K, V]) GetAndTouch(key K, ttl time.Duration) (value V, ok bool) {
+	c.lock()
+	val, found := c.find(key, false)
+	c.lookup(key, found)
+	if found {
+		val.ttl = ttl
+		val.pin = false
+		val.fixed = false
+		c.resetTimerAt(val.t, capped(time.Now().Add(ttl), val.limit))
+		c.store(key, val)
+	}
+	c.unlock()
+	return c.read(key, val.Value(), found)
+}
+
+// SetTTL sets the time-to-live of a cached item to ttl, rescheduling its
+// expiry, like GetAndTouch does, without looking it up. Returns false if
+// the key has not been found in the cache.
+func (c *Cache[K, V]) SetTTL(key K, ttl time.Duration) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	val.ttl = ttl
+	val.pin = false
+	val.fixed = false
+	c.resetTimerAt(val.t, capped(time.Now().Add(ttl), val.limit))
+	c.store(key, val)
+	return true
+}
+
+// ExpireAt reschedules the expiry of a cached item to t, which lookups
+// do not extend, unpinning it. Returns false if the key has not been
+// found in the cache.
+func (c *Cache[K, V]) ExpireAt(key K, t time.Time) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	o := callOptions{until: t}
+	now := time.Now()
+	val.ttl = o.lifetime(now)
+	val.pin = false
+	val.fixed = true
+	c.resetTimerAt(val.t, o.expiry(now))
+	c.store(key, val)
+	return true
+}
+
+// TTL returns the remaining lifetime of a cached item, which is the
+// largest time.Duration for pinned items, without extending it, e.g.,
+// to derive the max-age of an HTTP response. Returns false if the key
+// has not been found in the cache.
+func (c *Cache[K, V]) TTL(key K) (remaining time.Duration, ok bool) {
+	c.lock()
+	defer c.unlock()
+	val, found := c.find(key, false)
+	if !found {
+		return 0, false
+	}
+	if val.pin {
+		return indefinite, true
+	}
+	return time.Until(val.t.x), true
+}
+
 // Shutdown terminates the goroutine processing item expiry timers.
 func (c *Cache[K, V]) Shutdown() {
 	if c.IsShutDown() {
@@ -170,6 +473,42 @@ func (c *Cache[K, V]) IsShutDown() bool {
 	}
 }
 
+// Quiesce the cache to drain it: values are no longer put in it, by
+// any method, while it keeps serving the ones it holds, and those keep
+// expiring and can be dropped. Restore fails with ErrQuiesced.
+func (c *Cache[K, V]) Quiesce() {
+	c.lock()
+	defer c.m.Unlock()
+	c.quiet = true
+}
+
+// Resume putting values in a quiesced cache.
+func (c *Cache[K, V]) Resume() {
+	c.lock()
+	defer c.m.Unlock()
+	c.quiet = false
+}
+
+// IsQuiesced returns whether the cache is quiesced.
+func (c *Cache[K, V]) IsQuiesced() bool {
+	c.lock()
+	defer c.m.Unlock()
+	return c.quiet
+}
+
+// An Entry describes a cached item.
+type Entry[K comparable, V any] struct {
+	Key       K
+	Value     V
+	TTL       time.Duration // Time-to-live of the item.
+	Inserted  time.Time     // When the value was put.
+	Expires   time.Time     // When the item would expire; zero if pinned.
+	Remaining time.Duration // Remaining lifetime; zero if pinned.
+	Pinned    bool
+	Tags      []string
+	Origin    string // Of the value, see Origin.
+}
+
 // A Getter can get a value for a key.
 type Getter[K comparable, V any] interface {
 	// Get value for given key and return if that was successful.
@@ -198,16 +537,54 @@ func (f SimpleGetterFunc[K, V]) Get(key K) (value V, ok bool) {
 // Internals.
 
 func (c *Cache[K, V]) loop() {
+	var validate <-chan time.Time
+	if c.validate != nil && c.o.validateEvery > 0 {
+		tk := time.NewTicker(c.o.validateEvery)
+		defer tk.Stop()
+		validate = tk.C
+	}
+	var check <-chan time.Time
+	if c.o.checkEvery > 0 {
+		tk := time.NewTicker(c.o.checkEvery)
+		defer tk.Stop()
+		check = tk.C
+	}
+	var compress <-chan time.Time
+	if c.cold != nil {
+		tk := time.NewTicker(c.o.coldAfter)
+		defer tk.Stop()
+		compress = tk.C
+	}
+	var sync <-chan time.Time
+	if c.o.clockSync > 0 {
+		tk := time.NewTicker(c.o.clockSync)
+		defer tk.Stop()
+		sync = tk.C
+		c.lock()
+		c.markClocks(time.Now())
+		c.unlock()
+	}
 	for {
 		select {
 		case <-c.t.C:
 			// log.Println("timer fired")
 			more := true
 			for more {
-				c.m.Lock()
+				c.lock()
 				more = c.processTimers()
-				c.m.Unlock()
+				c.unlock()
 			}
+		case <-validate:
+			c.validateAll()
+		case <-compress:
+			c.compressCold()
+		case <-check:
+			c.checkIntegrity()
+		case <-sync:
+			now := time.Now()
+			c.lock()
+			c.syncClock(now, now.Round(0))
+			c.unlock()
 		case <-c.done:
 			return
 		}
@@ -221,29 +598,357 @@ func (c *Cache[K, V]) processTimers() (more bool) {
 		return
 	}
 	t := c.th[0]
-	if now := time.Now(); t.x.After(now) {
+	now := time.Now()
+	if t.x.After(now) {
 		// log.Printf("└── expired cleared; next at %v\n", t.x)
 		c.t.Reset(t.x.Sub(now))
 		return
 	}
 	// log.Printf("├─  drop '%v' expired at %v\n", t.k, t.x)
+	c.lag(now.Sub(t.x))
 	heap.Pop(&c.th)
-	delete(c.d, t.k)
+	if val := c.d[t.k]; val.provider != nil && !c.quiet {
+		c.refresh(t.k, val, t)
+		return true
+	}
+	c.remove(t.k, ReasonExpired)
+	c.stats.Expired++
 	return true
 }
 
-func (c *Cache[K, V]) find(key K) (entry[K, V], bool) {
+// store val at key, recording the modification.
+//
+// Items selected by the eviction policy, possibly including this one,
+// are evicted should the cache exceed its limits.
+func (c *Cache[K, V]) store(key K, val entry[K, V]) {
+	c.gen++
+	val.gen = c.gen
+	if val.cold != nil && !val.costSet {
+		val.size = int64(len(val.cold.data))
+	} else if !val.costSet {
+		val.size = c.size(key, val.v)
+	}
+	old := c.d[key]
+	c.weigh(key, &val, old)
+	c.bytes += val.size - old.size
+	c.uncold(old, val)
+	c.d[key] = val
+	c.used(val.t)
+	c.ranked(val.t)
+	if val.cold == nil && val.t.hnext == nil {
+		c.heat(val.t)
+	}
+	atomic.StoreInt64(&c.n, int64(len(c.d)))
+	c.stale = c.o.lockFree
+	if c.tombs != nil {
+		delete(c.tombs, key)
+	}
+	c.evict(val.t)
+}
+
+// remove the item at key, recording the modification, and the reason
+// for it to report.
+func (c *Cache[K, V]) remove(key K, reason EvictReason) {
+	c.gen++
+	val := c.d[key]
+	if len(val.tags) > 0 {
+		c.untag(key, val.tags)
+	}
+	if len(val.deps) > 0 {
+		c.undepend(key, val.deps)
+	}
+	c.bytes -= val.size
+	c.unweigh(val)
+	c.unused(val.t)
+	c.unranked(val.t)
+	c.uncold(val, entry[K, V]{})
+	c.cool(val.t)
+	c.unintern(val.digest)
+	delete(c.d, key)
+	atomic.StoreInt64(&c.n, int64(len(c.d)))
+	c.stale = c.o.lockFree
+	if c.tombs != nil {
+		c.tombs[key] = c.gen
+	}
+	if val.cold != nil && (c.onEvict != nil || c.emits()) {
+		val.v = val.Value()
+	}
+	c.emit(EventEvict, key, val.v, reason)
+	if c.onEvict != nil {
+		e := eviction[K, V]{key, val.v, reason, c.ctx}
+		if val.lease != nil && val.lease.n > 0 {
+			val.lease.e = &e
+		} else {
+			c.evicted = append(c.evicted, e)
+		}
+	}
+	c.dropDependents(key)
+}
+
+// drop the item val at key, with its timer, for reason.
+func (c *Cache[K, V]) drop(key K, val entry[K, V], reason EvictReason) {
+	c.unschedule(val.t)
+	c.remove(key, reason)
+}
+
+// getOrPut returns whether the value was found in the cache (hit), and
+// how long it took to load it from provider, or to wait for another
+// call to load it, otherwise.
+func (c *Cache[K, V]) getOrPut(
+	key K,
+	provider Getter[K, V],
+	o callOptions,
+) (value V, ok, hit bool, load time.Duration) {
+	c.lock()
+	c.ctx = o.ctx
+	val, hit := c.find(key, !o.noSlide)
+	c.lookup(key, hit)
+	if hit {
+		c.unlock()
+		value, ok = c.read(key, val.Value(), true)
+		return value, ok, true, 0
+	}
+	start := time.Now()
+	if cl, found := c.calls[key]; found {
+		cl.dups++
+		c.stats.Coalesced++
+		c.unlock()
+		<-cl.done
+		value, ok = c.read(key, cl.v, cl.ok)
+		return value, ok, false, time.Since(start)
+	}
+	cl := &call[V]{done: make(emptyChan)}
+	if c.calls == nil {
+		c.calls = make(map[K]*call[V])
+	}
+	c.calls[key] = cl
+	c.unlock()
+
+	c.load(key, provider, o, cl)
+	value, ok = c.read(key, cl.v, cl.ok)
+	return value, ok, false, time.Since(start)
+}
+
+// load a value from provider into the cache, completing cl, even if
+// provider panics.
+func (c *Cache[K, V]) load(
+	key K,
+	provider Getter[K, V],
+	o callOptions,
+	cl *call[V],
+) {
+	start := time.Now()
+	var until time.Time // Expiry of the value at the provider, if known.
+	defer func() {
+		c.lock()
+		c.ctx = o.ctx
+		c.stats.load(time.Since(start))
+		if cl.dups > 0 {
+			c.stats.Suppressed++
+		}
+		delete(c.calls, key)
+		keep := !o.noPromote
+		now := time.Now()
+		if cl.ok {
+			cl.ok, o = c.loaded(cl.v, c.valueTTL(key, cl.v, o))
+			if !until.IsZero() && o.lifetime(now) > until.Sub(now) {
+				o.until = until
+			}
+		}
+		if cl.ok {
+			if val, found := c.d[key]; found {
+				cl.v = val.v
+			} else if keep && !c.quiet && c.keyOK(key) &&
+				c.allowWrite(o.origin, now) {
+				val = entry[K, V]{
+					pin:    o.pin,
+					fixed:  !o.until.IsZero(),
+					put:    now,
+					t:      c.addTimerAt(key, o.expiry(now)),
+					ttl:    o.lifetime(now),
+					limit:  o.limit(now),
+					origin: o.origin,
+					v:      cl.v,
+				}
+				c.dedup(&val)
+				c.emit(EventPut, key, cl.v, 0)
+				c.store(key, val)
+			}
+		}
+		c.unlock()
+		close(cl.done)
+	}()
+	if c.o.chaos.fails() {
+		return
+	}
+	if eg, ok := provider.(ExpiringGetter[K, V]); ok {
+		var remaining time.Duration
+		cl.v, remaining, cl.ok = eg.GetExpiring(key)
+		if remaining != indefinite {
+			until = time.Now().Add(remaining)
+		}
+		return
+	}
+	if cg, ok := provider.(ContextGetter[K, V]); ok && o.ctx != nil {
+		cl.v, cl.ok = cg.GetContext(o.ctx, key)
+		return
+	}
+	cl.v, cl.ok = provider.Get(key)
+}
+
+// keyOK returns whether values may be put at key.
+func (c *Cache[K, V]) keyOK(key K) bool {
+	return c.checkKey == nil || c.checkKey(key) == nil
+}
+
+// get the item at key, like Get does, with ctx, if not nil.
+func (c *Cache[K, V]) get(ctx context.Context, key K) (value V, ok bool) {
+	if c.o.lockFree {
+		return c.getLockFree(key)
+	}
+	c.lock()
+	c.ctx = ctx
+	val, found := c.find(key, true)
+	c.lookup(key, found)
+	c.unlock()
+	return c.read(key, val.Value(), found)
+}
+
+// find the item at key, extending its lifetime, if slide, unless
+// lookups do not slide. Items that have expired, but not been dropped
+// yet, are dropped and not found, if expiry is checked on lookups.
+func (c *Cache[K, V]) find(key K, slide bool) (entry[K, V], bool) {
 	val, found := c.d[key]
+	if found && c.checksExpiry() && !val.t.refreshing &&
+		!val.t.x.After(time.Now()) {
+		c.drop(key, val, ReasonExpired)
+		c.stats.Expired++
+		return entry[K, V]{}, false
+	}
+	if found && !val.pin && c.o.chaos.strikes() {
+		c.drop(key, val, ReasonEvicted)
+		c.stats.Evicted++
+		return entry[K, V]{}, false
+	}
+	if found && val.cold != nil {
+		val = c.thaw(key, val)
+	}
 	if found {
-		c.resetTimer(val.t, val.ttl)
+		val.t.hits++
+		c.reranked(val.t)
+		c.heat(val.t)
+	}
+	if found && slide {
+		c.used(val.t)
+	}
+	if found && slide && !c.o.noSlide {
+		c.slide(val)
 	}
 	return val, found
 }
 
+// put value at key with o, returning an error if it is not put.
+func (c *Cache[K, V]) put(key K, value V, o callOptions) error {
+	if c.checkKey != nil {
+		if err := c.checkKey(key); err != nil {
+			return err
+		}
+	}
+	c.lock()
+	defer c.unlock()
+	c.ctx = o.ctx
+	return c.set(key, value, o)
+}
+
+// set value at key with o, returning an error if it is not set. The
+// cache must be locked.
+func (c *Cache[K, V]) set(key K, value V, o callOptions) error {
+	if c.quiet {
+		return ErrQuiesced
+	}
+	now := time.Now()
+	if !c.allowWrite(o.origin, now) {
+		return &QuotaError{o.origin}
+	}
+	o = c.valueTTL(key, value, o)
+	val, found := c.d[key]
+	if c.o.zeroDrops && !o.pin && !o.expiry(now).After(now) {
+		if found {
+			c.drop(key, val, ReasonDropped)
+		}
+		return nil
+	}
+	val.v = value
+	val.cold = nil
+	val.lease = nil
+	val.costSet = false
+	val.provider = nil
+	val.ttl = o.lifetime(now)
+	val.pin = o.pin
+	val.fixed = !o.until.IsZero()
+	val.put = now
+	val.origin = o.origin
+	val.limit = o.limit(now)
+	if x := o.expiry(now); found {
+		c.seq++
+		val.t.seq = c.seq
+		c.resetTimerAt(val.t, x)
+		val.t.hits = 0
+	} else {
+		val.t = c.addTimerAt(key, x)
+	}
+	c.dedup(&val)
+	c.emit(EventPut, key, value, 0)
+	c.store(key, val)
+	c.dropDependents(key)
+	return nil
+}
+
+// chaos injected by WithChaos, built with the cachetest tag.
+type chaos struct {
+	latency time.Duration // Of every load.
+	rate    float64       // Of failed loads and early evictions.
+}
+
+// strikes returns whether chaos strikes, at random, if injected.
+func (ch *chaos) strikes() bool {
+	return ch != nil && rand.Float64() < ch.rate
+}
+
+// fails delays a load by the latency, if chaos is injected, and returns
+// whether it fails.
+func (ch *chaos) fails() bool {
+	if ch == nil {
+		return false
+	}
+	time.Sleep(ch.latency)
+	return ch.strikes()
+}
+
+// slide the expiry of an item, unless it is pinned or fixed, to extend
+// its lifetime, or it is being refreshed, and so has expired already.
+func (c *Cache[K, V]) slide(val entry[K, V]) {
+	if !val.pin && !val.fixed && !val.t.refreshing {
+		c.resetTimerAt(val.t, capped(time.Now().Add(val.ttl), val.limit))
+	}
+}
+
 func (c *Cache[K, V]) addTimer(key K, ttl time.Duration) *itemTimer[K] {
+	return c.addTimerAt(key, time.Now().Add(ttl))
+}
+
+func (c *Cache[K, V]) addTimerAt(key K, x time.Time) *itemTimer[K] {
+	c.seq++
 	t := &itemTimer[K]{
-		k: key,
-		x: time.Now().Add(ttl),
+		k:   key,
+		x:   x,
+		seq: c.seq,
+	}
+	if c.bulk {
+		// Out of order, until restored, see putMany.
+		t.i = len(c.th)
+		c.th = append(c.th, t)
+		return t
 	}
 	heap.Push(&c.th, t)
 	// log.Printf("added '%v' to drop at %v\n", t.k, t.x)
@@ -254,9 +959,32 @@ func (c *Cache[K, V]) addTimer(key K, ttl time.Duration) *itemTimer[K] {
 	return t
 }
 
+// unschedule the timer t, taking it off the heap, unless it is off it
+// already, being refreshed.
+func (c *Cache[K, V]) unschedule(t *itemTimer[K]) {
+	if !t.refreshing {
+		heap.Remove(&c.th, t.i)
+	}
+}
+
 func (c *Cache[K, V]) resetTimer(t *itemTimer[K], ttl time.Duration) {
-	t.x = time.Now().Add(ttl)
-	heap.Fix(&c.th, t.i)
+	c.resetTimerAt(t, time.Now().Add(ttl))
+}
+
+func (c *Cache[K, V]) resetTimerAt(t *itemTimer[K], x time.Time) {
+	t.x = x
+	// Rescheduling is not a modification (e.g., for Range), but it has to
+	// reach incremental snapshots.
+	c.xgen++
+	t.xgen = c.xgen
+	if t.refreshing {
+		// Back on the heap, see refresh.
+		t.refreshing = false
+		heap.Push(&c.th, t)
+	} else {
+		heap.Fix(&c.th, t.i)
+	}
+	c.reranked(t)
 	// log.Printf("extended '%v' to drop at %v\n", t.k, t.x)
 	if t.i == 0 {
 		// log.Println("└── this is currently the soonest")
@@ -270,19 +998,83 @@ const indefinite = time.Duration(1<<63 - 1)
 
 // entry has all the data of a stored value.
 type entry[K comparable, V any] struct {
-	t   *itemTimer[K] // Item expiry timer.
-	ttl time.Duration // Time-to-live of the value.
-	v   V             // The stored value.
+	gen      uint64        // Generation of the last modification.
+	lease    *lease[K, V]  // Of the value to borrowers, if borrowed.
+	pin      bool          // Whether the item is pinned.
+	fixed    bool          // Whether the expiry is not extended by lookups.
+	put      time.Time     // When the value was put.
+	size     int64         // Size of the item in bytes.
+	costSet  bool          // Whether the size was set by UpdateCost.
+	class    string        // Of the item, see WithClassBudgets.
+	provider Getter[K, V]  // Refreshing the value on expiry, if bound.
+	t        *itemTimer[K] // Item expiry timer.
+	tags     []string      // Tags of the item.
+	deps     []K           // Keys of the items this one depends on.
+	origin   string        // Of the value, see Origin.
+	digest   string        // Of the value, if interned, see WithDedup.
+	cold     *coldValue[V] // Compressed value, instead of v, if cold.
+	ttl      time.Duration // Time-to-live of the value.
+	limit    time.Time     // Latest expiry, if capped, see MaxLifetime.
+	v        V             // The stored value.
 }
 
 func (e entry[K, V]) Value() V {
+	if e.cold != nil {
+		return e.cold.decompress(e.cold.data)
+	}
 	return e.v
 }
 
+// describe the entry for key as of now.
+func (e entry[K, V]) describe(key K, now time.Time) Entry[K, V] {
+	d := Entry[K, V]{
+		Key:      key,
+		Value:    e.Value(),
+		TTL:      e.ttl,
+		Inserted: e.put,
+		Pinned:   e.pin,
+		Tags:     append([]string(nil), e.tags...),
+		Origin:   e.origin,
+	}
+	if !e.pin {
+		d.Expires = e.t.x
+		d.Remaining = e.t.x.Sub(now)
+	}
+	return d
+}
+
+// A call to a provider, shared by the concurrent lookups of a key.
+type call[V any] struct {
+	done emptyChan // Closed when the call completes.
+	v    V
+	ok   bool
+	dups int // Number of lookups waiting for the result.
+}
+
 type itemTimer[K comparable] struct {
-	i int       // Heap index.
-	k K         // Key of cache entry.
-	x time.Time // Expiry time.
+	i     int       // Heap index.
+	k     K         // Key of cache entry.
+	x     time.Time // Expiry time.
+	hits  uint32    // Lookups that found the entry since put or refreshed.
+	swept uint32    // Hits as of the last compression of cold items.
+	seq   uint64    // Of the entry, in the order the entries were put.
+	xgen  uint64    // Expiry schedule generation of the last reschedule.
+	// Whether the entry is being refreshed, see PutWithProvider, with the
+	// timer off the heap, and the expiry time the one it was due at.
+	refreshing bool
+	fi         int // Frequency heap index plus one, if in it, else zero.
+
+	prev, next   *itemTimer[K] // In the order of recent use, if tracked.
+	hprev, hnext *itemTimer[K] // In the hot segment, if bounded.
+}
+
+// before returns whether t is due before u, or at the same time, but
+// put before it.
+func (t *itemTimer[K]) before(u *itemTimer[K]) bool {
+	if t.x.Equal(u.x) {
+		return t.seq < u.seq
+	}
+	return t.x.Before(u.x)
 }
 
 type timerHeap[K comparable] []*itemTimer[K]
@@ -297,7 +1089,7 @@ func (h timerHeap[_]) Len() int {
 // Less reports whether the element with index i
 // must sort before the element with index j.
 func (h timerHeap[_]) Less(i int, j int) bool {
-	return h[i].x.Before(h[j].x)
+	return h[i].before(h[j])
 }
 
 // Swap swaps the elements with indexes i and j.
@@ -323,3 +1115,59 @@ func (h *timerHeap[_]) Pop() any {
 	*h = s[:i]
 	return v
 }
+
+// heapFrontier is a heap of the indices of the nodes of heap h that
+// can come next when walking it in order.
+type heapFrontier struct {
+	h heap.Interface
+	i []int
+}
+
+var _ heap.Interface = (*heapFrontier)(nil)
+
+// newHeapFrontier returns the frontier of a walk of h from its root.
+func newHeapFrontier(h heap.Interface) *heapFrontier {
+	f := &heapFrontier{h: h}
+	if h.Len() > 0 {
+		f.i = []int{0}
+	}
+	return f
+}
+
+// next returns the index of the next node of the walk, or -1 if all of
+// them have been walked.
+func (f *heapFrontier) next() int {
+	if len(f.i) == 0 {
+		return -1
+	}
+	i := heap.Pop(f).(int)
+	for _, j := range [...]int{2*i + 1, 2*i + 2} {
+		if j < f.h.Len() {
+			heap.Push(f, j)
+		}
+	}
+	return i
+}
+
+func (f *heapFrontier) Len() int {
+	return len(f.i)
+}
+
+func (f *heapFrontier) Less(i int, j int) bool {
+	return f.h.Less(f.i[i], f.i[j])
+}
+
+func (f *heapFrontier) Swap(i int, j int) {
+	f.i[i], f.i[j] = f.i[j], f.i[i]
+}
+
+func (f *heapFrontier) Push(v any) {
+	f.i = append(f.i, v.(int))
+}
+
+func (f *heapFrontier) Pop() any {
+	n := len(f.i) - 1
+	v := f.i[n]
+	f.i = f.i[:n]
+	return v
+}
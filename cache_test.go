@@ -5,6 +5,7 @@
 package cache_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -208,6 +209,260 @@ func TestRaces(t *testing.T) {
 	req.LengthIs(2)
 }
 
+func TestGetAndTouch(t *testing.T) {
+	const k = "key"
+	c := New[string, int](ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, ok := c.GetAndTouch(k, ttl)
+	req.AssertNot(ok, "should not get and touch '%v'", k)
+
+	c.Put(k, 1)
+	v, ok := c.GetAndTouch(k, 3*ttl)
+	req.Assert(ok && v == 1, "GetAndTouch(%v) got=%v,%v, want=1,true", k, v, ok)
+
+	time.Sleep(2 * ttl)
+	req.Has(k)
+	// The new time-to-live sticks.
+	req.Touch(k)
+	time.Sleep(2 * ttl)
+	req.Has(k)
+
+	c.GetAndTouch(k, ttl/2)
+	time.Sleep(ttl)
+	req.HasNot(k)
+}
+
+func TestSetTTL(t *testing.T) {
+	const k = "key"
+	c := New[string, int](ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.AssertNot(c.SetTTL(k, ttl), "should not set TTL of '%v'", k)
+
+	c.Put(k, 1, Pin())
+	req.Assert(c.SetTTL(k, 3*ttl), "should set TTL of '%v'", k)
+	time.Sleep(2 * ttl)
+	req.Has(k)
+	req.Touch(k) // The new time-to-live sticks.
+	time.Sleep(2 * ttl)
+	req.Has(k)
+
+	c.SetTTL(k, ttl/2)
+	time.Sleep(ttl)
+	req.HasNot(k)
+}
+
+func TestDropEntry(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, ok := c.DropEntry("a")
+	req.AssertNot(ok, "should not drop an absent item")
+
+	before := time.Now()
+	c.Put("a", 1, TTL(time.Hour))
+	c.Tag("a", "t")
+	c.Put("b", 2, Pin())
+	e, ok := c.DropEntry("a")
+	req.Assert(ok, "should drop 'a'")
+	req.HasNot("a")
+	req.Assert(e.Key == "a" && e.Value == 1, "DropEntry(a) got=%v", e)
+	req.Assert(e.TTL == time.Hour, "TTL got=%v, want=%v", e.TTL, time.Hour)
+	req.Assert(!e.Inserted.Before(before), "Inserted got=%v, before=%v", e.Inserted, before)
+	req.Assert(e.Expires.Sub(e.Inserted) == time.Hour, "Expires got=%v", e.Expires)
+	req.Assert(e.Remaining > 0 && e.Remaining <= time.Hour, "Remaining got=%v", e.Remaining)
+	req.Assert(len(e.Tags) == 1 && e.Tags[0] == "t", "Tags got=%v", e.Tags)
+	n := c.TouchTag("t")
+	req.Assert(n == 0, "TouchTag(t) got=%d after DropEntry, want=0", n)
+
+	e, _ = c.DropEntry("b")
+	req.Assert(e.Pinned && e.Expires.IsZero() && e.Remaining == 0,
+		"pinned DropEntry(b) got=%v", e)
+	req.Assert(c.Stats().Expired == 0, "DropEntry should not count as expiry")
+}
+
+func TestPutUntil(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	deadline := time.Now().Add(4 * ttl)
+	c.PutUntil("a", 1, deadline)
+	c.Put("b", 2)
+	req.Assert(c.ExpireAt("b", deadline), "ExpireAt(b) should find 'b'")
+	req.AssertNot(c.ExpireAt("c", deadline), "ExpireAt(c) should not find 'c'")
+	time.Sleep(2 * ttl)
+	// Lookups do not extend the deadline.
+	req.Has("a")
+	c.Touch("a")
+	c.Get("b")
+	time.Sleep(3 * ttl)
+	req.HasNot("a")
+	req.HasNot("b")
+
+	c.PutUntil("a", 1, time.Now().Add(-time.Second))
+	time.Sleep(ttl / 2)
+	req.HasNot("a")
+}
+
+func TestGetOrReload(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	valid := func(v int) bool { return v > 0 }
+	loads := 0
+	provider := GetterFunc[string, int](func(string) (int, bool) {
+		loads++
+		return loads, true
+	})
+
+	c.Put("a", 1)
+	c.Put("b", -1)
+	v, ok := c.GetOrReload("a", valid, provider)
+	req.Assert(ok && v == 1, "GetOrReload(a) got=%d, %t, want=1, true", v, ok)
+	v, ok = c.GetOrReload("b", valid, provider)
+	req.Assert(ok && v == 1, "GetOrReload(b) got=%d, %t, want=1, true", v, ok)
+	req.Assert(req.Get("b") == 1, "Get(b) should be reloaded")
+	v, ok = c.GetOrReload("c", valid, provider)
+	req.Assert(ok && v == 2, "GetOrReload(c) got=%d, %t, want=2, true", v, ok)
+
+	// An item modified while being validated is kept.
+	c.Put("d", -1)
+	v, _ = c.GetOrReload("d", func(int) bool {
+		c.Put("d", 4)
+		return false
+	}, provider)
+	req.Assert(v == 4, "GetOrReload(d) got=%d, want=4", v)
+	req.Assert(loads == 2, "provider calls got=%d, want=2", loads)
+	req.Assert(c.Stats().Invalid == 1, "Invalid got=%d, want=1", c.Stats().Invalid)
+}
+
+func TestVictims(t *testing.T) {
+	c := New[int, empty](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.Assert(len(c.Victims(3)) == 0, "empty cache should have no victims")
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		// Insert in a scrambled order of expiry.
+		k := i * 37 % n
+		c.PutWithTTL(k, empty{}, time.Minute+time.Duration(k)*time.Second)
+	}
+	c.GetAndTouch(0, time.Hour)
+
+	got := c.Victims(5)
+	want := []int{1, 2, 3, 4, 5}
+	req.Assert(len(got) == len(want), "Victims(5) got=%v, want=%v", got, want)
+	for i := range want {
+		req.Assert(got[i] == want[i], "Victims(5) got=%v, want=%v", got, want)
+	}
+	req.Assert(len(c.Victims(2*n)) == n, "Victims(%d) should return all", 2*n)
+}
+
+func TestExpiryOrder(t *testing.T) {
+	c := New[int, empty](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, _, ok := c.NextExpiry()
+	req.AssertNot(ok, "empty cache should have no next expiry")
+	c.Put(-1, empty{}, Pin())
+	_, _, ok = c.NextExpiry()
+	req.AssertNot(ok, "pinned items should not expire")
+
+	deadline := time.Now().Add(time.Hour)
+	const n = 50
+	for i := 0; i < n; i++ {
+		c.PutUntil(i, empty{}, deadline)
+	}
+	c.PutUntil(0, empty{}, deadline) // Put again, after the others.
+
+	got := c.Victims(n)
+	for i := range got[:n-1] {
+		req.Assert(got[i] == i+1, "Victims() got=%v, want in the order put", got)
+	}
+	req.Assert(got[n-1] == 0, "Victims() got=%v, want 0 last", got)
+	k, x, ok := c.NextExpiry()
+	req.Assert(ok && k == 1 && x.Equal(deadline),
+		"NextExpiry() got=%v,%v,%v, want=1,%v,true", k, x, ok, deadline)
+}
+
+func TestPeek(t *testing.T) {
+	c := New[int, int](ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, ok := c.Peek(0)
+	req.AssertNot(ok, "Peek() of missing key should fail")
+	c.Put(0, 1)
+	deadline := time.Now().Add(ttl)
+	for time.Now().Before(deadline) {
+		if v, ok := c.Peek(0); ok {
+			req.Assert(v == 1, "Peek() got=%v, want=1", v)
+		}
+		time.Sleep(ttl / 5)
+	}
+	time.Sleep(ttl)
+	req.HasNot(0)
+}
+
+func TestTTL(t *testing.T) {
+	c := New[int, empty](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	_, ok := c.TTL(0)
+	req.AssertNot(ok, "TTL() of missing key should fail")
+	c.PutWithTTL(0, empty{}, time.Hour)
+	c.Put(1, empty{}, Pin())
+	time.Sleep(ttl)
+	d, ok := c.TTL(0)
+	req.Assert(ok && d > time.Hour-time.Second && d < time.Hour,
+		"TTL() got=%v,%v, want just under an hour", d, ok)
+	c.Get(0)
+	d2, _ := c.TTL(0)
+	req.Assert(d2 >= d, "TTL() got=%v after lookup, want at least %v", d2, d)
+	d, ok = c.TTL(1)
+	req.Assert(ok && d == math.MaxInt64,
+		"TTL() of pinned got=%v,%v, want indefinite", d, ok)
+}
+
+func TestSampleKeys(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.Assert(c.SampleKeys(3) == nil, "SampleKeys() of empty should be nil")
+	const n = 100
+	for i := 0; i < n; i++ {
+		c.Put(i, i)
+	}
+	seen := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		keys := c.SampleKeys(10)
+		req.Assert(len(keys) == 10, "SampleKeys(10) got %d keys", len(keys))
+		distinct := map[int]bool{}
+		for _, k := range keys {
+			req.AssertNot(distinct[k], "SampleKeys(10) repeats %d", k)
+			distinct[k] = true
+			seen[k]++
+		}
+	}
+	// Every key is expected about 100 times.
+	for k := 0; k < n; k++ {
+		req.Assert(seen[k] > 40 && seen[k] < 200,
+			"key %d sampled %d times, not uniformly", k, seen[k])
+	}
+	req.Assert(len(c.SampleKeys(2*n)) == n, "SampleKeys(%d) should return all", 2*n)
+}
+
 func TestIsShutDown(t *testing.T) {
 	v := struct{}{}
 	c := NewByOf(ttl, v, v)
@@ -264,6 +519,21 @@ func BenchmarkGetOrPut(b *testing.B) {
 	}
 }
 
+func BenchmarkLength(b *testing.B) {
+	v := struct{}{}
+	c := NewByOf(5*time.Second, 0, v)
+	defer c.Shutdown()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%16 == 0 {
+				c.Put(i, v)
+			}
+			c.Length()
+		}
+	})
+}
+
 func BenchmarkHas(b *testing.B) {
 	v := struct{}{}
 	c := NewByOf(5*time.Second, 0, v)
@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"strings"
+)
+
+// Access that a client has to the endpoints of a Handler.
+type Access int
+
+const (
+	// NoAccess to any endpoint.
+	NoAccess Access = iota
+	// ReadOnly access to the endpoints that do not change the cache.
+	ReadOnly
+	// ReadWrite access to all endpoints.
+	ReadWrite
+)
+
+// An Authorizer returns the access that the client making r has.
+type Authorizer func(r *http.Request) Access
+
+// TokenAuth returns an Authorizer that validates the bearer token in
+// the Authorization header of requests, returning the access it grants.
+func TokenAuth(validate func(token string) Access) Authorizer {
+	return func(r *http.Request) Access {
+		const prefix = "Bearer "
+		h := r.Header.Get("Authorization")
+		if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+			return NoAccess
+		}
+		return validate(h[len(prefix):])
+	}
+}
+
+// CertAuth returns an Authorizer that grants the access returned by
+// access for the verified client certificate of a request made over
+// mutually authenticated TLS.
+func CertAuth(access func(cert *x509.Certificate) Access) Authorizer {
+	return func(r *http.Request) Access {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return NoAccess
+		}
+		return access(r.TLS.VerifiedChains[0][0])
+	}
+}
+
+// MutualTLSConfig returns a server TLS configuration that requires
+// clients to present certificates signed by one of clientCAs, for use
+// with CertAuth.
+func MutualTLSConfig(clientCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// authorize reports whether the client making r has the need access,
+// replying with an error if not.
+func (h *Handler[K, V]) authorize(w http.ResponseWriter, r *http.Request, need Access) bool {
+	if h.auth == nil {
+		return true
+	}
+	switch a := h.auth(r); {
+	case a >= need:
+		return true
+	case a == NoAccess:
+		http.Error(w, http.StatusText(http.StatusUnauthorized),
+			http.StatusUnauthorized)
+	default:
+		http.Error(w, http.StatusText(http.StatusForbidden),
+			http.StatusForbidden)
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestTokenAuth(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	auth := TokenAuth(func(token string) Access {
+		switch token {
+		case "reader":
+			return ReadOnly
+		case "writer":
+			return ReadWrite
+		}
+		return NoAccess
+	})
+	srv := httptest.NewServer(NewHandler(c, WithAuth(auth)))
+	defer srv.Close()
+	ctx := context.Background()
+
+	anon := &Client{BaseURL: srv.URL}
+	if _, _, err := anon.Get(ctx, "a"); err == nil ||
+		!strings.Contains(err.Error(), "401") {
+		t.Errorf("anonymous Get() error: got=%v, want 401", err)
+	}
+
+	reader := &Client{BaseURL: srv.URL, Token: "reader"}
+	if _, ok, err := reader.Get(ctx, "a"); err != nil || !ok {
+		t.Errorf("reader Get() got=%v,%v, want=true,nil", ok, err)
+	}
+	if _, err := reader.Drop(ctx, "a"); err == nil ||
+		!strings.Contains(err.Error(), "403") {
+		t.Errorf("reader Drop() error: got=%v, want 403", err)
+	}
+
+	writer := &Client{BaseURL: srv.URL, Token: "writer"}
+	if ok, err := writer.Drop(ctx, "a"); err != nil || !ok {
+		t.Errorf("writer Drop() got=%v,%v, want=true,nil", ok, err)
+	}
+}
+
+func TestCertAuth(t *testing.T) {
+	caCert, caKey := newCert(t, "CA", nil, nil)
+	clientCert, clientKey := newCert(t, "writer", caCert, caKey)
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	h := NewHandler(c, WithAuth(CertAuth(func(cert *x509.Certificate) Access {
+		if cert.Subject.CommonName == "writer" {
+			return ReadWrite
+		}
+		return NoAccess
+	})))
+	srv := httptest.NewUnstartedServer(h)
+	srv.TLS = MutualTLSConfig(pool)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // Handshake errors.
+	srv.StartTLS()
+	defer srv.Close()
+
+	tr := srv.Client().Transport.(*http.Transport).Clone()
+	tr.TLSClientConfig.Certificates = []tls.Certificate{{
+		Certificate: [][]byte{clientCert.Raw},
+		PrivateKey:  clientKey,
+	}}
+	hc := &http.Client{Transport: tr}
+	cl := &Client{BaseURL: srv.URL, HTTPClient: hc}
+	if ok, err := cl.Drop(context.Background(), "a"); err != nil || !ok {
+		t.Errorf("Drop() got=%v,%v, want=true,nil", ok, err)
+	}
+
+	anon := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	if _, err := anon.Keys(context.Background()); err == nil {
+		t.Error("Keys() without a client certificate should fail")
+	}
+}
+
+// newCert returns a new certificate for cn, signed by parent, or
+// self-signed, if nil.
+func newCert(
+	t *testing.T,
+	cn string,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent,
+		&key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
@@ -0,0 +1,284 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// A Client talks to the endpoints served by a Handler.
+type Client struct {
+	// BaseURL is the URL the Handler is served at.
+	BaseURL string
+	// HTTPClient to make requests with; http.DefaultClient, if nil.
+	// Configure its transport for mutually authenticated TLS, if the
+	// Handler requires that.
+	HTTPClient *http.Client
+	// Token to send as the bearer token in requests, if not empty.
+	Token string
+}
+
+// Keys returns the textual forms of the keys of the cached items.
+func (c *Client) Keys(ctx context.Context) (keys []string, err error) {
+	err = c.getJSON(ctx, "/keys", nil, &keys)
+	return
+}
+
+// Get the JSON of the value at the given key. Returns false if there is
+// no item at the key.
+func (c *Client) Get(
+	ctx context.Context,
+	key string,
+) (value json.RawMessage, ok bool, err error) {
+	err = c.getJSON(ctx, "/get", keyQuery(key), &value)
+	if err == errNotFound {
+		return nil, false, nil
+	}
+	return value, err == nil, err
+}
+
+// Entry returns a description of the item at the given key, with the
+// JSON of its value. Returns false if there is no item at the key.
+func (c *Client) Entry(
+	ctx context.Context,
+	key string,
+) (e cache.Entry[string, json.RawMessage], ok bool, err error) {
+	err = c.getJSON(ctx, "/entry", keyQuery(key), &e)
+	if err == errNotFound {
+		return e, false, nil
+	}
+	return e, err == nil, err
+}
+
+// Versions returns the times the cached items were put at, by the
+// textual forms of their keys.
+func (c *Client) Versions(ctx context.Context) (v map[string]time.Time, err error) {
+	err = c.getJSON(ctx, "/versions", nil, &v)
+	return
+}
+
+// Entries returns descriptions of the items at the given keys, with the
+// JSON of their values, in a single request. Keys without items are
+// skipped.
+func (c *Client) Entries(
+	ctx context.Context,
+	keys []string,
+) (es []cache.Entry[string, json.RawMessage], err error) {
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(ctx, http.MethodPost, "/entries", nil, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	err = json.NewDecoder(res.Body).Decode(&es)
+	return
+}
+
+// Digests returns the digests of the versions of the cached items in
+// each of the Buckets of their keys, see Digests.
+func (c *Client) Digests(ctx context.Context) (d []uint64, err error) {
+	err = c.getJSON(ctx, "/digests", nil, &d)
+	return
+}
+
+// VersionsIn returns the versions of the cached items, like Versions
+// does, only of those with keys in the given buckets, see Bucket.
+func (c *Client) VersionsIn(
+	ctx context.Context,
+	buckets []int,
+) (v map[string]time.Time, err error) {
+	q := url.Values{}
+	for _, b := range buckets {
+		q.Add("bucket", strconv.Itoa(b))
+	}
+	err = c.getJSON(ctx, "/versions", q, &v)
+	return
+}
+
+// Put the JSON of a value at the given key, with the given
+// time-to-live, or the cache-default one, if zero.
+func (c *Client) Put(
+	ctx context.Context,
+	key string,
+	value json.RawMessage,
+	ttl time.Duration,
+) error {
+	q := keyQuery(key)
+	if ttl != 0 {
+		q.Set("ttl", ttl.String())
+	}
+	res, err := c.do(ctx, http.MethodPost, "/put", q, bytes.NewReader(value))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Drop the item at the given key. Returns false if there is no item at
+// the key.
+func (c *Client) Drop(ctx context.Context, key string) (ok bool, err error) {
+	res, err := c.do(ctx, http.MethodPost, "/drop", keyQuery(key), nil)
+	if err == errNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	return true, nil
+}
+
+// Stats returns the usage statistics of the cache.
+func (c *Client) Stats(ctx context.Context) (s cache.Stats, err error) {
+	err = c.getJSON(ctx, "/stats", nil, &s)
+	return
+}
+
+// Dump copies a snapshot of the cache to w, gzip-compressed if compress
+// is true.
+func (c *Client) Dump(ctx context.Context, w io.Writer, compress bool) error {
+	var q url.Values
+	if compress {
+		q = url.Values{"compress": {""}}
+	}
+	res, err := c.do(ctx, http.MethodGet, "/dump", q, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// Restore a snapshot read from r to the cache.
+func (c *Client) Restore(ctx context.Context, r io.Reader) error {
+	res, err := c.do(ctx, http.MethodPost, "/restore", nil, r)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Remote returns a cache.Interface of the cache, with the values in
+// their JSON form, making requests in ctx. Get and Has report the
+// items as absent on errors.
+func (c *Client) Remote(ctx context.Context) cache.Interface[string, json.RawMessage] {
+	return remote{c, ctx}
+}
+
+// Internals.
+
+type remote struct {
+	c   *Client
+	ctx context.Context
+}
+
+func (r remote) Get(key string) (value json.RawMessage, ok bool) {
+	value, ok, _ = r.c.Get(r.ctx, key)
+	return
+}
+
+func (r remote) Has(key string) bool {
+	_, ok := r.Get(key)
+	return ok
+}
+
+func (r remote) PutWithTTLE(key string, value json.RawMessage, ttl time.Duration) error {
+	return r.c.Put(r.ctx, key, value, ttl)
+}
+
+func (r remote) DropE(key string) error {
+	_, err := r.c.Drop(r.ctx, key)
+	return err
+}
+
+type statusError struct {
+	status string
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	if e.msg == "" {
+		return e.status
+	}
+	return e.status + ": " + e.msg
+}
+
+var errNotFound = &statusError{status: http.StatusText(http.StatusNotFound)}
+
+func keyQuery(key string) url.Values {
+	return url.Values{"key": {key}}
+}
+
+func (c *Client) getJSON(
+	ctx context.Context,
+	path string,
+	query url.Values,
+	v any,
+) error {
+	res, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// do a request, returning an error for any response with a status
+// other than 2xx.
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body io.Reader,
+) (*http.Response, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return nil, &statusError{
+		status: res.Status,
+		msg:    strings.TrimSpace(string(msg)),
+	}
+}
@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestClient(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a b", 1)
+	c.Put("c", 2)
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cl := &Client{BaseURL: srv.URL + "/"}
+
+	keys, err := cl.Keys(ctx)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Keys() got=%v,%v, want 2 keys", keys, err)
+	}
+
+	v, ok, err := cl.Get(ctx, "a b")
+	if err != nil || !ok || string(v) != "1" {
+		t.Errorf("Get(a b) got=%q,%v,%v, want=%q,true,nil", v, ok, err, "1")
+	}
+	_, ok, err = cl.Get(ctx, "x")
+	if err != nil || ok {
+		t.Errorf("Get(x) got=%v,%v, want=false,nil", ok, err)
+	}
+
+	ok, err = cl.Drop(ctx, "c")
+	if err != nil || !ok {
+		t.Errorf("Drop(c) got=%v,%v, want=true,nil", ok, err)
+	}
+	ok, err = cl.Drop(ctx, "c")
+	if err != nil || ok {
+		t.Errorf("Drop(c) again got=%v,%v, want=false,nil", ok, err)
+	}
+
+	s, err := cl.Stats(ctx)
+	if err != nil || s.Items != 1 || s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("Stats() got=%+v,%v", s, err)
+	}
+
+	var buf bytes.Buffer
+	if err := cl.Dump(ctx, &buf, true); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	c.Drop("a b")
+	if err := cl.Restore(ctx, &buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if !c.Has("a b") {
+		t.Error("should have restored 'a b'")
+	}
+}
+
+func TestClientInvalidKey(t *testing.T) {
+	c := cache.New[int, int](time.Minute)
+	defer c.Shutdown()
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	cl := &Client{BaseURL: srv.URL}
+	if _, _, err := cl.Get(context.Background(), "NaN"); err == nil {
+		t.Error("Get(NaN) should fail for int keys")
+	}
+}
+
+func TestClientRemote(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	r := (&Client{BaseURL: srv.URL}).Remote(context.Background())
+	if err := r.PutWithTTLE("a", []byte("1"), time.Hour); err != nil {
+		t.Fatalf("PutWithTTLE() error: %v", err)
+	}
+	if e, ok := c.DropEntry("a"); !ok || e.Value != 1 || e.TTL != time.Hour {
+		t.Fatalf("put entry got=%+v,%v", e, ok)
+	}
+	if err := r.PutWithTTLE("a", []byte("x"), 0); err == nil {
+		t.Error("PutWithTTLE() of invalid JSON should fail")
+	}
+	c.Put("b", 2)
+	if v, ok := r.Get("b"); !ok || string(v) != "2" {
+		t.Errorf("Get(b) got=%q,%v, want=%q,true", v, ok, "2")
+	}
+	if err := r.DropE("b"); err != nil || r.Has("b") {
+		t.Errorf("DropE(b) got=%v, should have dropped", err)
+	}
+}
+
+func TestClientTextKeys(t *testing.T) {
+	c := cache.New[netip.Addr, int](time.Minute)
+	defer c.Shutdown()
+	c.Put(netip.MustParseAddr("::1"), 1)
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cl := &Client{BaseURL: srv.URL}
+	keys, err := cl.Keys(ctx)
+	if err != nil || len(keys) != 1 || keys[0] != "::1" {
+		t.Fatalf("Keys() got=%v,%v, want=[::1]", keys, err)
+	}
+	v, ok, err := cl.Get(ctx, "::1")
+	if err != nil || !ok || string(v) != "1" {
+		t.Errorf("Get(::1) got=%q,%v,%v, want=%q,true,nil", v, ok, err, "1")
+	}
+}
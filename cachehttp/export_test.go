@@ -0,0 +1,12 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+// Clients returns the number of the clients the router holds.
+func (r *Router) Clients() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return len(r.clients)
+}
@@ -0,0 +1,180 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// ErrNoPeers is returned by an AntiEntropy with no peers.
+var ErrNoPeers = errors.New("cachehttp: no peers")
+
+// Buckets is the number of the buckets that the keys are hashed into
+// for the Digests of the versions of the items.
+const Buckets = 256
+
+// Bucket returns the bucket of the textual form of a key.
+func Bucket(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % Buckets)
+}
+
+// Digests returns a digest of the versions of the items with keys in
+// each of the Buckets, by the textual forms of their keys, see
+// cache.Cache.Versions, which is the same for any two replicas that
+// have the same versions of the items in the bucket (and almost
+// certainly different, otherwise).
+func Digests(versions map[string]time.Time) []uint64 {
+	d := make([]uint64, Buckets)
+	var t [8]byte
+	for k, v := range versions {
+		h := fnv.New64a()
+		h.Write([]byte(k))
+		binary.LittleEndian.PutUint64(t[:], uint64(v.UnixNano()))
+		h.Write(t[:])
+		d[Bucket(k)] ^= h.Sum64()
+	}
+	return d
+}
+
+// An AntiEntropy replicates the items written to a cache from its peers
+// served by Handlers, by periodically exchanging the Digests of the
+// versions of their items, see cache.Cache.Versions, comparing the
+// versions in the buckets whose digests differ, and pulling the newer
+// items in batches, so that replicas converge even if some of the
+// writes fail to propagate otherwise, without transferring all of their
+// keys every round.
+//
+// Only the items put are replicated, not the drops.
+type AntiEntropy[K comparable, V any] struct {
+	c     *cache.Cache[K, V]
+	peers []*Client
+}
+
+// NewAntiEntropy returns a new AntiEntropy for c with peers.
+func NewAntiEntropy[K comparable, V any](
+	c *cache.Cache[K, V],
+	peers ...*Client,
+) *AntiEntropy[K, V] {
+	return &AntiEntropy[K, V]{c: c, peers: peers}
+}
+
+// Run rounds of anti-entropy every period until ctx is done, ignoring
+// their errors, e.g., due to unavailable peers, which later rounds
+// make up for.
+func (a *AntiEntropy[K, V]) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			a.Round(ctx)
+		}
+	}
+}
+
+// Round of anti-entropy with a random peer: the items that it has newer
+// versions of than the cache are pulled into the cache, with their
+// insertion times and remaining lifetimes. Returns the number of items
+// pulled.
+func (a *AntiEntropy[K, V]) Round(ctx context.Context) (pulled int, err error) {
+	if len(a.peers) == 0 {
+		return 0, ErrNoPeers
+	}
+	return a.Pull(ctx, a.peers[rand.Intn(len(a.peers))])
+}
+
+// Pull the items that peer has newer versions of than the cache, like
+// Round does.
+func (a *AntiEntropy[K, V]) Pull(ctx context.Context, peer *Client) (pulled int, err error) {
+	theirs, err := peer.Digests(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ours := versions(a.c)
+	var differ []int
+	for b, d := range Digests(ours) {
+		if b < len(theirs) && theirs[b] != d {
+			differ = append(differ, b)
+		}
+	}
+	if len(differ) == 0 {
+		return 0, nil
+	}
+	vs, err := peer.VersionsIn(ctx, differ)
+	if err != nil {
+		return 0, err
+	}
+	var stale []string
+	for s, version := range vs {
+		if our, found := ours[s]; !found || our.Before(version) {
+			stale = append(stale, s)
+		}
+	}
+	for len(stale) > 0 {
+		n := pullBatch
+		if n > len(stale) {
+			n = len(stale)
+		}
+		es, err := peer.Entries(ctx, stale[:n])
+		if err != nil {
+			return pulled, err
+		}
+		stale = stale[n:]
+		for _, e := range es {
+			// Entries dropped since are missing.
+			ok, err := a.put(e)
+			if err != nil {
+				return pulled, err
+			}
+			if ok {
+				pulled++
+			}
+		}
+	}
+	return pulled, nil
+}
+
+// Internals.
+
+// pullBatch is the number of entries pulled in a request.
+const pullBatch = 100
+
+// put the item described by e in the cache, and return whether it has
+// been put, which it is not if its key is invalid.
+func (a *AntiEntropy[K, V]) put(e cache.Entry[string, json.RawMessage]) (bool, error) {
+	k, err := parseKey[K](e.Key)
+	if err != nil {
+		return false, nil
+	}
+	var v V
+	if err := json.Unmarshal(e.Value, &v); err != nil {
+		return false, err
+	}
+	// The clocks of the peers may differ.
+	expires := time.Now().Add(e.Remaining)
+	err = a.c.PutEntry(cache.Entry[K, V]{
+		Key:      k,
+		Value:    v,
+		TTL:      e.TTL,
+		Inserted: e.Inserted,
+		Expires:  expires,
+		Pinned:   e.Pinned,
+		Tags:     e.Tags,
+		Origin:   e.Origin,
+	})
+	return err == nil, err
+}
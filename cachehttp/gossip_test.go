@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestAntiEntropy(t *testing.T) {
+	var caches [2]*cache.Cache[int, string]
+	var peers [2]*Client
+	for i := range caches {
+		caches[i] = cache.New[int, string](time.Minute)
+		defer caches[i].Shutdown()
+		srv := httptest.NewServer(NewHandler(caches[i]))
+		defer srv.Close()
+		peers[i] = &Client{BaseURL: srv.URL}
+	}
+	a, b := caches[0], caches[1]
+	ctx := context.Background()
+
+	a.Put(1, "a", cache.Origin("web"))
+	a.PutWithTTL(2, "old", time.Hour)
+	b.Put(3, "b")
+	time.Sleep(time.Millisecond)
+	b.Put(2, "new")
+
+	if n, err := NewAntiEntropy(a, peers[1]).Round(ctx); err != nil || n != 2 {
+		t.Fatalf("Round() got=%d, %v, want=2, nil", n, err)
+	}
+	if n, err := NewAntiEntropy(b, peers[0]).Round(ctx); err != nil || n != 1 {
+		t.Fatalf("Round() got=%d, %v, want=1, nil", n, err)
+	}
+	if n, err := NewAntiEntropy(a, peers[1]).Round(ctx); err != nil || n != 0 {
+		t.Errorf("Round() after converging got=%d, %v, want=0, nil", n, err)
+	}
+	for _, c := range caches {
+		if v, _ := c.Get(2); v != "new" || c.Length() != 3 {
+			t.Errorf("Get(2) got=%q of %d items, want=%q of 3", v, c.Length(), "new")
+		}
+	}
+	if e, _ := b.GetEntry(1); e.Origin != "web" || e.TTL != time.Minute {
+		t.Errorf("GetEntry(1) got=%+v, want origin web and TTL %v", e, time.Minute)
+	}
+
+	if _, err := NewAntiEntropy(a).Round(ctx); err != ErrNoPeers {
+		t.Errorf("Round() without peers error got=%v, want=%v", err, ErrNoPeers)
+	}
+}
+
+func TestAntiEntropyDigests(t *testing.T) {
+	a := cache.New[int, string](time.Minute)
+	defer a.Shutdown()
+	b := cache.New[int, string](time.Minute)
+	defer b.Shutdown()
+	h := NewHandler(b)
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			h.ServeHTTP(w, r)
+		}))
+	defer srv.Close()
+	ae := NewAntiEntropy(a, &Client{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	for i := 0; i < 300; i++ {
+		b.Put(i, "v")
+	}
+	if n, err := ae.Round(ctx); err != nil || n != 300 {
+		t.Fatalf("Round() got=%d, %v, want=300, nil", n, err)
+	}
+	want := []string{"/digests", "/versions", "/entries", "/entries", "/entries"}
+	if len(paths) != len(want) {
+		t.Fatalf("requests got=%v, want=%v", paths, want)
+	}
+
+	paths = nil
+	if n, err := ae.Round(ctx); err != nil || n != 0 {
+		t.Fatalf("Round() after converging got=%d, %v, want=0, nil", n, err)
+	}
+	if len(paths) != 1 {
+		t.Errorf("requests after converging got=%v, want only /digests", paths)
+	}
+
+	paths = nil
+	b.Put(7, "new")
+	if n, err := ae.Round(ctx); err != nil || n != 1 {
+		t.Fatalf("Round() got=%d, %v, want=1, nil", n, err)
+	}
+	if v, _ := a.Get(7); v != "new" || len(paths) != 3 {
+		t.Errorf("Get(7) got=%q after requests %v, want=new after 3", v, paths)
+	}
+}
@@ -0,0 +1,378 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package cachehttp exposes a cache over HTTP for debugging and
+// operations.
+package cachehttp
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// Handler serves the following endpoints for a cache:
+//
+//	GET  /keys          Lists the keys of the cached items as a JSON
+//	                    array of strings.
+//	GET  /get?key=KEY   Replies with the JSON of the value at KEY,
+//	                    without extending its lifetime.
+//	GET  /entry?key=KEY Replies with the JSON of the cache.Entry at
+//	                    KEY, e.g., to see its origin.
+//	POST /entries       Replies with the JSON array of the cache.Entry
+//	                    items at the keys in the JSON array of the
+//	                    request body, skipping the absent ones.
+//	GET  /versions      Replies with a JSON object of the times the
+//	                    items were put at, by their keys, only of the
+//	                    keys in the buckets given by the optional,
+//	                    repeated bucket query parameter, see Bucket.
+//	GET  /digests       Replies with the JSON array of the Digests of
+//	                    the versions of the items.
+//	POST /put?key=KEY   Puts the value decoded from the JSON request
+//	                    body at KEY, with the time-to-live given by
+//	                    the optional ttl query parameter, e.g., 5m.
+//	POST /drop?key=KEY  Drops the item at KEY.
+//	GET  /stats         Replies with the JSON of the cache Stats.
+//	GET  /dump          Streams a snapshot of the cache, gzip-compressed
+//	                    if the compress query parameter is present.
+//	POST /restore       Restores a snapshot from the request body.
+//
+// Keys are in their textual forms, as encoded by their MarshalText
+// methods, for encoding.TextMarshaler keys, or fmt.Sprint, otherwise.
+//
+// The endpoints that operate on a single item reply with the 404 (Not
+// Found) status when there is no item at the given key.
+//
+// Unless an Authorizer is given, all clients have ReadWrite access. The
+// put, drop and restore endpoints require ReadWrite access, the rest
+// require ReadOnly access.
+type Handler[K comparable, V any] struct {
+	c    *cache.Cache[K, V]
+	mux  *http.ServeMux
+	auth Authorizer
+}
+
+// A HandlerOption configures a Handler.
+type HandlerOption func(*handlerOptions)
+
+// WithAuth makes a Handler authorize clients with a.
+func WithAuth(a Authorizer) HandlerOption {
+	return func(o *handlerOptions) {
+		o.auth = a
+	}
+}
+
+// NewHandler returns a new Handler for c.
+func NewHandler[K comparable, V any](
+	c *cache.Cache[K, V],
+	opts ...HandlerOption,
+) *Handler[K, V] {
+	var o handlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	h := &Handler[K, V]{
+		c:    c,
+		mux:  http.NewServeMux(),
+		auth: o.auth,
+	}
+	h.handle("/keys", ReadOnly, h.keys)
+	h.handle("/get", ReadOnly, h.get)
+	h.handle("/entry", ReadOnly, h.entry)
+	h.handle("/entries", ReadOnly, h.entries)
+	h.handle("/versions", ReadOnly, h.versions)
+	h.handle("/digests", ReadOnly, h.digests)
+	h.handle("/put", ReadWrite, h.put)
+	h.handle("/drop", ReadWrite, h.drop)
+	h.handle("/stats", ReadOnly, h.stats)
+	h.handle("/dump", ReadOnly, h.dump)
+	h.handle("/restore", ReadWrite, h.restore)
+	return h
+}
+
+func (h *Handler[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler[K, V]) keys(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	keys := h.c.Keys()
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = formatKey(k)
+	}
+	reply(w, s)
+}
+
+func (h *Handler[K, V]) get(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	k, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	v, ok := h.c.Peek(k)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	reply(w, v)
+}
+
+func (h *Handler[K, V]) entry(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	k, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	e, ok := h.c.GetEntry(k)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	reply(w, textEntry(e))
+}
+
+func (h *Handler[K, V]) entries(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	var keys []string
+	if err := json.NewDecoder(r.Body).Decode(&keys); err != nil {
+		http.Error(w, "invalid keys: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	es := make([]cache.Entry[string, V], 0, len(keys))
+	for _, s := range keys {
+		k, err := parseKey[K](s)
+		if err != nil {
+			http.Error(w, "invalid key: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if e, ok := h.c.GetEntry(k); ok {
+			es = append(es, textEntry(e))
+		}
+	}
+	reply(w, es)
+}
+
+func (h *Handler[K, V]) versions(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	var in map[int]bool
+	for _, s := range r.URL.Query()["bucket"] {
+		b, err := strconv.Atoi(s)
+		if err != nil || b < 0 || b >= Buckets {
+			http.Error(w, "invalid bucket: "+s, http.StatusBadRequest)
+			return
+		}
+		if in == nil {
+			in = make(map[int]bool)
+		}
+		in[b] = true
+	}
+	m := versions(h.c)
+	if in != nil {
+		for k := range m {
+			if !in[Bucket(k)] {
+				delete(m, k)
+			}
+		}
+	}
+	reply(w, m)
+}
+
+func (h *Handler[K, V]) digests(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	reply(w, Digests(versions(h.c)))
+}
+
+func (h *Handler[K, V]) put(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	k, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	var opts []cache.CallOption
+	if s := r.URL.Query().Get("ttl"); s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil {
+			http.Error(w, "invalid ttl: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts = append(opts, cache.TTL(ttl))
+	}
+	var v V
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		http.Error(w, "invalid value: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.c.PutE(k, v, opts...); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[K, V]) drop(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	k, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := h.c.Drop(k); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[K, V]) stats(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	reply(w, h.c.Stats())
+}
+
+func (h *Handler[K, V]) dump(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	s := cache.NewSnapshotter(h.c, cache.SnapshotConfig{
+		Compress: r.URL.Query().Has("compress"),
+	})
+	// The response has already started, there is no way to report
+	// errors to the client other than aborting it.
+	if err := s.Dump(w); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (h *Handler[K, V]) restore(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	if err := h.c.Restore(r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type handlerOptions struct {
+	auth Authorizer
+}
+
+// handle requests for path that the client has the need access for
+// with f.
+func (h *Handler[K, V]) handle(path string, need Access, f http.HandlerFunc) {
+	h.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if h.authorize(w, r, need) {
+			f(w, r)
+		}
+	})
+}
+
+// key parses the key query parameter of r, replying with an error if
+// that fails.
+func (h *Handler[K, V]) key(w http.ResponseWriter, r *http.Request) (K, bool) {
+	k, err := parseKey[K](r.URL.Query().Get("key"))
+	if err != nil {
+		http.Error(w, "invalid key: "+err.Error(), http.StatusBadRequest)
+		return k, false
+	}
+	return k, true
+}
+
+// formatKey returns the textual form of k, as encoded by its
+// MarshalText method, if it is an encoding.TextMarshaler, or produced
+// by fmt.Sprint, otherwise.
+func formatKey[K comparable](k K) string {
+	if m, ok := any(k).(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(k)
+}
+
+// parseKey parses s as the textual form of a key, as decoded by its
+// UnmarshalText method, if it is an encoding.TextUnmarshaler, or
+// produced by fmt.Sprint, otherwise.
+func parseKey[K comparable](s string) (k K, err error) {
+	if u, ok := any(&k).(encoding.TextUnmarshaler); ok {
+		err = u.UnmarshalText([]byte(s))
+		return
+	}
+	if v := reflect.ValueOf(&k).Elem(); v.Kind() == reflect.String {
+		v.SetString(s)
+		return
+	}
+	_, err = fmt.Sscan(s, &k)
+	return
+}
+
+// textEntry returns e with its key in its textual form.
+func textEntry[K comparable, V any](e cache.Entry[K, V]) cache.Entry[string, V] {
+	return cache.Entry[string, V]{
+		Key:       formatKey(e.Key),
+		Value:     e.Value,
+		TTL:       e.TTL,
+		Inserted:  e.Inserted,
+		Expires:   e.Expires,
+		Remaining: e.Remaining,
+		Pinned:    e.Pinned,
+		Tags:      e.Tags,
+		Origin:    e.Origin,
+	}
+}
+
+// versions returns the versions of the items in c, by the textual forms
+// of their keys.
+func versions[K comparable, V any](c *cache.Cache[K, V]) map[string]time.Time {
+	vs := c.Versions()
+	m := make(map[string]time.Time, len(vs))
+	for k, t := range vs {
+		m[formatKey(k)] = t
+	}
+	return m
+}
+
+// reply with the JSON of v.
+func reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// allow reports whether r uses method, replying with an error if not.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+		http.StatusMethodNotAllowed)
+	return false
+}
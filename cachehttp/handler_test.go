@@ -0,0 +1,146 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestDumpRestore(t *testing.T) {
+	for _, query := range []string{"", "?compress"} {
+		src := cache.New[string, int](time.Minute)
+		defer src.Shutdown()
+		src.Put("a", 1)
+		src.Put("b", 2)
+		srcSrv := httptest.NewServer(NewHandler(src))
+		defer srcSrv.Close()
+
+		dst := cache.New[string, int](time.Minute)
+		defer dst.Shutdown()
+		dstSrv := httptest.NewServer(NewHandler(dst))
+		defer dstSrv.Close()
+
+		res, err := http.Get(srcSrv.URL + "/dump" + query)
+		if err != nil {
+			t.Fatalf("GET /dump%s error: %v", query, err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("GET /dump%s status: %s", query, res.Status)
+		}
+
+		res, err = http.Post(dstSrv.URL+"/restore",
+			"application/octet-stream", res.Body)
+		if err != nil {
+			t.Fatalf("POST /restore error: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNoContent {
+			t.Fatalf("POST /restore status: %s", res.Status)
+		}
+
+		if v, ok := dst.Get("b"); !ok || v != 2 {
+			t.Errorf("%s: Get(b) got=%v,%v, want=2,true", query, v, ok)
+		}
+		if n := dst.Length(); n != 2 {
+			t.Errorf("%s: Length() got=%d, want=2", query, n)
+		}
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	res, err := http.Post(srv.URL+"/restore", "text/plain",
+		strings.NewReader("not a snapshot"))
+	if err != nil {
+		t.Fatalf("POST /restore error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST /restore status: got=%d, want=%d",
+			res.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	res, err := http.Post(srv.URL+"/dump", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("POST /dump error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /dump status: got=%d, want=%d",
+			res.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGetDoesNotSlide(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	before, _ := c.TTL("a")
+	time.Sleep(10 * time.Millisecond)
+	res, err := http.Get(srv.URL + "/get?key=a")
+	if err != nil {
+		t.Fatalf("GET /get error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /get status: %s", res.Status)
+	}
+	if after, _ := c.TTL("a"); after >= before {
+		t.Errorf("TTL(a) got=%v after GET /get, want less than %v", after, before)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1, cache.Origin("billing"))
+	srv := httptest.NewServer(NewHandler(c))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/entry?key=a")
+	if err != nil {
+		t.Fatalf("GET /entry error: %v", err)
+	}
+	defer res.Body.Close()
+	var e cache.Entry[string, int]
+	if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
+		t.Fatalf("decoding the entry error: %v", err)
+	}
+	if e.Key != "a" || e.Value != 1 || e.Origin != "billing" {
+		t.Errorf("GET /entry got=%+v, want a, 1 from billing", e)
+	}
+
+	res, err = http.Get(srv.URL + "/entry?key=b")
+	if err != nil {
+		t.Fatalf("GET /entry error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /entry of a missing key status: %s", res.Status)
+	}
+}
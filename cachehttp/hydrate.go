@@ -0,0 +1,37 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+import (
+	"context"
+	"io"
+
+	"github.com/antichris/go-cache"
+)
+
+// Hydrate warms c up from the cache of peer, e.g., on the startup of a
+// replica when scaling out, so that it does not start cold: a full
+// snapshot of the cache of peer is streamed into c, followed by a
+// catch-up on the items put at peer while it was, up to the cutover,
+// when c is considered hydrated. Those are pulled like AntiEntropy.Pull
+// does, and so are not the drops. Returns the number of the items
+// caught up on.
+func Hydrate[K comparable, V any](
+	ctx context.Context,
+	c *cache.Cache[K, V],
+	peer *Client,
+) (caughtUp int, err error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(peer.Dump(ctx, pw, true))
+	}()
+	err = c.Restore(pr)
+	// Stop the dump, if not done yet.
+	pr.CloseWithError(err)
+	if err != nil {
+		return 0, err
+	}
+	return NewAntiEntropy(c).Pull(ctx, peer)
+}
@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestHydrate(t *testing.T) {
+	leader := cache.New[int, string](time.Minute)
+	defer leader.Shutdown()
+	for i := 0; i < 100; i++ {
+		leader.Put(i, "v")
+	}
+	h := NewHandler(leader)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r)
+			if r.URL.Path == "/dump" {
+				// Put before the cutover.
+				leader.Put(100, "late")
+			}
+		}))
+	defer srv.Close()
+
+	c := cache.New[int, string](time.Minute)
+	defer c.Shutdown()
+	n, err := Hydrate(context.Background(), c, &Client{BaseURL: srv.URL})
+	if err != nil || n != 1 {
+		t.Fatalf("Hydrate() got=%d,%v, want=1,nil", n, err)
+	}
+	if l := c.Length(); l != 101 {
+		t.Errorf("Length() got=%d, want=101", l)
+	}
+	if v, _ := c.Get(100); v != "late" {
+		t.Errorf("Get(100) got=%q, want=%q", v, "late")
+	}
+
+	srv.Close()
+	if _, err := Hydrate(context.Background(), c, &Client{BaseURL: srv.URL}); err == nil {
+		t.Error("Hydrate() from an unavailable peer should fail")
+	}
+}
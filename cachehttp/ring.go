@@ -0,0 +1,263 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoNodes is returned by a Router with no nodes on its ring.
+var ErrNoNodes = errors.New("cachehttp: no nodes")
+
+// DefaultReplicas is the number of virtual nodes per node on a Ring
+// that has none set.
+const DefaultReplicas = 128
+
+// A Ring assigns keys to nodes by consistent hashing, so that adding
+// or removing a node only moves the keys assigned to it.
+//
+// Each node is placed on the ring as a number of virtual nodes, to
+// spread the keys more evenly.
+type Ring struct {
+	m        sync.RWMutex
+	replicas int
+	points   []uint64          // Sorted hashes of virtual nodes.
+	owners   map[uint64]string // Nodes by the hashes of their virtual nodes.
+	gen      uint64            // Of the last removal of nodes.
+}
+
+// NewRing returns a new Ring with the given number of virtual nodes per
+// node (DefaultReplicas, if not positive) and nodes.
+func NewRing(replicas int, nodes ...string) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+	r := &Ring{
+		replicas: replicas,
+		owners:   make(map[uint64]string),
+	}
+	r.Add(nodes...)
+	return r
+}
+
+// Add nodes to the ring.
+func (r *Ring) Add(nodes ...string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, n := range nodes {
+		for i := 0; i < r.replicas; i++ {
+			p := hash(strconv.Itoa(i) + "\x00" + n)
+			if _, taken := r.owners[p]; taken {
+				continue
+			}
+			r.owners[p] = n
+			r.points = append(r.points, p)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+}
+
+// Remove nodes from the ring.
+func (r *Ring) Remove(nodes ...string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.gen++
+	rm := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		rm[n] = true
+	}
+	points := r.points[:0]
+	for _, p := range r.points {
+		if rm[r.owners[p]] {
+			delete(r.owners, p)
+			continue
+		}
+		points = append(points, p)
+	}
+	r.points = points
+}
+
+// Node returns the node that key is assigned to, or false if the ring
+// has no nodes.
+func (r *Ring) Node(key string) (node string, ok bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	if len(r.points) == 0 {
+		return "", false
+	}
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]], true
+}
+
+// Nodes returns the nodes on the ring, in no particular order.
+func (r *Ring) Nodes() []string {
+	nodes, _ := r.nodes()
+	return nodes
+}
+
+// nodes returns the nodes on the ring, and the generation of the last
+// removal of nodes from it.
+func (r *Ring) nodes() (nodes []string, gen uint64) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	seen := make(map[string]bool)
+	for _, n := range r.owners {
+		if !seen[n] {
+			seen[n] = true
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, r.gen
+}
+
+// removals returns the generation of the last removal of nodes from
+// the ring.
+func (r *Ring) removals() uint64 {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.gen
+}
+
+// hash s with FNV-1a, finalized like MurmurHash3 to spread the similar
+// names of virtual nodes evenly.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// A Router shards keys across the caches served at multiple nodes, by
+// the textual forms of the keys, using a Ring. Nodes can be added to and
+// removed from the ring while it is in use, and the clients of removed
+// nodes are dropped once keys are next routed.
+type Router struct {
+	ring *Ring
+	tmpl Client
+
+	m       sync.Mutex
+	clients map[string]*Client
+	gen     uint64 // Of the ring as of the last pruning of clients.
+}
+
+// NewRouter returns a new Router for ring, that makes requests to the
+// base URLs of its nodes with clients configured like tmpl.
+func NewRouter(ring *Ring, tmpl Client) *Router {
+	return &Router{
+		ring:    ring,
+		tmpl:    tmpl,
+		clients: make(map[string]*Client),
+	}
+}
+
+// Client returns the client for the node that key is assigned to, or
+// nil if the ring has no nodes.
+func (r *Router) Client(key string) *Client {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.prune()
+	node, ok := r.ring.Node(key)
+	if !ok {
+		return nil
+	}
+	c, ok := r.clients[node]
+	if !ok {
+		c = new(Client)
+		*c = r.tmpl
+		c.BaseURL = node
+		r.clients[node] = c
+	}
+	return c
+}
+
+// Get the JSON of the value at key from the node it is assigned to.
+func (r *Router) Get(
+	ctx context.Context,
+	key string,
+) (value json.RawMessage, ok bool, err error) {
+	c := r.Client(key)
+	if c == nil {
+		return nil, false, ErrNoNodes
+	}
+	return c.Get(ctx, key)
+}
+
+// Put the JSON of a value at key, at the node it is assigned to, with
+// the given time-to-live, or the cache-default one, if zero.
+func (r *Router) Put(
+	ctx context.Context,
+	key string,
+	value json.RawMessage,
+	ttl time.Duration,
+) error {
+	c := r.Client(key)
+	if c == nil {
+		return ErrNoNodes
+	}
+	return c.Put(ctx, key, value, ttl)
+}
+
+// Drop the item at key from the node it is assigned to.
+func (r *Router) Drop(ctx context.Context, key string) (ok bool, err error) {
+	c := r.Client(key)
+	if c == nil {
+		return false, ErrNoNodes
+	}
+	return c.Drop(ctx, key)
+}
+
+// Keys returns the keys of the items cached at all nodes.
+func (r *Router) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	for _, node := range r.ring.Nodes() {
+		c := r.tmpl
+		c.BaseURL = node
+		k, err := c.Keys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k...)
+	}
+	return keys, nil
+}
+
+// prune the clients of the nodes removed from the ring since the last
+// call. The router must be locked.
+func (r *Router) prune() {
+	if r.ring.removals() == r.gen {
+		return
+	}
+	nodes, gen := r.ring.nodes()
+	on := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		on[n] = true
+	}
+	for n := range r.clients {
+		if !on[n] {
+			delete(r.clients, n)
+		}
+	}
+	r.gen = gen
+}
@@ -0,0 +1,188 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cachehttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/cachehttp"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(0)
+	if _, ok := r.Node("a"); ok {
+		t.Error("empty ring should not assign keys")
+	}
+	r.Add("n1", "n2", "n3")
+
+	const keys = 10000
+	before := make(map[string]string, keys)
+	count := map[string]int{}
+	for i := 0; i < keys; i++ {
+		k := strconv.Itoa(i)
+		n, _ := r.Node(k)
+		before[k] = n
+		count[n]++
+	}
+	for n, c := range count {
+		if c < keys/6 {
+			t.Errorf("node %s got %d of %d keys", n, c, keys)
+		}
+	}
+
+	r.Add("n4")
+	moved := 0
+	for k, was := range before {
+		n, _ := r.Node(k)
+		if n != was {
+			moved++
+			if n != "n4" {
+				t.Fatalf("key %s moved from %s to %s, not the new node", k, was, n)
+			}
+		}
+	}
+	if moved == 0 || moved > keys/2 {
+		t.Errorf("moved %d of %d keys when adding a node", moved, keys)
+	}
+
+	r.Remove("n4")
+	for k, was := range before {
+		if n, _ := r.Node(k); n != was {
+			t.Fatalf("key %s at %s, want %s after removing the node", k, n, was)
+		}
+	}
+
+	nodes := r.Nodes()
+	sort.Strings(nodes)
+	if len(nodes) != 3 || nodes[0] != "n1" || nodes[2] != "n3" {
+		t.Errorf("Nodes() got=%v, want=[n1 n2 n3]", nodes)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	ctx := context.Background()
+	var urls []string
+	caches := map[string]*cache.Cache[string, int]{}
+	for i := 0; i < 3; i++ {
+		c := cache.New[string, int](time.Minute)
+		defer c.Shutdown()
+		srv := httptest.NewServer(NewHandler(c))
+		defer srv.Close()
+		urls = append(urls, srv.URL)
+		caches[srv.URL] = c
+	}
+	ring := NewRing(0, urls...)
+	router := NewRouter(ring, Client{})
+
+	for i := 0; i < 30; i++ {
+		k := strconv.Itoa(i)
+		n, _ := ring.Node(k)
+		caches[n].Put(k, i)
+	}
+	for i := 0; i < 30; i++ {
+		k := strconv.Itoa(i)
+		if v, ok, err := router.Get(ctx, k); err != nil || !ok || string(v) != k {
+			t.Errorf("Get(%s) got=%s,%v,%v", k, v, ok, err)
+		}
+	}
+	if ok, err := router.Drop(ctx, "7"); err != nil || !ok {
+		t.Errorf("Drop(7) got=%v,%v, want=true,nil", ok, err)
+	}
+	keys, err := router.Keys(ctx)
+	if err != nil || len(keys) != 29 {
+		t.Errorf("Keys() got %d keys,%v, want 29", len(keys), err)
+	}
+
+	empty := NewRouter(NewRing(0), Client{})
+	if _, _, err := empty.Get(ctx, "a"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("Get() error: got=%v, want=%v", err, ErrNoNodes)
+	}
+}
+
+func TestRouterPut(t *testing.T) {
+	ctx := context.Background()
+	var urls []string
+	caches := map[string]*cache.Cache[string, json.RawMessage]{}
+	for i := 0; i < 4; i++ {
+		c := cache.New[string, json.RawMessage](time.Minute)
+		defer c.Shutdown()
+		srv := httptest.NewServer(NewHandler(c))
+		defer srv.Close()
+		urls = append(urls, srv.URL)
+		caches[srv.URL] = c
+	}
+	ring := NewRing(0, urls[:3]...)
+	router := NewRouter(ring, Client{})
+
+	const keys = 300
+	put := func() {
+		for i := 0; i < keys; i++ {
+			k := strconv.Itoa(i)
+			if err := router.Put(ctx, k, json.RawMessage(k), 0); err != nil {
+				t.Fatalf("Put(%s) error: %v", k, err)
+			}
+		}
+	}
+	// where returns the nodes that hold the keys.
+	where := func() map[string]string {
+		at := make(map[string]string, keys)
+		for url, c := range caches {
+			for _, k := range c.Keys() {
+				at[k] = url
+			}
+			c.Clear()
+		}
+		return at
+	}
+	put()
+	before := where()
+	if len(before) != keys {
+		t.Fatalf("put %d of %d keys", len(before), keys)
+	}
+	for k, url := range before {
+		if n, _ := ring.Node(k); n != url {
+			t.Fatalf("key %s put at %s, want %s", k, url, n)
+		}
+	}
+
+	ring.Add(urls[3])
+	put()
+	moved := 0
+	for k, url := range where() {
+		if url != before[k] {
+			moved++
+			if url != urls[3] {
+				t.Fatalf("key %s put at %s, not the new node", k, url)
+			}
+		}
+	}
+	if moved == 0 || moved > keys/2 {
+		t.Errorf("moved %d of %d keys when adding a node", moved, keys)
+	}
+
+	ring.Remove(urls[3])
+	put()
+	for k, url := range where() {
+		if url != before[k] {
+			t.Fatalf("key %s put at %s, want %s after removing the node", k, url, before[k])
+		}
+	}
+	if n := router.Clients(); n != 3 {
+		t.Errorf("Clients() got=%d, want=3 after removing a node", n)
+	}
+
+	empty := NewRouter(NewRing(0), Client{})
+	if err := empty.Put(ctx, "a", json.RawMessage("1"), 0); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("Put() error: got=%v, want=%v", err, ErrNoNodes)
+	}
+}
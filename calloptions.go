@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// A CallOption overrides the behavior of a Cache for a single call.
+type CallOption func(*callOptions)
+
+// TTL sets the time-to-live of the item put by the call, instead of the
+// cache-default one.
+func TTL(ttl time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.ttl = ttl
+		o.ttlSet = true
+	}
+}
+
+// NoSlide makes the call look an item up without extending its
+// lifetime.
+func NoSlide() CallOption {
+	return func(o *callOptions) {
+		o.noSlide = true
+	}
+}
+
+// DoNotPromote makes the call look an item up without extending its
+// lifetime, like NoSlide does, and GetOrPut not put the value it loads,
+// e.g., for batch jobs that read the entire key space once, so that
+// they do not displace the working set of interactive use.
+func DoNotPromote() CallOption {
+	return func(o *callOptions) {
+		o.noSlide = true
+		o.noPromote = true
+	}
+}
+
+// MaxLifetime caps the lifetime of the item put by the call at d since
+// it is put, so that lookups do not extend it any further, e.g., for
+// sliding expiry to not keep frequently looked up items forever.
+func MaxLifetime(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.maxLife = d
+	}
+}
+
+// Pin the item put by the call, so that it neither expires, nor gets
+// evicted, until it is put again without this option, or dropped.
+//
+// A cache that only holds pinned items can exceed its limits.
+func Pin() CallOption {
+	return func(o *callOptions) {
+		o.pin = true
+	}
+}
+
+// GetWith gets a cached item like Get does, with the behavior
+// overridden by opts.
+func (c *Cache[K, V]) GetWith(key K, opts ...CallOption) (value V, ok bool) {
+	o := c.callOptions(key, opts)
+	c.lock()
+	c.ctx = o.ctx
+	val, ok := c.find(key, !o.noSlide)
+	c.lookup(key, ok)
+	c.unlock()
+	return c.read(key, val.Value(), ok)
+}
+
+// Internals.
+
+type callOptions struct {
+	ttl     time.Duration
+	ttlSet  bool      // Whether ttl has been given explicitly.
+	until   time.Time // Absolute expiry, if not zero, instead of ttl.
+	noSlide bool
+	pin     bool
+	maxLife time.Duration // Cap on the lifetime, if positive.
+
+	noPromote bool            // Whether loaded values are not put.
+	origin    string          // Of the values put, see Origin.
+	ctx       context.Context // Of the call, if given, see Context.
+}
+
+// callOptions returns the cache defaults for key overridden by opts.
+func (c *Cache[K, V]) callOptions(key K, opts []CallOption) callOptions {
+	o := callOptions{ttl: c.ttlFor(key)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// expiry returns the expiry time of an item put at now with o.
+func (o callOptions) expiry(now time.Time) time.Time {
+	if o.pin {
+		return now.Add(indefinite)
+	}
+	return capped(now.Add(o.lifetime(now)), o.limit(now))
+}
+
+// limit returns the latest expiry time of an item put at now with o,
+// or zero, if not capped.
+func (o callOptions) limit(now time.Time) time.Time {
+	if o.maxLife <= 0 {
+		return time.Time{}
+	}
+	return now.Add(o.maxLife)
+}
+
+// capped returns expiry time x capped at limit, unless that is zero.
+func capped(x, limit time.Time) time.Time {
+	if !limit.IsZero() && x.After(limit) {
+		return limit
+	}
+	return x
+}
+
+// lifetime returns the time-to-live of an item put at now with o.
+func (o callOptions) lifetime(now time.Time) time.Duration {
+	if o.until.IsZero() {
+		return o.ttl
+	}
+	return o.until.Sub(now)
+}
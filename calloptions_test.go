@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestNoSlide(t *testing.T) {
+	c := New[string, int](2 * ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	time.Sleep(3 * ttl / 2)
+	_, ok := c.GetWith("a", NoSlide())
+	req.Assert(ok, "GetWith(a, NoSlide()) should find 'a'")
+	_, ok = c.GetOrPut("b", nil, NoSlide())
+	req.Assert(ok, "GetOrPut(b, nil, NoSlide()) should find 'b'")
+	time.Sleep(ttl)
+	req.HasNot("a")
+	req.HasNot("b")
+}
+
+func TestDoNotPromote(t *testing.T) {
+	c := New[string, int](2 * ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	time.Sleep(3 * ttl / 2)
+	_, ok := c.GetWith("a", DoNotPromote())
+	req.Assert(ok, "GetWith(a, DoNotPromote()) should find 'a'")
+	v, ok := c.GetOrPut("b", SimpleGetterFunc[string, int](func() int { return 2 }),
+		DoNotPromote())
+	req.Assert(ok && v == 2, "GetOrPut(b, DoNotPromote()) got=%v,%v, want=2,true", v, ok)
+	req.HasNot("b")
+	time.Sleep(ttl)
+	req.HasNot("a")
+}
+
+func TestTTLOption(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1, TTL(ttl))
+	c.GetOrPut("b", SimpleGetterFunc[string, int](func() int { return 2 }), TTL(ttl))
+	c.Put("c", 3)
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("a")
+	req.HasNot("b")
+	req.Has("c")
+}
+
+func TestPin(t *testing.T) {
+	c := New[string, int](ttl, WithCapacity(2))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1, Pin())
+	c.Put("b", 2)
+	c.Put("c", 3)
+	req.Has("a")
+	req.HasNot("b")
+	req.Get("a") // Does not unpin.
+	time.Sleep(3 * ttl / 2)
+	req.Has("a")
+	req.HasNot("c")
+
+	var buf bytes.Buffer
+	if err := c.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	dst := New[string, int](ttl)
+	defer dst.Shutdown()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	dst.Get("a")
+	time.Sleep(3 * ttl / 2)
+	newAssert(t, dst, true).Has("a")
+
+	// Putting without Pin unpins.
+	c.Put("a", 1)
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("a")
+}
+
+func TestMaxLifetime(t *testing.T) {
+	c := New[string, int](3 * ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1, MaxLifetime(5*ttl))
+	c.Put("b", 2)
+	for i := 0; i < 4; i++ {
+		time.Sleep(2 * ttl)
+		req.Touch("b")
+		c.Touch("a") // Extends its lifetime up to the cap.
+	}
+	req.HasNot("a")
+	req.Has("b")
+
+	c.Put("c", 3, MaxLifetime(ttl))
+	if e, _ := c.GetEntry("c"); e.Remaining > ttl {
+		t.Errorf("remaining lifetime of c got=%v, want at most %v", e.Remaining, ttl)
+	}
+}
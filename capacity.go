@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "unsafe"
+
+// WithCapacity limits the number of items in a Cache to n, see
+// SetCapacity.
+func WithCapacity(n int) Option {
+	return func(o *options) {
+		o.capacity = n
+	}
+}
+
+// WithMaxBytes limits the total size of the items in a Cache to b
+// bytes, see SetMaxBytes.
+func WithMaxBytes(b int64) Option {
+	return func(o *options) {
+		o.maxBytes = b
+	}
+}
+
+// WithSizer sets the function measuring the sizes of items, in bytes,
+// for a Cache of the same key and value types. By default, the sizes
+// are the lengths of []byte and string values, and the shallow sizes
+// of values of other types.
+//
+// New panics if the types do not match those of the cache.
+func WithSizer[K comparable, V any](size func(key K, value V) int64) Option {
+	return func(o *options) {
+		o.sizer = size
+	}
+}
+
+// SetCapacity limits the number of items in the cache to n, or lifts
+// the limit if n is not positive. While the limit is exceeded, the
+// items selected by the eviction policy, by default the closest to
+// expiry, are evicted, immediately on a call that lowers it.
+func (c *Cache[K, V]) SetCapacity(n int) {
+	c.lock()
+	defer c.unlock()
+	c.o.capacity = n
+	c.evict(nil)
+}
+
+// SetMaxBytes limits the total size of the items in the cache to b
+// bytes, or lifts the limit if b is not positive. While the limit is
+// exceeded, the items selected by the eviction policy are evicted,
+// immediately on a call that lowers it.
+func (c *Cache[K, V]) SetMaxBytes(b int64) {
+	c.lock()
+	defer c.unlock()
+	c.o.maxBytes = b
+	c.evict(nil)
+}
+
+// UpdateCost sets the size of the cached item at key to cost bytes,
+// e.g., after its value has grown in place, instead of the one measured
+// when the value was put, until a new value is put at the key. Items
+// closest to expiry, possibly including this one, are evicted should
+// the cache exceed its size limit. Returns false if the key has not
+// been found in the cache.
+func (c *Cache[K, V]) UpdateCost(key K, cost int64) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	val.size = cost
+	val.costSet = true
+	c.store(key, val)
+	return true
+}
+
+// Remeasure the size of the cached item at key with the sizer, e.g.,
+// after its value has grown in place, dropping the one set by
+// UpdateCost, if any, and evicting items like UpdateCost does. Returns
+// false if the key has not been found in the cache.
+func (c *Cache[K, V]) Remeasure(key K) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	val.costSet = false
+	c.store(key, val)
+	return true
+}
+
+// Internals.
+
+// sizerOf returns the sizer set by WithSizer, or the default one.
+func sizerOf[K comparable, V any](o options) func(K, V) int64 {
+	if o.sizer == nil {
+		return defaultSize[K, V]
+	}
+	size, ok := o.sizer.(func(K, V) int64)
+	if !ok {
+		panic("cache: WithSizer types do not match those of the cache")
+	}
+	return size
+}
+
+func defaultSize[K comparable, V any](_ K, v V) int64 {
+	switch v := any(v).(type) {
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	}
+	return int64(unsafe.Sizeof(v))
+}
+
+// overLimit returns whether the cache exceeds its capacity or size.
+func (c *Cache[K, V]) overLimit() bool {
+	return c.o.capacity > 0 && len(c.d) > c.o.capacity ||
+		c.o.maxBytes > 0 && c.bytes > c.o.maxBytes
+}
+
+// evict the items selected by the eviction policy while the cache is
+// over its limits. Pinned items are never evicted, nor, under EvictLFU,
+// is the one with timer keep, if not nil, which has just been put and
+// has not had the chance to be used yet.
+func (c *Cache[K, V]) evict(keep *itemTimer[K]) {
+	match := unpinned[K, V]
+	if c.o.eviction == EvictLFU && keep != nil {
+		match = func(val entry[K, V]) bool {
+			return !val.pin && val.t != keep
+		}
+	}
+	for c.overLimit() && c.th.Len() > 0 {
+		// Pinned items are the last to expire, so the soonest one only
+		// needs checking, unless the policy is not EvictSoonest.
+		t := c.th[0]
+		if c.o.eviction != EvictSoonest {
+			t = c.victim(match)
+		}
+		if t == nil || c.d[t.k].pin {
+			break
+		}
+		c.unschedule(t)
+		c.remove(t.k, ReasonEvicted)
+		c.stats.Evicted++
+	}
+	if len(c.o.budgets) > 0 {
+		c.evictClasses()
+	}
+}
+
+// unpinned returns whether val is not pinned.
+func unpinned[K comparable, V any](val entry[K, V]) bool {
+	return !val.pin
+}
@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestCapacity(t *testing.T) {
+	c := New[int, int](time.Minute, WithCapacity(3))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	for i := 0; i < 4; i++ {
+		c.PutWithTTL(i, i, time.Duration(i+1)*time.Minute)
+	}
+	req.LengthIs(3)
+	req.HasNot(0)
+
+	c.SetCapacity(1)
+	req.LengthIs(1)
+	req.Has(3)
+
+	c.SetCapacity(0)
+	c.Put(4, 4)
+	c.Put(5, 5)
+	req.LengthIs(3)
+	if n := c.Stats().Evicted; n != 3 {
+		t.Errorf("Evicted got=%d, want=3", n)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	c := NewStringBytes(time.Minute, WithMaxBytes(10))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.PutWithTTL("a", []byte("12345"), time.Minute)
+	c.PutWithTTL("b", []byte("12345"), 2*time.Minute)
+	req.LengthIs(2)
+	c.PutWithTTL("c", []byte("1"), 3*time.Minute)
+	req.HasNot("a")
+	if b := c.Stats().Bytes; b != 6 {
+		t.Errorf("Bytes got=%d, want=6", b)
+	}
+
+	// Replacing a value accounts for the size of the old one.
+	c.PutWithTTL("c", []byte("1234"), 3*time.Minute)
+	req.LengthIs(2)
+
+	c.SetMaxBytes(4)
+	req.LengthIs(1)
+	req.Has("c")
+}
+
+func TestWithSizer(t *testing.T) {
+	size := func(k string, v []string) int64 {
+		return int64(len(k) + len(strings.Join(v, "")))
+	}
+	c := New[string, []string](time.Minute, WithMaxBytes(4), WithSizer(size))
+	defer c.Shutdown()
+	c.Put("ab", []string{"c", "d"})
+	if b := c.Stats().Bytes; b != 4 {
+		t.Errorf("Bytes got=%d, want=4", b)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New() should panic on mismatched sizer types")
+		}
+	}()
+	New[int, int](time.Minute, WithSizer(size))
+}
+
+func TestUpdateCost(t *testing.T) {
+	size := func(_ string, v *[]byte) int64 { return int64(len(*v)) }
+	c := New[string, *[]byte](time.Minute, WithMaxBytes(10), WithSizer(size))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	a, b := []byte("123"), []byte("123")
+	c.PutWithTTL("a", &a, time.Minute)
+	c.PutWithTTL("b", &b, 2*time.Minute)
+
+	if !c.UpdateCost("b", 6) {
+		t.Fatal("UpdateCost(b) should find 'b'")
+	}
+	if got := c.Stats().Bytes; got != 9 {
+		t.Errorf("Bytes got=%d, want=9", got)
+	}
+	c.Tag("b", "t") // Keeps the cost set.
+	if got := c.Stats().Bytes; got != 9 {
+		t.Errorf("Bytes after Tag got=%d, want=9", got)
+	}
+
+	// Growing in place goes unnoticed until remeasured.
+	a = append(a, "45678"...)
+	if got := c.Stats().Bytes; got != 9 {
+		t.Errorf("Bytes got=%d, want=9", got)
+	}
+	c.Remeasure("a")
+	req.HasNot("a") // Evicted, closest to expiry.
+	req.Has("b")
+
+	// Putting a new value measures it again.
+	c.PutWithTTL("b", &b, 2*time.Minute)
+	if got := c.Stats().Bytes; got != 3 {
+		t.Errorf("Bytes after Put got=%d, want=3", got)
+	}
+	if c.UpdateCost("x", 1) || c.Remeasure("x") {
+		t.Error("should not find 'x'")
+	}
+}
@@ -0,0 +1,177 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// WithKeyCardinality makes a Cache of the same key type estimate the
+// number of distinct keys looked up, whether found or not, in the last
+// window to two, with a HyperLogLog sketch of the keys hashed by hash,
+// e.g., HashString, see DistinctKeys. Comparing that to the number of
+// items helps to size the cache to the working set.
+//
+// The estimates are accurate to about 1%, for about 32 KiB of memory.
+//
+// New panics if the key type does not match that of the cache.
+func WithKeyCardinality[K comparable](
+	window time.Duration,
+	hash func(key K) uint64,
+) Option {
+	return func(o *options) {
+		o.cardWindow = window
+		o.cardHash = hash
+	}
+}
+
+// DistinctKeys returns the estimated number of distinct keys looked up
+// in the cache since the start of the previous window, or zero, if it
+// does not estimate that, see WithKeyCardinality.
+func (c *Cache[K, V]) DistinctKeys() uint64 {
+	if c.card == nil {
+		return 0
+	}
+	return c.card.estimate(time.Now())
+}
+
+// Internals.
+
+// cardHashOf returns the function set by WithKeyCardinality.
+func cardHashOf[K comparable](o options) func(K) uint64 {
+	if o.cardHash == nil {
+		return nil
+	}
+	f, ok := o.cardHash.(func(K) uint64)
+	if !ok {
+		panic("cache: WithKeyCardinality key type does not match that of the cache")
+	}
+	return f
+}
+
+// lookup counts a lookup of key as a hit or a miss, and in the distinct
+// keys, if estimated. The cache must be locked.
+func (c *Cache[K, V]) lookup(key K, hit bool) {
+	c.stats.count(hit)
+	c.countKey(key)
+}
+
+// countKey in the distinct keys, if estimated.
+func (c *Cache[K, V]) countKey(key K) {
+	if c.card != nil {
+		c.card.add(c.cardHash(key), time.Now())
+	}
+}
+
+// hllBits is the number of hash bits that index the registers of a
+// hyperLogLog.
+const hllBits = 14
+
+// A hyperLogLog sketch of a set of hashes.
+type hyperLogLog [1 << hllBits]uint8
+
+// add hash h to the set.
+func (s *hyperLogLog) add(h uint64) {
+	i := h >> (64 - hllBits)
+	rank := uint8(bits.LeadingZeros64(h<<hllBits|1<<(hllBits-1)) + 1)
+	if rank > s[i] {
+		s[i] = rank
+	}
+}
+
+// merge the set of o into the set.
+func (s *hyperLogLog) merge(o *hyperLogLog) {
+	for i, r := range o {
+		if r > s[i] {
+			s[i] = r
+		}
+	}
+}
+
+// estimate the number of distinct hashes in the set.
+func (s *hyperLogLog) estimate() uint64 {
+	const m = float64(len(s))
+	var sum float64
+	zeros := 0
+	for _, r := range s {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small sets.
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(e + 0.5)
+}
+
+// A keyCounter estimates the number of distinct keys in two consecutive
+// windows of time, the current and the previous one.
+type keyCounter struct {
+	m      sync.Mutex
+	window time.Duration
+	start  time.Time // Of the current window.
+	cur    *hyperLogLog
+	prev   *hyperLogLog
+}
+
+func newKeyCounter(window time.Duration) *keyCounter {
+	return &keyCounter{
+		window: window,
+		start:  time.Now(),
+		cur:    new(hyperLogLog),
+		prev:   new(hyperLogLog),
+	}
+}
+
+// add a key hash seen at now.
+func (k *keyCounter) add(h uint64, now time.Time) {
+	k.m.Lock()
+	defer k.m.Unlock()
+	k.rotate(now)
+	k.cur.add(mix64(h))
+}
+
+// estimate the number of distinct keys as of now.
+func (k *keyCounter) estimate(now time.Time) uint64 {
+	k.m.Lock()
+	defer k.m.Unlock()
+	k.rotate(now)
+	s := *k.cur
+	s.merge(k.prev)
+	return s.estimate()
+}
+
+// rotate the windows as of now.
+func (k *keyCounter) rotate(now time.Time) {
+	if k.window <= 0 {
+		return
+	}
+	n := now.Sub(k.start) / k.window
+	if n < 1 {
+		return
+	}
+	k.start = k.start.Add(n * k.window)
+	k.prev, k.cur = k.cur, k.prev
+	*k.cur = hyperLogLog{}
+	if n > 1 {
+		*k.prev = hyperLogLog{}
+	}
+}
+
+// mix64 spreads the bits of h, for hashes that are weak in some.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithKeyCardinality(t *testing.T) {
+	for _, n := range []int{100, 10000, 200000} {
+		c := New[string, int](time.Minute, WithKeyCardinality(time.Hour, HashString))
+		c.Put("0", 0)
+		for i := 0; i < n; i++ {
+			c.Get(strconv.Itoa(i))
+			c.Get(strconv.Itoa(i % 10))
+		}
+		got := float64(c.DistinctKeys())
+		if got < 0.97*float64(n) || got > 1.03*float64(n) {
+			t.Errorf("DistinctKeys() of %d got=%v", n, got)
+		}
+		c.Shutdown()
+	}
+}
+
+func TestWithKeyCardinalityWindow(t *testing.T) {
+	c := New[string, int](time.Minute, WithKeyCardinality(2*ttl, HashString))
+	defer c.Shutdown()
+	u := New[string, int](ttl)
+	defer u.Shutdown()
+	u.Get("a")
+	if got := u.DistinctKeys(); got != 0 {
+		t.Errorf("DistinctKeys() without estimating got=%d", got)
+	}
+
+	c.Get("a")
+	c.Get("b")
+	time.Sleep(2 * ttl)
+	c.Get("c")
+	if got := c.DistinctKeys(); got != 3 {
+		t.Errorf("DistinctKeys() over two windows got=%d, want=3", got)
+	}
+	time.Sleep(2 * ttl)
+	if got := c.DistinctKeys(); got != 1 {
+		t.Errorf("DistinctKeys() a window later got=%d, want=1", got)
+	}
+	time.Sleep(4 * ttl)
+	if got := c.DistinctKeys(); got != 0 {
+		t.Errorf("DistinctKeys() two windows later got=%d, want=0", got)
+	}
+}
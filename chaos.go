@@ -0,0 +1,23 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build cachetest
+
+package cache
+
+import "time"
+
+// WithChaos makes a Cache misbehave, so that the fallback paths of the
+// code using it can be tested against realistic failures: every load
+// from a provider is delayed by latency, and fails (as if the provider
+// reported no value) at errorRate, between 0 and 1, which is also the
+// rate at which the unpinned items looked up are evicted early, as if
+// the cache were over its limits.
+//
+// It is only built with the cachetest build tag, like Expiries.
+func WithChaos(latency time.Duration, errorRate float64) Option {
+	return func(o *options) {
+		o.chaos = &chaos{latency, errorRate}
+	}
+}
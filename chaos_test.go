@@ -0,0 +1,38 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build cachetest
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithChaos(t *testing.T) {
+	one := SimpleGetterFunc[string, int](func() int { return 1 })
+
+	c := New[string, int](time.Minute, WithChaos(ttl, 0))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	start := time.Now()
+	req.GetOrPut("a", one)
+	req.Assert(time.Since(start) >= ttl, "load should have been delayed")
+	req.Has("a")
+
+	c = New[string, int](time.Minute, WithChaos(0, 1))
+	defer c.Shutdown()
+	req = newAssert(t, c, true)
+	_, ok := c.GetOrPut("a", one)
+	req.AssertNot(ok, "load should have failed")
+	c.Put("b", 2)
+	c.Put("c", 3, Pin())
+	req.HasNot("b")
+	req.Has("c")
+	req.Assert(c.Stats().Evicted == 1, "Stats().Evicted got=%d, want=1",
+		c.Stats().Evicted)
+}
@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// ClockDriftTolerance is the amount by which the wall clock may drift
+// from the monotonic clock before a cache synchronizing with the wall
+// clock re-derives its expiry schedule.
+const ClockDriftTolerance = time.Second
+
+// WithWallClockSync makes a Cache compare the wall clock against the
+// monotonic clock every interval and, when they have drifted apart by
+// more than ClockDriftTolerance, re-derive the expiry times of all
+// items from the wall clock times they were scheduled for.
+//
+// That keeps the items from outliving their wall clock expiry times
+// when the monotonic clock stalls, e.g., while a virtual machine is
+// frozen. It also makes the items follow the steps of the wall clock,
+// e.g., by NTP, expiring early when it jumps forward, so this should
+// only be used where the wall clock is trusted.
+func WithWallClockSync(interval time.Duration) Option {
+	return func(o *options) {
+		o.clockSync = interval
+	}
+}
+
+// Internals.
+
+// clocks are the readings of the monotonic and the wall clock at the
+// last synchronization.
+type clocks struct {
+	mono time.Time
+	wall time.Time
+}
+
+// markClocks records the readings of now as of the last
+// synchronization.
+func (c *Cache[K, V]) markClocks(now time.Time) {
+	c.clk = clocks{
+		mono: now,
+		wall: now.Round(0),
+	}
+}
+
+// syncClock re-derives the expiry schedule from the wall clock if it
+// has drifted from the monotonic one since the last synchronization.
+// The mono time must have a monotonic clock reading, the wall one must
+// not.
+func (c *Cache[K, V]) syncClock(mono time.Time, wall time.Time) {
+	drift := wall.Sub(c.clk.wall) - mono.Sub(c.clk.mono)
+	c.clk = clocks{
+		mono: mono,
+		wall: wall,
+	}
+	if drift > -ClockDriftTolerance && drift < ClockDriftTolerance {
+		return
+	}
+	for _, t := range c.th {
+		// The wall clock reading of an expiry time is the one it was
+		// scheduled for.
+		t.x = mono.Add(t.x.Round(0).Sub(wall))
+	}
+	heap.Init(&c.th)
+	heap.Init(&c.fh)
+	if len(c.th) > 0 {
+		c.t.Reset(time.Until(c.th[0].x))
+	}
+}
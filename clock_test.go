@@ -0,0 +1,32 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWallClockSync(t *testing.T) {
+	c := New[string, int](time.Minute, WithWallClockSync(time.Hour))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.PutWithTTL("short", 1, 30*time.Minute)
+	c.PutWithTTL("long", 2, 90*time.Minute)
+
+	// A drift within tolerance changes nothing.
+	c.JumpWallClock(ClockDriftTolerance / 2)
+	time.Sleep(ttl)
+	req.LengthIs(2)
+
+	// As if the monotonic clock stalled for an hour.
+	c.JumpWallClock(time.Hour)
+	time.Sleep(ttl)
+	req.HasNot("short")
+	req.Has("long")
+}
@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Command cachectl operates a live cache through the endpoints served
+// by a cachehttp.Handler.
+//
+// Usage:
+//
+//	cachectl [-url URL] [-token TOKEN] COMMAND [ARGS]
+//
+// Commands:
+//
+//	keys                 List the keys of the cached items.
+//	get KEY              Print the JSON of the value at KEY.
+//	drop KEY             Drop the item at KEY.
+//	stats                Print the cache usage statistics.
+//	dump [-z] [FILE]     Write a snapshot to FILE, or standard output,
+//	                     gzip-compressed with -z.
+//	restore [FILE]       Restore a snapshot from FILE, or standard input.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/antichris/go-cache/cachehttp"
+)
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cachectl:", err)
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("usage: cachectl [-url URL] [-token TOKEN] " +
+	"keys|get KEY|drop KEY|stats|dump [-z] [FILE]|restore [FILE]")
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("cachectl", flag.ContinueOnError)
+	baseURL := fs.String("url", envOr("CACHECTL_URL", "http://localhost:8080"),
+		"base `URL` of the cache endpoints (default $CACHECTL_URL)")
+	token := fs.String("token", os.Getenv("CACHECTL_TOKEN"),
+		"bearer `TOKEN` to authorize with (default $CACHECTL_TOKEN)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	c := &cachehttp.Client{
+		BaseURL: *baseURL,
+		Token:   *token,
+	}
+	cmd, args := fs.Arg(0), fs.Args()[1:]
+
+	switch cmd {
+	case "keys":
+		keys, err := c.Keys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			fmt.Fprintln(stdout, k)
+		}
+	case "get":
+		if len(args) != 1 {
+			return errUsage
+		}
+		v, ok, err := c.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%q not found", args[0])
+		}
+		fmt.Fprintf(stdout, "%s\n", v)
+	case "drop":
+		if len(args) != 1 {
+			return errUsage
+		}
+		ok, err := c.Drop(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%q not found", args[0])
+		}
+	case "stats":
+		s, err := c.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		e := json.NewEncoder(stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(s)
+	case "dump":
+		dfs := flag.NewFlagSet("dump", flag.ContinueOnError)
+		compress := dfs.Bool("z", false, "gzip-compress the snapshot")
+		if err := dfs.Parse(args); err != nil {
+			return err
+		}
+		w, closeFn, err := create(dfs.Arg(0), stdout)
+		if err != nil {
+			return err
+		}
+		if err := c.Dump(ctx, w, *compress); err != nil {
+			closeFn()
+			return err
+		}
+		return closeFn()
+	case "restore":
+		r := stdin
+		if len(args) > 0 {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		return c.Restore(ctx, r)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+// create the named file, or return stdout if name is empty.
+func create(name string, stdout io.Writer) (io.Writer, func() error, error) {
+	if name == "" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
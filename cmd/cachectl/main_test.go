@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	"github.com/antichris/go-cache/cachehttp"
+)
+
+func TestRun(t *testing.T) {
+	c := cache.New[int, string](time.Minute)
+	defer c.Shutdown()
+	c.Put(1, "one")
+	c.Put(2, "two")
+	srv := httptest.NewServer(cachehttp.NewHandler(c))
+	defer srv.Close()
+
+	ctl := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		args = append([]string{"-url", srv.URL}, args...)
+		err := run(context.Background(), args, strings.NewReader(""), &out)
+		return out.String(), err
+	}
+
+	out, err := ctl("get", "2")
+	if err != nil || out != "\"two\"\n" {
+		t.Errorf("get 2: got=%q,%v, want=%q", out, err, "\"two\"\n")
+	}
+	if _, err := ctl("get", "3"); err == nil {
+		t.Error("get 3 should fail")
+	}
+	if _, err := ctl("drop", "2"); err != nil {
+		t.Errorf("drop 2 error: %v", err)
+	}
+	out, err = ctl("keys")
+	if err != nil || out != "1\n" {
+		t.Errorf("keys: got=%q,%v, want=%q", out, err, "1\n")
+	}
+
+	out, err = ctl("stats")
+	if err != nil {
+		t.Fatalf("stats error: %v", err)
+	}
+	var s cache.Stats
+	if err := json.Unmarshal([]byte(out), &s); err != nil || s.Items != 1 {
+		t.Errorf("stats: got=%q,%v, want 1 item", out, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "snapshot")
+	if _, err := ctl("dump", "-z", file); err != nil {
+		t.Fatalf("dump error: %v", err)
+	}
+	c.Drop(1)
+	if _, err := ctl("restore", file); err != nil {
+		t.Fatalf("restore error: %v", err)
+	}
+	if v, ok := c.Get(1); !ok || v != "one" {
+		t.Errorf("Get(1) after restore: got=%q,%v, want=%q,true", v, ok, "one")
+	}
+
+	if _, err := ctl("bogus"); err != errUsage {
+		t.Errorf("bogus: got=%v, want=%v", err, errUsage)
+	}
+}
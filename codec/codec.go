@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package codec implements a cache of values stored serialized.
+//
+// Serialized values take a precisely known amount of memory, never
+// share it with the values they are put and got as, and can be moved
+// between processes, e.g., in snapshots, regardless of their types.
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// A Codec serializes values.
+//
+// Other formats, e.g., MessagePack, can be used by adapting their
+// implementations to this interface.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON encodes values with encoding/json.
+	JSON Codec = jsonCodec{}
+	// Gob encodes values with encoding/gob.
+	Gob Codec = gobCodec{}
+)
+
+// A Cache of values stored serialized in an inner cache.
+type Cache[K comparable, T any] struct {
+	c     *cache.Cache[K, []byte]
+	codec Codec
+}
+
+// New Cache of values serialized with codec in c.
+func New[K comparable, T any](c *cache.Cache[K, []byte], codec Codec) *Cache[K, T] {
+	return &Cache[K, T]{c: c, codec: codec}
+}
+
+// Get the value of a cached item. Returns an error if the item cannot
+// be decoded.
+func (c *Cache[K, T]) Get(key K) (value T, ok bool, err error) {
+	b, ok := c.c.Get(key)
+	if !ok {
+		return
+	}
+	if err = c.codec.Unmarshal(b, &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Put a value in the cache with the cache-default time-to-live.
+func (c *Cache[K, T]) Put(key K, value T) error {
+	b, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.c.Put(key, b)
+	return nil
+}
+
+// PutWithTTL puts a value in the cache with the given time-to-live.
+func (c *Cache[K, T]) PutWithTTL(key K, value T, ttl time.Duration) error {
+	b, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.c.PutWithTTL(key, b, ttl)
+	return nil
+}
+
+// Drop a cached item and return whether it was present.
+func (c *Cache[K, T]) Drop(key K) bool {
+	_, ok := c.c.Drop(key)
+	return ok
+}
+
+// Inner returns the cache of serialized values.
+func (c *Cache[K, T]) Inner() *cache.Cache[K, []byte] {
+	return c.c
+}
+
+// Internals.
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
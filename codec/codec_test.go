@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package codec_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/codec"
+)
+
+type user struct {
+	Name  string
+	Roles []string
+}
+
+func TestCache(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"JSON", JSON},
+		{"Gob", Gob},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := cache.New[int, []byte](time.Minute)
+			defer inner.Shutdown()
+			c := New[int, user](inner, tt.codec)
+
+			u := user{"Bob", []string{"admin"}}
+			if err := c.Put(1, u); err != nil {
+				t.Fatalf("Put() error: %v", err)
+			}
+			// The cached value does not share memory with the one put.
+			u.Roles[0] = "guest"
+
+			got, ok, err := c.Get(1)
+			if err != nil || !ok {
+				t.Fatalf("Get(1) got=%t, %v", ok, err)
+			}
+			if got.Name != "Bob" || len(got.Roles) != 1 || got.Roles[0] != "admin" {
+				t.Errorf("Get(1) got=%+v", got)
+			}
+			if _, ok, _ := c.Get(2); ok {
+				t.Error("Get(2) should not find an absent item")
+			}
+			if !c.Drop(1) {
+				t.Error("Drop(1) should report the item present")
+			}
+			if c.Inner().Length() != 0 {
+				t.Error("the inner cache should be empty after Drop")
+			}
+		})
+	}
+}
+
+func TestCacheDecodeError(t *testing.T) {
+	inner := cache.New[int, []byte](time.Minute)
+	defer inner.Shutdown()
+	c := New[int, user](inner, JSON)
+
+	inner.Put(1, []byte("{"))
+	if _, ok, err := c.Get(1); ok || err == nil {
+		t.Errorf("Get(1) got=%t, %v, want an error", ok, err)
+	}
+	if err := New[int, chan int](inner, JSON).Put(2, nil); err == nil {
+		t.Error("Put() of an unencodable value should fail")
+	}
+}
@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package compiled implements a cache of artifacts that are expensive
+// to compile, e.g., templates, regular expressions or queries.
+//
+// Artifacts are keyed by the digests of their sources, so that a
+// changed source is compiled anew, and they can depend on named inputs,
+// e.g., the files that a template includes, so that they are dropped
+// when any of the inputs changes.
+package compiled
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// A Cache of artifacts compiled from sources.
+type Cache[T any] struct {
+	c *cache.Cache[string, T]
+}
+
+// New Cache of artifacts that expire after the ttl without use.
+func New[T any](ttl time.Duration) *Cache[T] {
+	return &Cache[T]{c: cache.New[string, T](ttl)}
+}
+
+// Get the artifact compiled from source by compile, compiling it, if
+// not cached, to depend on the named inputs. Failed compilations are
+// not cached.
+func (c *Cache[T]) Get(
+	source []byte,
+	compile func(source []byte) (T, error),
+	inputs ...string,
+) (artifact T, err error) {
+	key := artifactKey(source)
+	var compiled bool
+	artifact, ok := c.c.GetOrPut(key, cache.GetterFunc[string, T](
+		func(string) (artifact T, ok bool) {
+			artifact, err = compile(source)
+			compiled = err == nil
+			return artifact, compiled
+		},
+	))
+	if !ok {
+		if err == nil {
+			// The compilation failed in a concurrent call.
+			return c.Get(source, compile, inputs...)
+		}
+		return
+	}
+	if compiled && len(inputs) > 0 {
+		keys := make([]string, len(inputs))
+		for i, in := range inputs {
+			keys[i] = c.input(in)
+		}
+		c.c.DependOn(key, keys...)
+	}
+	return artifact, nil
+}
+
+// Changed drops the artifacts that depend on the named input.
+func (c *Cache[T]) Changed(input string) {
+	c.c.Drop(inputKey(input))
+}
+
+// Len returns the number of cached artifacts.
+func (c *Cache[T]) Len() (n int) {
+	for _, k := range c.c.Keys() {
+		if k[0] == artifactPrefix {
+			n++
+		}
+	}
+	return
+}
+
+// Shutdown the underlying cache.
+func (c *Cache[T]) Shutdown() {
+	c.c.Shutdown()
+}
+
+// Internals.
+
+// Key prefixes of the items in the underlying cache.
+const (
+	artifactPrefix = 'a'
+	inputPrefix    = 'i'
+)
+
+func artifactKey(source []byte) string {
+	sum := sha256.Sum256(source)
+	return string(artifactPrefix) + hex.EncodeToString(sum[:])
+}
+
+func inputKey(name string) string {
+	return string(inputPrefix) + name
+}
+
+// input returns the key of the item that stands for the named input in
+// the cache, putting it there, pinned, if absent.
+func (c *Cache[T]) input(name string) string {
+	key := inputKey(name)
+	var zero T
+	c.c.GetOrPut(key, cache.SimpleGetterFunc[string, T](
+		func() T { return zero },
+	), cache.Pin())
+	return key
+}
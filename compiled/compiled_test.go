@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package compiled_test
+
+import (
+	"regexp"
+	"testing"
+	"text/template"
+	"time"
+
+	. "github.com/antichris/go-cache/compiled"
+)
+
+func TestGet(t *testing.T) {
+	c := New[*regexp.Regexp](time.Minute)
+	defer c.Shutdown()
+	compiles := 0
+	compile := func(src []byte) (*regexp.Regexp, error) {
+		compiles++
+		return regexp.Compile(string(src))
+	}
+
+	a, err := c.Get([]byte("a+"), compile)
+	if err != nil || !a.MatchString("aa") {
+		t.Fatalf("Get(a+) got=%v, %v", a, err)
+	}
+	b, _ := c.Get([]byte("a+"), compile)
+	if a != b || compiles != 1 {
+		t.Errorf("Get(a+) again should be cached, compiles got=%d, want=1", compiles)
+	}
+	c.Get([]byte("b+"), compile)
+	if compiles != 2 {
+		t.Errorf("a changed source should be compiled, compiles got=%d, want=2", compiles)
+	}
+	if _, err := c.Get([]byte("("), compile); err == nil {
+		t.Error("Get(() should fail")
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() got=%d, want=2", n)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	c := New[*template.Template](time.Minute)
+	defer c.Shutdown()
+	compiles := 0
+	compile := func(src []byte) (*template.Template, error) {
+		compiles++
+		return template.New("").Parse(string(src))
+	}
+
+	c.Get([]byte(`{{template "header"}}`), compile, "header.tmpl")
+	c.Get([]byte(`{{template "footer"}}`), compile, "footer.tmpl")
+	c.Get([]byte(`{{template "header"}}{{template "footer"}}`), compile,
+		"header.tmpl", "footer.tmpl")
+
+	c.Changed("header.tmpl")
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len() got=%d after a change, want=1", n)
+	}
+	c.Get([]byte(`{{template "footer"}}`), compile, "footer.tmpl")
+	c.Get([]byte(`{{template "header"}}`), compile, "header.tmpl")
+	if compiles != 4 {
+		t.Errorf("compiles got=%d, want=4", compiles)
+	}
+
+	// Changing an unused input has no effect.
+	c.Changed("unused.tmpl")
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() got=%d, want=2", n)
+	}
+}
@@ -0,0 +1,151 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// WithColdCompression makes a Cache of the same value type compress
+// the values of the items that have not been looked up for a while (at
+// least after, and up to twice as long) in place, with compress, and
+// decompress them with decompress once they are looked up again,
+// trading processor time spent on the cold items for a much larger
+// effective capacity, where limited by WithMaxBytes: the sizes of the
+// compressed items are the lengths of their compressed values, unless
+// set by UpdateCost.
+//
+// The values that compress reports false for, e.g., the ones too small
+// to benefit, are kept as they are, and so are the values borrowed with
+// Acquire, or interned by WithDedup. The cache is not locked while the
+// values are compressed, but it is while they are decompressed, which
+// decompress must do for any value that compress has compressed. The
+// compressed values are stored densely, in blocks shared by many, see
+// WithHotSegment.
+//
+// New panics if the types do not match those of the cache.
+func WithColdCompression[V any](
+	after time.Duration,
+	compress func(value V) ([]byte, bool),
+	decompress func(data []byte) V,
+) Option {
+	return func(o *options) {
+		o.coldAfter = after
+		o.compress = compress
+		o.decompress = decompress
+	}
+}
+
+// Internals.
+
+// A coldValue is compressed data of a value.
+type coldValue[V any] struct {
+	data       []byte
+	decompress func([]byte) V
+}
+
+// A coldCodec compresses and decompresses the values of cold items.
+type coldCodec[V any] struct {
+	compress   func(V) ([]byte, bool)
+	decompress func([]byte) V
+}
+
+// coldCodecOf returns the functions set by WithColdCompression.
+func coldCodecOf[V any](o options) *coldCodec[V] {
+	if o.compress == nil || o.coldAfter <= 0 {
+		return nil
+	}
+	compress, ok := o.compress.(func(V) ([]byte, bool))
+	decompress, ok2 := o.decompress.(func([]byte) V)
+	if !ok || !ok2 {
+		panic("cache: WithColdCompression types do not match those of the cache")
+	}
+	return &coldCodec[V]{compress, decompress}
+}
+
+// compressCold compresses the values of the items that have neither
+// been put, nor looked up since the previous call, and of the ones that
+// overflow the hot segment, if bounded, demoting them to the cold one.
+func (c *Cache[K, V]) compressCold() {
+	type item struct {
+		k    K
+		v    V
+		gen  uint64
+		hits uint32
+	}
+	c.lock()
+	since := c.swept
+	c.swept = time.Now()
+	var items []item
+	overflow := c.overflow()
+	for k, val := range c.d {
+		t := val.t
+		idle := t.hits == t.swept && val.put.Before(since)
+		t.swept = t.hits
+		if !idle && !overflow[t] || val.cold != nil || val.digest != "" ||
+			val.lease != nil && val.lease.n > 0 {
+			continue
+		}
+		items = append(items, item{k, val.v, val.gen, t.hits})
+	}
+	c.m.Unlock()
+
+	data := make([][]byte, len(items))
+	for i, it := range items {
+		if d, ok := c.cold.compress(it.v); ok {
+			data[i] = d
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+	var zero V
+	for i, it := range items {
+		val, found := c.d[it.k]
+		if data[i] == nil || !found || val.gen != it.gen ||
+			val.t.hits != it.hits {
+			// Modified or looked up meanwhile.
+			continue
+		}
+		old := val
+		val.cold = &coldValue[V]{c.coldSeg.store(data[i]), c.cold.decompress}
+		val.v = zero
+		c.restate(it.k, val, old)
+	}
+	if c.coldSeg.fragmented() {
+		c.compactCold()
+	}
+}
+
+// thaw the compressed value of val at key, returning the item with it
+// decompressed. The cache must be locked.
+func (c *Cache[K, V]) thaw(key K, val entry[K, V]) entry[K, V] {
+	old := val
+	val.v = val.Value()
+	val.cold = nil
+	return c.restate(key, val, old)
+}
+
+// restate replaces the item old at key with val, which holds the same
+// value, compressed or decompressed, updating its size, returning it.
+// Unlike a put, it is neither a modification (e.g., for Range, or
+// incremental snapshots), nor a use of the item, and it evicts nothing:
+// a cache that decompressing values takes over WithMaxBytes evicts
+// items on the next put. The cache must be locked.
+func (c *Cache[K, V]) restate(key K, val, old entry[K, V]) entry[K, V] {
+	if val.cold != nil && !val.costSet {
+		val.size = int64(len(val.cold.data))
+	} else if !val.costSet {
+		val.size = c.size(key, val.v)
+	}
+	c.weigh(key, &val, old)
+	c.bytes += val.size - old.size
+	c.uncold(old, val)
+	c.d[key] = val
+	if val.cold != nil {
+		c.cool(val.t)
+	} else {
+		c.heat(val.t)
+	}
+	return val
+}
@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestColdCompression(t *testing.T) {
+	var packed, unpacked int32
+	compress := func(v string) ([]byte, bool) {
+		if len(v) < 100 {
+			return nil, false
+		}
+		atomic.AddInt32(&packed, 1)
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.BestCompression)
+		io.WriteString(w, v)
+		w.Close()
+		return buf.Bytes(), true
+	}
+	decompress := func(data []byte) string {
+		atomic.AddInt32(&unpacked, 1)
+		b, _ := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		return string(b)
+	}
+	c := New[string, string](time.Minute,
+		WithColdCompression(ttl, compress, decompress))
+	defer c.Shutdown()
+
+	big := strings.Repeat("cold ", 1000)
+	c.Put("cold", big)
+	c.Put("hot", big)
+	c.Put("small", "x")
+	before := c.Stats().Bytes
+
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Bytes > before-int64(len(big))/2 && time.Now().Before(deadline) {
+		c.Get("hot")
+		time.Sleep(ttl / 4)
+	}
+	if after := c.Stats().Bytes; after > before-int64(len(big))/2 {
+		t.Fatalf("Stats().Bytes got=%d, want much less than %d", after, before)
+	}
+	if n := atomic.LoadInt32(&packed); n != 1 {
+		t.Errorf("compressed %d values, want 1", n)
+	}
+
+	if v, ok := c.Get("cold"); !ok || v != big {
+		t.Errorf("Get(cold) got=%d bytes,%v, want=%d bytes,true", len(v), ok, len(big))
+	}
+	if n := atomic.LoadInt32(&unpacked); n != 1 {
+		t.Errorf("decompressed %d values, want 1", n)
+	}
+	if s := c.Stats().Bytes; s != before {
+		t.Errorf("Stats().Bytes after decompressing got=%d, want=%d", s, before)
+	}
+	c.Get("cold") // Decompressed in place.
+	if n := atomic.LoadInt32(&unpacked); n != 1 {
+		t.Errorf("decompressed %d values, want 1", n)
+	}
+}
+
+func TestColdCompressionPut(t *testing.T) {
+	c := New[string, string](time.Minute, WithColdCompression(ttl,
+		func(v string) ([]byte, bool) {
+			var buf bytes.Buffer
+			w, _ := flate.NewWriter(&buf, flate.BestCompression)
+			io.WriteString(w, v)
+			w.Close()
+			return buf.Bytes(), true
+		},
+		func(data []byte) string {
+			b, _ := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+			return string(b)
+		},
+	))
+	defer c.Shutdown()
+
+	old := strings.Repeat("old ", 1000)
+	c.Put("a", old)
+	before := c.Stats().Bytes
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Bytes == before && time.Now().Before(deadline) {
+		time.Sleep(ttl / 4)
+	}
+	if c.Stats().Bytes == before {
+		t.Fatal("the value should have been compressed")
+	}
+
+	c.Put("a", "new") // Replaces the compressed value.
+	if v, ok := c.Get("a"); !ok || v != "new" {
+		t.Errorf("Get(a) got=%.10q,%v, want=new,true", v, ok)
+	}
+}
+
+func TestColdCompressionLRU(t *testing.T) {
+	c := New[string, string](time.Minute,
+		WithEvictionPolicy(EvictLRU),
+		WithMaxBytes(16000),
+		WithSizer(func(_ string, v string) int64 { return int64(len(v)) }),
+		WithColdCompression(ttl,
+			func(v string) ([]byte, bool) { return []byte{byte(len(v) / 1000)}, true },
+			func(data []byte) string { return strings.Repeat("x", int(data[0])*1000) },
+		),
+	)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	big := strings.Repeat("x", 5000)
+	c.Put("cold1", big)
+	c.Put("cold2", big)
+	c.Put("hot", big)
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Bytes > 5002 && time.Now().Before(deadline) {
+		c.Get("hot")
+		time.Sleep(ttl / 4)
+	}
+	if n := c.Stats().Bytes; n != 5002 {
+		t.Fatalf("Stats().Bytes got=%d, want=5002 with the cold items compressed", n)
+	}
+	// Neither compressing, nor decompressing for a lookup that does not
+	// count as a use makes the cold items recently used.
+	req.Has("cold1")
+
+	c.Put("new", strings.Repeat("x", 11000))
+	req.HasNot("cold1")
+	req.HasNot("cold2")
+	req.Has("hot")
+	req.Has("new")
+}
@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// A Config declares the settings of a cache, e.g., unmarshaled from a
+// JSON or YAML file, so that operators can tune it without recompiling,
+// see FromConfig.
+type Config struct {
+	// TTL is the cache-default time-to-live, e.g., "5m", parsed by
+	// time.ParseDuration.
+	TTL string `json:"ttl" yaml:"ttl"`
+	// Capacity limits the number of items, see WithCapacity.
+	Capacity int `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+	// MaxBytes limits the total size of the items, see WithMaxBytes.
+	MaxBytes int64 `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+	// Eviction is the eviction policy, "soonest" (by default), "lru",
+	// or "lfu", see WithEvictionPolicy.
+	Eviction string `json:"eviction,omitempty" yaml:"eviction,omitempty"`
+	// NoSliding makes lookups not extend lifetimes, see WithoutSliding.
+	NoSliding bool `json:"noSliding,omitempty" yaml:"noSliding,omitempty"`
+	// Shards is the number of shards of a cache constructed by
+	// ShardedFromConfig.
+	Shards int `json:"shards,omitempty" yaml:"shards,omitempty"`
+
+	// Snapshot configures the snapshots persisting the cache, if its
+	// Path is set, see SnapshotConfig.
+	Snapshot SnapshotConfig `json:"snapshot" yaml:"snapshot"`
+
+	// Name and Labels identify the cache in its metrics and traces, see
+	// WithName and WithLabels.
+	Name   string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// ContentionStats records lock contention in the Stats of the
+	// cache, see WithContentionStats.
+	ContentionStats bool `json:"contentionStats,omitempty" yaml:"contentionStats,omitempty"`
+}
+
+// ErrConfigTTL is returned when constructing a cache from a Config
+// without a TTL.
+var ErrConfigTTL = errors.New("cache: config has no ttl")
+
+// FromConfig returns a new cache configured by cfg, and by opts, which
+// take precedence, or an error if cfg is invalid.
+//
+// If cfg.Snapshot.Path is set, the cache is restored from the snapshots
+// there, if any, returning an error if that fails, and persisted to
+// them until it is shut down.
+func FromConfig[K comparable, V any](cfg Config, opts ...Option) (*Cache[K, V], error) {
+	ttl, o, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	every, err := cfg.Snapshot.interval()
+	if err != nil {
+		return nil, err
+	}
+	c := New[K, V](ttl, append(o, opts...)...)
+	if path := cfg.Snapshot.Path; path != "" {
+		if err := c.restoreFiles(path); err != nil {
+			c.Shutdown()
+			return nil, err
+		}
+		persist(c, cfg.Snapshot, path, every)
+	}
+	return c, nil
+}
+
+// ShardedFromConfig returns a new Sharded cache of cfg.Shards shards,
+// with keys assigned to them by hash, configured, restored, and
+// persisted like FromConfig does.
+func ShardedFromConfig[K comparable, V any](
+	cfg Config,
+	hash func(key K) uint64,
+	opts ...Option,
+) (*Sharded[K, V], error) {
+	ttl, o, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	every, err := cfg.Snapshot.interval()
+	if err != nil {
+		return nil, err
+	}
+	s := NewSharded[K, V](cfg.Shards, hash, ttl, append(o, opts...)...)
+	if path := cfg.Snapshot.Path; path != "" {
+		if err := restoreShards(s, path); err != nil {
+			s.Shutdown()
+			return nil, err
+		}
+		persistShards(s, cfg.Snapshot, path, every)
+	}
+	return s, nil
+}
+
+// FromEnv returns cfg overridden by the environment variables named by
+// prefix, e.g., "SESSIONS_CACHE_", followed by TTL, CAPACITY, MAX_BYTES,
+// EVICTION, NO_SLIDING or SHARDS, if set, for FromConfig, so that
+// deployments can tune a cache without code changes.
+//
+// The values are those of the fields of a Config, in text, with the
+// integers and booleans parsed by the strconv package; an invalid one
+// fails with an error naming its variable, returning cfg unchanged. The
+// others are validated by FromConfig.
+func FromEnv(prefix string, cfg Config) (Config, error) {
+	out := cfg
+	var err error
+	var name string
+	env := func(n string) (string, bool) {
+		if err != nil {
+			return "", false
+		}
+		name = prefix + n
+		return os.LookupEnv(name)
+	}
+	if v, ok := env("TTL"); ok {
+		out.TTL = v
+	}
+	if v, ok := env("EVICTION"); ok {
+		out.Eviction = v
+	}
+	if v, ok := env("CAPACITY"); ok {
+		out.Capacity, err = strconv.Atoi(v)
+	}
+	if v, ok := env("MAX_BYTES"); ok {
+		out.MaxBytes, err = strconv.ParseInt(v, 10, 64)
+	}
+	if v, ok := env("NO_SLIDING"); ok {
+		out.NoSliding, err = strconv.ParseBool(v)
+	}
+	if v, ok := env("SHARDS"); ok {
+		out.Shards, err = strconv.Atoi(v)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("cache: config from environment %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// Internals.
+
+// options returns the default time-to-live and the options declared by
+// cfg.
+func (cfg Config) options() (ttl time.Duration, o []Option, err error) {
+	if cfg.TTL == "" {
+		return 0, nil, ErrConfigTTL
+	}
+	if ttl, err = time.ParseDuration(cfg.TTL); err != nil {
+		return 0, nil, fmt.Errorf("cache: config ttl: %w", err)
+	}
+	p, ok := evictionPolicies[cfg.Eviction]
+	if !ok {
+		return 0, nil, fmt.Errorf("cache: config eviction %q unknown", cfg.Eviction)
+	}
+	o = append(o,
+		WithCapacity(cfg.Capacity),
+		WithMaxBytes(cfg.MaxBytes),
+		WithEvictionPolicy(p),
+	)
+	if cfg.NoSliding {
+		o = append(o, WithoutSliding())
+	}
+	if cfg.Name != "" {
+		o = append(o, WithName(cfg.Name))
+	}
+	if len(cfg.Labels) > 0 {
+		o = append(o, WithLabels(cfg.Labels))
+	}
+	if cfg.ContentionStats {
+		o = append(o, WithContentionStats())
+	}
+	return ttl, o, nil
+}
+
+// evictionPolicies by their names in a Config.
+var evictionPolicies = map[string]EvictionPolicy{
+	"":        EvictSoonest,
+	"soonest": EvictSoonest,
+	"lru":     EvictLRU,
+	"lfu":     EvictLFU,
+}
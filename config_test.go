@@ -0,0 +1,182 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestFromConfig(t *testing.T) {
+	var cfg Config
+	err := json.Unmarshal([]byte(`{
+		"ttl": "1m",
+		"capacity": 2,
+		"eviction": "lru",
+		"name": "sessions",
+		"snapshot": {"compress": true}
+	}`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Snapshot.Compress {
+		t.Error("Snapshot.Compress should be unmarshaled")
+	}
+	c, err := FromConfig[string, int](cfg)
+	if err != nil {
+		t.Fatalf("FromConfig() error: %v", err)
+	}
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.Assert(c.Name() == "sessions", "Name() got=%q, want=sessions", c.Name())
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a")
+	c.Put("c", 3)
+	req.LengthIs(2)
+	req.Has("a")
+	req.HasNot("b")
+	d, _ := c.TTL("a")
+	req.Assert(d > time.Minute-time.Second && d <= time.Minute,
+		"TTL() got=%v, want about a minute", d)
+
+	cfg.Shards = 4
+	s, err := ShardedFromConfig[string, int](cfg, HashString)
+	if err != nil {
+		t.Fatalf("ShardedFromConfig() error: %v", err)
+	}
+	defer s.Shutdown()
+	req.Assert(len(s.Shards()) == 4, "got %d shards, want 4", len(s.Shards()))
+}
+
+func TestFromConfigInvalid(t *testing.T) {
+	if _, err := FromConfig[string, int](Config{}); !errors.Is(err, ErrConfigTTL) {
+		t.Errorf("FromConfig() without TTL got=%v, want=%v", err, ErrConfigTTL)
+	}
+	for _, cfg := range []Config{
+		{TTL: "soon"},
+		{TTL: "1m", Eviction: "random"},
+		{TTL: "1m", Snapshot: SnapshotConfig{Path: "cache"}},
+		{TTL: "1m", Snapshot: SnapshotConfig{Path: "cache", Every: "-1s"}},
+	} {
+		if _, err := FromConfig[string, int](cfg); err == nil {
+			t.Errorf("FromConfig(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestFromConfigSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cfg := Config{TTL: "1m", Snapshot: SnapshotConfig{
+		Path:      path,
+		Every:     ttl.String(),
+		FullEvery: 3,
+	}}
+	c, err := FromConfig[string, int](cfg)
+	if err != nil {
+		t.Fatalf("FromConfig() error: %v", err)
+	}
+	c.Put("a", 1)
+	c.Put("b", 2)
+	time.Sleep(4 * ttl) // A full snapshot, and incremental ones.
+	c.Drop("b")
+	time.Sleep(2 * ttl)
+	if n := c.Stats().Unsaved; n != 0 {
+		t.Errorf("Stats().Unsaved got=%d, want=0", n)
+	}
+	c.Shutdown()
+	time.Sleep(ttl) // For a snapshot in progress.
+
+	c, err = FromConfig[string, int](cfg)
+	if err != nil {
+		t.Fatalf("FromConfig() restoring error: %v", err)
+	}
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	req.Has("a")
+	req.HasNot("b")
+}
+
+func TestShardedFromConfigSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cfg := Config{TTL: "1m", Shards: 4, Snapshot: SnapshotConfig{
+		Path:  path,
+		Every: ttl.String(),
+	}}
+	s, err := ShardedFromConfig[string, int](cfg, HashString)
+	if err != nil {
+		t.Fatalf("ShardedFromConfig() error: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		s.Put(strconv.Itoa(i), i)
+	}
+	time.Sleep(3 * ttl)
+	s.Shutdown()
+	time.Sleep(ttl) // For snapshots in progress.
+
+	// Restored to fewer shards.
+	cfg.Shards = 2
+	s, err = ShardedFromConfig[string, int](cfg, HashString)
+	if err != nil {
+		t.Fatalf("ShardedFromConfig() restoring error: %v", err)
+	}
+	defer s.Shutdown()
+	for i := 0; i < 100; i++ {
+		if v, ok := s.Get(strconv.Itoa(i)); !ok || v != i {
+			t.Fatalf("Get(%d) got=%v,%v, want=%[1]d,true", i, v, ok)
+		}
+	}
+	if _, err := os.Stat(path + "-3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("the snapshot of a shard gone should be removed, got=%v", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TEST_CACHE_TTL", "2m")
+	t.Setenv("TEST_CACHE_CAPACITY", "10")
+	t.Setenv("TEST_CACHE_EVICTION", "lfu")
+	t.Setenv("TEST_CACHE_NO_SLIDING", "true")
+
+	cfg, err := FromEnv("TEST_CACHE_", Config{TTL: "1m", Capacity: 5, Shards: 2})
+	if err != nil {
+		t.Fatalf("FromEnv() error: %v", err)
+	}
+	want := Config{
+		TTL:       "2m",
+		Capacity:  10,
+		Eviction:  "lfu",
+		NoSliding: true,
+		Shards:    2,
+	}
+	if cfg.TTL != want.TTL || cfg.Capacity != want.Capacity ||
+		cfg.Eviction != want.Eviction || cfg.NoSliding != want.NoSliding ||
+		cfg.Shards != want.Shards {
+		t.Errorf("FromEnv() got=%+v, want=%+v", cfg, want)
+	}
+	c, err := FromConfig[string, int](cfg)
+	if err != nil {
+		t.Fatalf("FromConfig() error: %v", err)
+	}
+	c.Shutdown()
+
+	t.Setenv("TEST_CACHE_CAPACITY", "many")
+	cfg, err = FromEnv("TEST_CACHE_", Config{TTL: "1m"})
+	if err == nil || !strings.Contains(err.Error(), "TEST_CACHE_CAPACITY") {
+		t.Errorf("FromEnv() with invalid capacity got=%v, want an error naming TEST_CACHE_CAPACITY", err)
+	}
+	if cfg.TTL != "1m" {
+		t.Errorf("FromEnv() on error got=%+v, want the config given", cfg)
+	}
+}
@@ -0,0 +1,39 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ContentionSampleRate is the number of times a Cache recording lock
+// contention is locked for each one it measures the wait for the lock.
+const ContentionSampleRate = 16
+
+// WithContentionStats makes a Cache measure how long it waits for its
+// lock, once every ContentionSampleRate times it is locked, and record
+// the waits in Stats, e.g., to decide whether a workload would benefit
+// from a Sharded cache, or WithLockFreeReads.
+func WithContentionStats() Option {
+	return func(o *options) {
+		o.contention = true
+	}
+}
+
+// Internals.
+
+// lock the cache, measuring the wait for a sample of the calls, if
+// recording lock contention.
+func (c *Cache[K, V]) lock() {
+	if !c.o.contention ||
+		atomic.AddUint64(&c.locks, 1)%ContentionSampleRate != 0 {
+		c.m.Lock()
+		return
+	}
+	start := time.Now()
+	c.m.Lock()
+	c.stats.lockWait(time.Since(start))
+}
@@ -0,0 +1,60 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithContentionStats(t *testing.T) {
+	c := New[int, int](ttl, WithContentionStats())
+	defer c.Shutdown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10*ContentionSampleRate; j++ {
+				c.Put(j, j)
+			}
+		}()
+	}
+	wg.Wait()
+	s := c.Stats()
+	if s.LockWaits == 0 {
+		t.Fatal("Stats().LockWaits should not be zero")
+	}
+	if got := s.LockWaitDist.Count(); got != s.LockWaits {
+		t.Fatalf("Stats().LockWaitDist.Count(): got=%d, want=%d", got, s.LockWaits)
+	}
+
+	u := New[int, int](ttl)
+	defer u.Shutdown()
+	for j := 0; j < 10*ContentionSampleRate; j++ {
+		u.Put(j, j)
+	}
+	if got := u.Stats().LockWaits; got != 0 {
+		t.Fatalf("Stats().LockWaits without contention stats: got=%d", got)
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	c := New[int, int](time.Minute, WithContentionStats())
+	defer c.Shutdown()
+	c.Put(1, 1)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Get(1)
+		}
+	})
+	s := c.Stats()
+	b.ReportMetric(float64(s.LockWaitDist.Quantile(0.99)), "p99-wait-ns")
+}
@@ -0,0 +1,63 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "context"
+
+// A ContextGetter is a Getter that can also get values with the
+// contexts of the calls that load them, see Context.
+type ContextGetter[K comparable, V any] interface {
+	Getter[K, V]
+	// GetContext gets the value for key with ctx, like Get does.
+	GetContext(ctx context.Context, key K) (value V, ok bool)
+}
+
+var _ ContextGetter[int, any] = (*ContextGetterFunc[int, any])(nil)
+
+// ContextGetterFunc is a func that implements the ContextGetter
+// interface. Get calls it with context.Background().
+type ContextGetterFunc[K comparable, V any] func(
+	ctx context.Context,
+	key K,
+) (value V, ok bool)
+
+func (f ContextGetterFunc[K, V]) Get(key K) (value V, ok bool) {
+	return f(context.Background(), key)
+}
+
+func (f ContextGetterFunc[K, V]) GetContext(
+	ctx context.Context,
+	key K,
+) (value V, ok bool) {
+	return f(ctx, key)
+}
+
+// Context makes the call forward ctx, with the values it holds, e.g.,
+// auth tokens, tenant IDs or trace IDs, to the providers it loads values
+// with, if they are ContextGetters, and to the OnEvict function set by
+// WithOnEvictContext, for the items that leave the cache due to it.
+//
+// Concurrent calls that share the result of a single provider call
+// share the context of the one that made it, too.
+func Context(ctx context.Context) CallOption {
+	return func(o *callOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithOnEvictContext sets a function to call with every item that
+// leaves a Cache of the same key and value types, like WithOnEvict
+// does, along with the context given to the call that caused it to
+// leave (see Context), or context.Background(), if none, e.g., for the
+// items that expire.
+//
+// New panics if the types do not match those of the cache.
+func WithOnEvictContext[K comparable, V any](
+	f func(ctx context.Context, key K, value V, reason EvictReason),
+) Option {
+	return func(o *options) {
+		o.onEvict = f
+	}
+}
@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+type tenantKey struct{}
+
+func TestContextProvider(t *testing.T) {
+	c := New[string, string](time.Minute)
+	defer c.Shutdown()
+	provider := ContextGetterFunc[string, string](
+		func(ctx context.Context, key string) (string, bool) {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return tenant + "/" + key, true
+		})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	if v, _ := c.GetOrPutContext(ctx, "a", provider); v != "acme/a" {
+		t.Errorf("GetOrPutContext(a) got=%q, want=%q", v, "acme/a")
+	}
+	if v, _ := c.GetOrPut("b", provider, Context(ctx)); v != "acme/b" {
+		t.Errorf("GetOrPut(b, Context) got=%q, want=%q", v, "acme/b")
+	}
+	if v, _ := c.GetOrPut("c", provider); v != "/c" {
+		t.Errorf("GetOrPut(c) got=%q, want=%q", v, "/c")
+	}
+}
+
+func TestOnEvictContext(t *testing.T) {
+	got := map[string]string{}
+	c := New[string, int](time.Minute,
+		WithCapacity(1),
+		WithOnEvictContext(func(ctx context.Context, k string, _ int, _ EvictReason) {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			got[k] = tenant
+		}),
+	)
+	defer c.Shutdown()
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	c.Put("a", 1)
+	c.PutContext(ctx, "b", 2) // Evicts a.
+	c.Put("c", 3)             // Evicts b.
+	c.PutWithTTL("d", 4, ttl) // Evicts c.
+	time.Sleep(2 * ttl)
+	c.GetContext(ctx, "d") // Expired meanwhile.
+
+	want := map[string]string{"a": "acme", "b": "", "c": ""}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("eviction of %s with tenant %q, want %q", k, got[k], w)
+		}
+	}
+	if _, ok := got["d"]; !ok {
+		t.Error("should have evicted d")
+	}
+}
+
+func TestWithOnEvictContextTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("New() should panic on mismatched types")
+		}
+	}()
+	New[string, int](time.Minute, WithOnEvictContext(
+		func(context.Context, int, int, EvictReason) {}))
+}
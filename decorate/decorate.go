@@ -0,0 +1,262 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package decorate implements decorators adding cross-cutting behavior
+// to any cache.Interface, which can be stacked, e.g.:
+//
+//	c := decorate.WithLogging(decorate.WithSingleflight(remote), nil)
+package decorate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// Metrics of the operations on a cache.
+type Metrics struct {
+	Hits   uint64 // Number of Gets that found an item.
+	Misses uint64 // Number of Gets that did not find an item.
+	Puts   uint64 // Number of values put.
+	Drops  uint64 // Number of items dropped.
+	Errors uint64 // Number of puts and drops that failed.
+}
+
+// A MeteredCache counts the operations on the cache it wraps.
+type MeteredCache[K comparable, V any] struct {
+	cache.Interface[K, V]
+	m Metrics // Accessed atomically.
+}
+
+// WithMetrics returns c counting the operations on it.
+func WithMetrics[K comparable, V any](c cache.Interface[K, V]) *MeteredCache[K, V] {
+	return &MeteredCache[K, V]{Interface: c}
+}
+
+// Get the value of a cached item.
+func (c *MeteredCache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok = c.Interface.Get(key)
+	if ok {
+		atomic.AddUint64(&c.m.Hits, 1)
+	} else {
+		atomic.AddUint64(&c.m.Misses, 1)
+	}
+	return
+}
+
+// PutWithTTLE puts a value in the cache with the given time-to-live.
+func (c *MeteredCache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	return c.count(&c.m.Puts, c.Interface.PutWithTTLE(key, value, ttl))
+}
+
+// DropE drops the item at key.
+func (c *MeteredCache[K, V]) DropE(key K) error {
+	return c.count(&c.m.Drops, c.Interface.DropE(key))
+}
+
+// Metrics returns the counts of the operations so far.
+func (c *MeteredCache[K, V]) Metrics() Metrics {
+	return Metrics{
+		Hits:   atomic.LoadUint64(&c.m.Hits),
+		Misses: atomic.LoadUint64(&c.m.Misses),
+		Puts:   atomic.LoadUint64(&c.m.Puts),
+		Drops:  atomic.LoadUint64(&c.m.Drops),
+		Errors: atomic.LoadUint64(&c.m.Errors),
+	}
+}
+
+// count an operation in n, unless it failed with err, and return err.
+func (c *MeteredCache[K, V]) count(n *uint64, err error) error {
+	if err != nil {
+		atomic.AddUint64(&c.m.Errors, 1)
+	} else {
+		atomic.AddUint64(n, 1)
+	}
+	return err
+}
+
+// A LoggingCache logs the operations on the cache it wraps.
+type LoggingCache[K comparable, V any] struct {
+	cache.Interface[K, V]
+	l *log.Logger
+	p string // Prefix of the messages.
+}
+
+// WithLogging returns c logging the operations on it to l, or to the
+// standard logger, if nil. The values are not logged. The messages name
+// the cache, if it has a name, see cache.WithName.
+func WithLogging[K comparable, V any](
+	c cache.Interface[K, V],
+	l *log.Logger,
+) *LoggingCache[K, V] {
+	if l == nil {
+		l = log.Default()
+	}
+	p := "cache"
+	if n, ok := c.(interface{ Name() string }); ok && n.Name() != "" {
+		p += " " + n.Name()
+	}
+	return &LoggingCache[K, V]{Interface: c, l: l, p: p}
+}
+
+// Get the value of a cached item.
+func (c *LoggingCache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok = c.Interface.Get(key)
+	c.l.Printf("%s: get %v: found=%v", c.p, key, ok)
+	return
+}
+
+// PutWithTTLE puts a value in the cache with the given time-to-live.
+func (c *LoggingCache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	err := c.Interface.PutWithTTLE(key, value, ttl)
+	c.l.Printf("%s: put %v for %v: err=%v", c.p, key, ttl, err)
+	return err
+}
+
+// DropE drops the item at key.
+func (c *LoggingCache[K, V]) DropE(key K) error {
+	err := c.Interface.DropE(key)
+	c.l.Printf("%s: drop %v: err=%v", c.p, key, err)
+	return err
+}
+
+// A SingleflightCache shares the result of a single Get among the
+// concurrent ones for the same key, e.g., to spare a remote cache.
+type SingleflightCache[K comparable, V any] struct {
+	cache.Interface[K, V]
+	m     sync.Mutex
+	calls map[K]*call[V]
+}
+
+// WithSingleflight returns c sharing the results of concurrent Gets.
+func WithSingleflight[K comparable, V any](c cache.Interface[K, V]) *SingleflightCache[K, V] {
+	return &SingleflightCache[K, V]{
+		Interface: c,
+		calls:     make(map[K]*call[V]),
+	}
+}
+
+// Get the value of a cached item.
+func (c *SingleflightCache[K, V]) Get(key K) (value V, ok bool) {
+	c.m.Lock()
+	if cl, found := c.calls[key]; found {
+		c.m.Unlock()
+		<-cl.done
+		return cl.v, cl.ok
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.m.Unlock()
+
+	defer func() {
+		c.m.Lock()
+		delete(c.calls, key)
+		c.m.Unlock()
+		close(cl.done)
+	}()
+	cl.v, cl.ok = c.Interface.Get(key)
+	return cl.v, cl.ok
+}
+
+// ErrKeyTooLong is returned when putting a value at a key longer than
+// the limit of a KeyLimitCache that rejects such keys.
+var ErrKeyTooLong = errors.New("decorate: key too long")
+
+// A KeyPolicy determines what a KeyLimitCache does with keys longer than
+// its limit.
+type KeyPolicy int
+
+const (
+	// RejectLongKeys makes puts at long keys fail with ErrKeyTooLong,
+	// and lookups of them miss.
+	RejectLongKeys KeyPolicy = iota
+	// HashLongKeys truncates long keys, replacing their tails with a
+	// hash of the whole key, so that distinct long keys still (almost
+	// certainly) map to distinct items.
+	HashLongKeys
+)
+
+// A KeyLimitCache limits the lengths of the keys in the cache it wraps,
+// e.g., to protect its memory from request bodies accidentally used as
+// keys.
+type KeyLimitCache[K ~string, V any] struct {
+	cache.Interface[K, V]
+	max    int
+	policy KeyPolicy
+}
+
+// WithKeyLimit returns c limiting its keys to max bytes, handling longer
+// ones according to policy. Keys are never truncated to less than the
+// length of the hash, 64 bytes, under HashLongKeys.
+func WithKeyLimit[K ~string, V any](
+	c cache.Interface[K, V],
+	max int,
+	policy KeyPolicy,
+) *KeyLimitCache[K, V] {
+	return &KeyLimitCache[K, V]{Interface: c, max: max, policy: policy}
+}
+
+// Get the value of a cached item.
+func (c *KeyLimitCache[K, V]) Get(key K) (value V, ok bool) {
+	if key, ok = c.key(key); !ok {
+		return
+	}
+	return c.Interface.Get(key)
+}
+
+// Has returns whether an item for given key is present in the cache.
+func (c *KeyLimitCache[K, V]) Has(key K) bool {
+	key, ok := c.key(key)
+	return ok && c.Interface.Has(key)
+}
+
+// PutWithTTLE puts a value in the cache with the given time-to-live.
+func (c *KeyLimitCache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	key, ok := c.key(key)
+	if !ok {
+		return ErrKeyTooLong
+	}
+	return c.Interface.PutWithTTLE(key, value, ttl)
+}
+
+// DropE drops the item at key.
+func (c *KeyLimitCache[K, V]) DropE(key K) error {
+	key, ok := c.key(key)
+	if !ok {
+		return nil
+	}
+	return c.Interface.DropE(key)
+}
+
+// Internals.
+
+// key returns the key in the wrapped cache for key, and false if key is
+// rejected.
+func (c *KeyLimitCache[K, V]) key(key K) (K, bool) {
+	if len(key) <= c.max {
+		return key, true
+	}
+	if c.policy != HashLongKeys {
+		return key, false
+	}
+	sum := sha256.Sum256([]byte(key))
+	h := hex.EncodeToString(sum[:])
+	if n := c.max - len(h); n > 0 {
+		return key[:n] + K(h), true
+	}
+	return K(h), true
+}
+
+// A call to Get, shared by the concurrent ones for the same key.
+type call[V any] struct {
+	done chan struct{} // Closed when the call completes.
+	v    V
+	ok   bool
+}
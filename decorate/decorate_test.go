@@ -0,0 +1,162 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package decorate_test
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/decorate"
+)
+
+func TestWithMetrics(t *testing.T) {
+	c := WithMetrics[string, int](cache.NullCache[string, int]{})
+	c.PutWithTTLE("a", 1, time.Minute)
+	c.Get("a")
+	c.DropE("a")
+	if err := cache.ReadOnly[string, int](c).PutWithTTLE("a", 1, 0); err == nil {
+		t.Fatal("ReadOnly PutWithTTLE() should fail")
+	}
+	c2 := WithMetrics[string, int](cache.ReadOnly[string, int](c))
+	c2.PutWithTTLE("a", 1, time.Minute)
+
+	want := Metrics{Misses: 1, Puts: 1, Drops: 1}
+	if got := c.Metrics(); got != want {
+		t.Errorf("Metrics() got=%+v, want=%+v", got, want)
+	}
+	if got := c2.Metrics(); got.Errors != 1 {
+		t.Errorf("Metrics() of failing got=%+v, want 1 error", got)
+	}
+}
+
+func TestWithLogging(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	var buf bytes.Buffer
+	l := WithLogging[string, int](c, log.New(&buf, "", 0))
+
+	l.PutWithTTLE("a", 1, time.Minute)
+	l.Get("a")
+	l.DropE("a")
+	want := "cache: put a for 1m0s: err=<nil>\n" +
+		"cache: get a: found=true\n" +
+		"cache: drop a: err=<nil>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log got=%q, want=%q", got, want)
+	}
+}
+
+func TestWithLoggingNamed(t *testing.T) {
+	c := cache.New[string, int](time.Minute, cache.WithName("sessions"))
+	defer c.Shutdown()
+	var buf bytes.Buffer
+	WithLogging[string, int](c, log.New(&buf, "", 0)).Get("a")
+	want := "cache sessions: get a: found=false\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log got=%q, want=%q", got, want)
+	}
+}
+
+func TestWithSingleflight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	c := WithSingleflight[string, int](slowCache{
+		Interface: cache.NullCache[string, int]{},
+		get: func() {
+			atomic.AddInt32(&calls, 1)
+			<-release
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("a")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("calls got=%d, want=1", calls)
+	}
+}
+
+func TestStacked(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	var buf bytes.Buffer
+	m := WithMetrics[string, int](c)
+	s := WithLogging[string, int](WithSingleflight[string, int](m), log.New(&buf, "", 0))
+
+	s.PutWithTTLE("a", 1, time.Minute)
+	if v, ok := s.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) got=%v,%v, want=1,true", v, ok)
+	}
+	if !s.Has("a") {
+		t.Error("should have 'a'")
+	}
+	if got := m.Metrics(); got.Hits != 1 || got.Puts != 1 {
+		t.Errorf("Metrics() got=%+v", got)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("log lines got=%d, want=2", got)
+	}
+}
+
+// slowCache calls get on every Get.
+type slowCache struct {
+	cache.Interface[string, int]
+	get func()
+}
+
+func (c slowCache) Get(key string) (int, bool) {
+	c.get()
+	return c.Interface.Get(key)
+}
+
+func TestWithKeyLimit(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	defer c.Shutdown()
+	long := strings.Repeat("a", 100)
+
+	r := WithKeyLimit[string, int](c, 80, RejectLongKeys)
+	if err := r.PutWithTTLE(long, 1, time.Minute); err != ErrKeyTooLong {
+		t.Fatalf("PutWithTTLE(long key) got=%v, want=%v", err, ErrKeyTooLong)
+	}
+	if r.Has(long) || c.Length() != 0 {
+		t.Fatal("long key should have been rejected")
+	}
+	if err := r.PutWithTTLE("a", 1, time.Minute); err != nil || !r.Has("a") {
+		t.Fatalf("PutWithTTLE(short key) got=%v, want it put", err)
+	}
+
+	h := WithKeyLimit[string, int](c, 80, HashLongKeys)
+	h.PutWithTTLE(long, 2, time.Minute)
+	h.PutWithTTLE(long+"b", 3, time.Minute)
+	if v, ok := h.Get(long); !ok || v != 2 {
+		t.Errorf("Get(long key) got=%v,%v, want=2,true", v, ok)
+	}
+	if v, ok := h.Get(long + "b"); !ok || v != 3 {
+		t.Errorf("Get(other long key) got=%v,%v, want=3,true", v, ok)
+	}
+	for _, k := range c.Keys() {
+		if len(k) > 80 {
+			t.Errorf("key of %d bytes in cache, want at most 80", len(k))
+		}
+	}
+	h.DropE(long)
+	if h.Has(long) {
+		t.Error("long key should have been dropped")
+	}
+}
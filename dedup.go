@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// WithDedup makes a Cache of the same value type store a single copy
+// of identical values put at different keys, e.g., default avatars or
+// shared configurations, by interning them by the digests of their
+// contents, e.g., the SHA-256 sums of their bytes. Values with equal
+// digests are considered identical, and the ones put later are replaced
+// with the copy interned first, which is kept while any item holds it.
+//
+// Only values that share memory when copied (e.g., slices, maps and
+// pointers) are stored once. The sizes of the items are not affected.
+//
+// New panics if the types do not match those of the cache.
+func WithDedup[V any](digest func(value V) string) Option {
+	return func(o *options) {
+		o.digest = digest
+	}
+}
+
+// Internals.
+
+// An interned value, held by n items.
+type interned[V any] struct {
+	v V
+	n int
+}
+
+// digestOf returns the function set by WithDedup.
+func digestOf[V any](o options) func(V) string {
+	if o.digest == nil {
+		return nil
+	}
+	f, ok := o.digest.(func(V) string)
+	if !ok {
+		panic("cache: WithDedup types do not match those of the cache")
+	}
+	return f
+}
+
+// dedup replaces the value of val with the identical one interned, if
+// any, or interns it, releasing the one it held before, if deduplicating.
+// The cache must be locked.
+func (c *Cache[K, V]) dedup(val *entry[K, V]) {
+	if c.digest == nil {
+		return
+	}
+	c.unintern(val.digest)
+	d := c.digest(val.v)
+	in, found := c.interned[d]
+	if !found {
+		if c.interned == nil {
+			c.interned = make(map[string]*interned[V])
+		}
+		in = &interned[V]{v: val.v}
+		c.interned[d] = in
+	}
+	in.n++
+	val.v = in.v
+	val.digest = d
+}
+
+// unintern releases a hold of the value interned by digest d,
+// forgetting it once no item holds it.
+func (c *Cache[K, V]) unintern(d string) {
+	in, found := c.interned[d]
+	if !found {
+		return
+	}
+	if in.n--; in.n == 0 {
+		delete(c.interned, d)
+	}
+}
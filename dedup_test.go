@@ -0,0 +1,66 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestDedup(t *testing.T) {
+	digest := func(v []byte) string {
+		s := sha256.Sum256(v)
+		return string(s[:])
+	}
+	c := New[string, []byte](time.Minute, WithDedup(digest))
+	defer c.Shutdown()
+	same := func(a, b string) bool {
+		va, _ := c.Get(a)
+		vb, _ := c.Get(b)
+		return &va[0] == &vb[0]
+	}
+
+	c.Put("a", []byte("avatar"))
+	c.Put("b", []byte("avatar"))
+	c.GetOrPut("c", SimpleGetterFunc[string, []byte](func() []byte {
+		return []byte("avatar")
+	}))
+	if !same("a", "b") || !same("a", "c") {
+		t.Error("identical values should be stored once")
+	}
+	c.Put("d", []byte("other"))
+	if same("a", "d") {
+		t.Error("different values should not be shared")
+	}
+
+	// The interned copy outlives the item it was put at first.
+	c.Drop("a")
+	c.Put("e", []byte("avatar"))
+	if !same("b", "e") {
+		t.Error("identical values should still be stored once")
+	}
+
+	// Once no item holds it, a new copy is interned.
+	c.Drop("b")
+	c.Drop("c")
+	old, _ := c.Get("e")
+	c.Put("e", []byte("replaced"))
+	c.Put("f", []byte("avatar"))
+	if v, _ := c.Get("f"); &v[0] == &old[0] {
+		t.Error("a value no longer held should not be interned")
+	}
+}
+
+func TestWithDedupTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("New() should panic on mismatched types")
+		}
+	}()
+	New[string, int](time.Minute, WithDedup(func(string) string { return "" }))
+}
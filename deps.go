@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// DependOn makes the cached item at key depend on the ones at deps, so
+// that it is dropped, with ReasonDependency, as soon as any of them
+// leaves the cache, or has a new value put, e.g., for an artifact
+// derived from them. Returns false, without recording any dependency,
+// if any of the keys has not been found in the cache.
+//
+// Dependencies are transitive, and they stay with the item until it is
+// dropped, putting a new value at the key does not remove them.
+func (c *Cache[K, V]) DependOn(key K, deps ...K) bool {
+	c.lock()
+	defer c.unlock()
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	for _, d := range deps {
+		if _, found := c.d[d]; !found {
+			return false
+		}
+	}
+	c.depend(key, val, deps)
+	return true
+}
+
+// PutLinked puts a value in cache at key, linked to the item at anchor,
+// so that it lives exactly as long as that: it neither expires, nor
+// gets evicted, on its own, but is dropped, with ReasonDependency, as
+// soon as the anchor leaves the cache, or has a new value put, e.g.,
+// for data derived from a session. Returns false, without putting the
+// value, if the anchor has not been found in the cache, or is key, or
+// has been evicted to make room for the value.
+func (c *Cache[K, V]) PutLinked(key K, value V, anchor K) bool {
+	if key == anchor || !c.keyOK(key) {
+		return false
+	}
+	c.lock()
+	defer c.unlock()
+	if _, found := c.d[anchor]; !found {
+		return false
+	}
+	if c.set(key, value, callOptions{pin: true}) != nil {
+		return false
+	}
+	val, found := c.d[key]
+	if _, ok := c.d[anchor]; !ok {
+		if found {
+			c.drop(key, val, ReasonDependency)
+		}
+		return false
+	}
+	c.depend(key, val, []K{anchor})
+	return true
+}
+
+// Internals.
+
+// depend the item val at key on deps. The cache must be locked.
+func (c *Cache[K, V]) depend(key K, val entry[K, V], deps []K) {
+	for _, d := range deps {
+		if _, ok := c.deps[d][key]; ok || d == key {
+			continue
+		}
+		if c.deps == nil {
+			c.deps = make(map[K]map[K]struct{})
+		}
+		if c.deps[d] == nil {
+			c.deps[d] = make(map[K]struct{})
+		}
+		c.deps[d][key] = struct{}{}
+		val.deps = append(val.deps, d)
+	}
+	c.store(key, val)
+}
+
+// undepend key on deps.
+func (c *Cache[K, V]) undepend(key K, deps []K) {
+	for _, d := range deps {
+		delete(c.deps[d], key)
+		if len(c.deps[d]) == 0 {
+			delete(c.deps, d)
+		}
+	}
+}
+
+// dropDependents of key.
+func (c *Cache[K, V]) dropDependents(key K) {
+	dependents := c.deps[key]
+	delete(c.deps, key)
+	for k := range dependents {
+		if val, found := c.d[k]; found {
+			c.drop(k, val, ReasonDependency)
+		}
+	}
+}
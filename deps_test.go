@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestDependOn(t *testing.T) {
+	var m sync.Mutex
+	reasons := map[string]EvictReason{}
+	reason := func(k string) EvictReason {
+		m.Lock()
+		defer m.Unlock()
+		return reasons[k]
+	}
+	c := New[string, int](time.Minute, WithOnEvict(func(k string, _ int, r EvictReason) {
+		m.Lock()
+		defer m.Unlock()
+		reasons[k] = r
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("src", 1)
+	c.Put("lib", 2)
+	c.Put("obj", 3)
+	c.Put("bin", 4)
+	req.AssertNot(c.DependOn("obj", "src", "missing"), "should not depend on an absent item")
+	req.AssertNot(c.DependOn("missing", "src"), "should not make an absent item depend")
+	req.Assert(c.DependOn("obj", "src", "lib"), "should make 'obj' depend")
+	req.Assert(c.DependOn("bin", "obj"), "should make 'bin' depend")
+	req.Assert(c.DependOn("src", "bin"), "cycles should be allowed")
+
+	// Touching or tagging a dependency does not change it.
+	c.Touch("lib")
+	c.Tag("lib", "t")
+	req.Has("obj")
+
+	// Putting a new value invalidates the dependents transitively.
+	c.Put("lib", 20)
+	req.HasNot("obj")
+	req.HasNot("bin")
+	req.HasNot("src")
+	req.Has("lib")
+	for _, k := range []string{"obj", "bin", "src"} {
+		req.Assert(reason(k) == ReasonDependency, "reason for %q got=%v", k, reason(k))
+	}
+
+	// Dependencies go away with the items.
+	c.Put("obj", 3)
+	c.DependOn("obj", "lib")
+	c.PutWithTTL("tmp", 5, ttl)
+	c.DependOn("tmp", "lib")
+	c.Drop("obj")
+	time.Sleep(3 * ttl / 2)
+	req.Assert(reason("tmp") == ReasonExpired, "reason for tmp got=%v", reason("tmp"))
+	c.Put("obj", 3)
+	c.Put("lib", 2)
+	req.Has("obj")
+}
+
+func TestPutLinked(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.AssertNot(c.PutLinked("data", 1, "session"), "should not link to a missing anchor")
+	req.HasNot("data")
+
+	c.PutWithTTL("session", 0, 2*ttl)
+	req.Assert(c.PutLinked("data", 1, "session"), "should link to the session")
+	req.AssertNot(c.PutLinked("session", 1, "session"), "should not link to itself")
+	time.Sleep(ttl)
+	req.Has("data")
+	time.Sleep(3 * ttl)
+	req.HasNot("session")
+	req.HasNot("data")
+
+	c.Put("session", 0)
+	c.PutLinked("data", 2, "session")
+	c.Drop("session")
+	req.HasNot("data")
+}
+
+func TestPutLinkedEvictsAnchor(t *testing.T) {
+	c := New[string, int](time.Minute, WithCapacity(1))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("session", 0)
+	req.AssertNot(c.PutLinked("data", 1, "session"),
+		"should not link to an anchor evicted to make room")
+	req.HasNot("session")
+	req.HasNot("data")
+	req.LengthIs(0)
+}
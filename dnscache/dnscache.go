@@ -0,0 +1,136 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package dnscache caches DNS lookups.
+package dnscache
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// A LookupFunc looks up the addresses of host, and returns how long
+// they may be cached for, taken from the records, or zero if unknown.
+type LookupFunc func(ctx context.Context, host string) (
+	addrs []net.IPAddr,
+	ttl time.Duration,
+	err error,
+)
+
+// NetLookup returns a LookupFunc using r, or net.DefaultResolver, if
+// nil. It does not know the TTLs of the records.
+func NetLookup(r *net.Resolver) LookupFunc {
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	return func(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+		addrs, err := r.LookupIPAddr(ctx, host)
+		return addrs, 0, err
+	}
+}
+
+// A Resolver caches the addresses of hosts, for the TTLs of their
+// records, or a default one, if unknown.
+type Resolver struct {
+	c      *cache.Cache[string, record]
+	lookup LookupFunc
+	ttl    time.Duration
+
+	// Dialer used by DialContext; a zero net.Dialer, if nil.
+	Dialer *net.Dialer
+}
+
+// New Resolver caching the lookups of lookup, or NetLookup(nil), if
+// nil, for defaultTTL if the TTL of the records is unknown.
+func New(lookup LookupFunc, defaultTTL time.Duration) *Resolver {
+	if lookup == nil {
+		lookup = NetLookup(nil)
+	}
+	return &Resolver{
+		c:      cache.New[string, record](defaultTTL),
+		lookup: lookup,
+		ttl:    defaultTTL,
+	}
+}
+
+// LookupIPAddr returns the addresses of host. Failed lookups are not
+// cached.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	var err error
+	rec, ok := r.c.GetOrPut(host, cache.GetterFunc[string, record](
+		func(host string) (rec record, ok bool) {
+			rec.addrs, rec.ttl, err = r.lookup(ctx, host)
+			if rec.ttl <= 0 {
+				rec.ttl = r.ttl
+			}
+			return rec, err == nil
+		},
+	), cache.NoSlide())
+	if !ok {
+		if err == nil {
+			// The lookup failed in a concurrent call.
+			err = &net.DNSError{Err: "lookup failed", Name: host}
+		}
+		return nil, err
+	}
+	return rec.addrs, nil
+}
+
+// DialContext connects to the address on the named network, like
+// net.Dialer.DialContext does, using the cached addresses of the host,
+// trying them in order until one succeeds. It can be used as that of
+// an http.Transport.
+func (r *Resolver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d := r.Dialer
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.DialContext(ctx, network, address)
+	}
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range addrs {
+		var conn net.Conn
+		conn, err = d.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	if err == nil {
+		err = &net.DNSError{Err: "no addresses", Name: host}
+	}
+	return nil, err
+}
+
+// Forget the cached addresses of host.
+func (r *Resolver) Forget(host string) {
+	r.c.Drop(host)
+}
+
+// Shutdown the underlying cache.
+func (r *Resolver) Shutdown() {
+	r.c.Shutdown()
+}
+
+// Internals.
+
+// A record of the addresses of a host.
+type record struct {
+	addrs []net.IPAddr
+	ttl   time.Duration
+}
+
+func (r record) TTL() time.Duration {
+	return r.ttl
+}
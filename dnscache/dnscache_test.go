@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dnscache_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache/dnscache"
+)
+
+const ttl = 20 * time.Millisecond
+
+func TestLookupIPAddr(t *testing.T) {
+	lookups := map[string]int{}
+	r := New(func(_ context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+		lookups[host]++
+		switch host {
+		case "short.test":
+			return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, ttl, nil
+		case "long.test":
+			return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 2)}}, 0, nil
+		}
+		return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}, time.Minute)
+	defer r.Shutdown()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		for _, host := range []string{"short.test", "long.test"} {
+			addrs, err := r.LookupIPAddr(ctx, host)
+			if err != nil || len(addrs) != 1 {
+				t.Fatalf("LookupIPAddr(%s) got=%v, %v", host, addrs, err)
+			}
+		}
+		var dnsErr *net.DNSError
+		if _, err := r.LookupIPAddr(ctx, "missing.test"); !errors.As(err, &dnsErr) {
+			t.Errorf("LookupIPAddr(missing.test) error got=%v, want a DNSError", err)
+		}
+	}
+	time.Sleep(3 * ttl / 2)
+	r.LookupIPAddr(ctx, "short.test")
+	r.LookupIPAddr(ctx, "long.test")
+
+	want := map[string]int{"short.test": 2, "long.test": 1, "missing.test": 2}
+	for host, n := range want {
+		if lookups[host] != n {
+			t.Errorf("lookups of %s got=%d, want=%d", host, lookups[host], n)
+		}
+	}
+
+	r.Forget("long.test")
+	r.LookupIPAddr(ctx, "long.test")
+	if lookups["long.test"] != 2 {
+		t.Errorf("lookups of long.test got=%d after Forget, want=2", lookups["long.test"])
+	}
+}
+
+func TestDialContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	r := New(func(context.Context, string) ([]net.IPAddr, time.Duration, error) {
+		return []net.IPAddr{
+			{IP: net.IPv4(127, 0, 0, 1)},
+		}, 0, nil
+	}, time.Minute)
+	defer r.Shutdown()
+
+	conn, err := r.DialContext(context.Background(), "tcp", net.JoinHostPort("service.test", port))
+	if err != nil {
+		t.Fatalf("DialContext() error: %v", err)
+	}
+	conn.Close()
+	if _, err := r.DialContext(context.Background(), "tcp", "service.test"); err == nil {
+		t.Error("DialContext() without a port should fail")
+	}
+}
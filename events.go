@@ -0,0 +1,118 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// An EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	// EventPut is of a value put in the cache, by any method, including
+	// loads and refreshes.
+	EventPut EventKind = iota
+	// EventEvict is of an item leaving the cache, for any reason, see
+	// WithOnEvict.
+	EventEvict
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventPut:
+		return "put"
+	case EventEvict:
+		return "evict"
+	}
+	return "unknown"
+}
+
+// An Event in a Cache, see Subscribe.
+type Event[K comparable, V any] struct {
+	Kind   EventKind
+	Key    K
+	Value  V
+	Reason EvictReason // Of EventEvict.
+	Time   time.Time
+}
+
+// WithEventReplay makes a Cache keep its last n events, which Subscribe
+// replays to new subscribers, so that the ones that start late, e.g., a
+// metrics exporter started after warmup, can catch up on them.
+func WithEventReplay(n int) Option {
+	return func(o *options) {
+		o.replay = n
+	}
+}
+
+// Subscribe returns a channel of the events in the cache, which holds
+// up to size of them, and a function to cancel the subscription, which
+// closes the channel. The events kept for replay, if any, see
+// WithEventReplay, come first, and the channel holds at least as many.
+//
+// Events are sent while the cache is locked, so the ones that do not
+// fit in the channel of a subscriber that does not keep up are dropped,
+// instead of blocking the cache.
+func (c *Cache[K, V]) Subscribe(size int) (
+	events <-chan Event[K, V],
+	cancel func(),
+) {
+	c.lock()
+	defer c.m.Unlock()
+	if n := len(c.replay); size < n {
+		size = n
+	}
+	ch := make(chan Event[K, V], size)
+	for i := range c.replay {
+		ch <- c.replay[(c.replayAt+i)%len(c.replay)]
+	}
+	if c.subs == nil {
+		c.subs = make(map[chan Event[K, V]]struct{})
+	}
+	c.subs[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			c.lock()
+			defer c.m.Unlock()
+			delete(c.subs, ch)
+			close(ch)
+		})
+	}
+}
+
+// Internals.
+
+// emits returns whether the cache has any use for events.
+func (c *Cache[K, V]) emits() bool {
+	return len(c.subs) > 0 || c.o.replay > 0
+}
+
+// emit an event of kind at key with value v, and the reason, if it is
+// EventEvict, to the subscribers, keeping it for replay, if enabled.
+// The cache must be locked.
+func (c *Cache[K, V]) emit(kind EventKind, key K, v V, reason EvictReason) {
+	if !c.emits() {
+		return
+	}
+	e := Event[K, V]{kind, key, v, reason, time.Now()}
+	if c.o.replay > 0 {
+		if len(c.replay) < c.o.replay {
+			c.replay = append(c.replay, e)
+		} else {
+			c.replay[c.replayAt] = e
+			c.replayAt = (c.replayAt + 1) % len(c.replay)
+		}
+	}
+	for ch := range c.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
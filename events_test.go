@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestSubscribe(t *testing.T) {
+	c := New[string, int](time.Minute, WithEventReplay(2), WithCapacity(2))
+	defer c.Shutdown()
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3) // Evicts a.
+	events, cancel := c.Subscribe(3)
+	c.Drop("b")
+	c.GetOrPut("d", SimpleGetterFunc[string, int](func() int { return 4 }))
+
+	type event struct {
+		kind   EventKind
+		key    string
+		value  int
+		reason EvictReason
+	}
+	want := []event{
+		// The last two replayed.
+		{EventPut, "c", 3, 0},
+		{EventEvict, "a", 1, ReasonEvicted},
+		// Live, the rest dropped, for lack of room.
+		{EventEvict, "b", 2, ReasonDropped},
+	}
+	cancel()
+	var got []event
+	for e := range events {
+		if e.Time.IsZero() {
+			t.Errorf("event %v has no time", e)
+		}
+		got = append(got, event{e.Kind, e.Key, e.Value, e.Reason})
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events got=%v, want=%v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d got=%v, want=%v", i, got[i], want[i])
+		}
+	}
+	cancel() // Idempotent.
+}
+
+func TestSubscribeLive(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	events, cancel := c.Subscribe(4)
+	defer cancel()
+
+	c.Put("b", 2)
+	select {
+	case e := <-events:
+		if e.Kind != EventPut || e.Key != "b" || e.Value != 2 {
+			t.Errorf("event got=%+v, want put b", e)
+		}
+	default:
+		t.Fatal("should have received an event")
+	}
+	select {
+	case e := <-events:
+		t.Errorf("should not have replayed %+v", e)
+	default:
+	}
+}
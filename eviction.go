@@ -0,0 +1,176 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "container/heap"
+
+// An EvictionPolicy selects the items that are evicted from a Cache
+// while it exceeds its limits, see WithCapacity and WithMaxBytes.
+type EvictionPolicy int
+
+// The eviction policies.
+const (
+	// EvictSoonest evicts the items closest to expiry first, which,
+	// for items with sliding expiry and the same time-to-live, are the
+	// least recently used ones.
+	EvictSoonest EvictionPolicy = iota
+	// EvictLRU evicts the least recently used items first, regardless
+	// of their expiry, e.g., for items with long times-to-live. Items
+	// are used by putting them, and by the lookups that find them,
+	// except for the ones that do not extend lifetimes (e.g., Has, or
+	// ones with the NoSlide or DoNotPromote options).
+	EvictLRU
+	// EvictLFU evicts the least frequently used items first, those
+	// found by the fewest lookups since they were put, and the soonest
+	// to expire among them, e.g., for workloads with popularity skewed
+	// strongly enough that bursts of lookups of other items would
+	// displace the popular ones under EvictLRU. An item that has just
+	// been put is not evicted to make room for itself.
+	EvictLFU
+)
+
+// WithEvictionPolicy sets the policy selecting the items evicted from a
+// Cache while it exceeds its limits; EvictSoonest, by default. Pinned
+// items are never evicted.
+func WithEvictionPolicy(p EvictionPolicy) Option {
+	return func(o *options) {
+		o.eviction = p
+	}
+}
+
+// Internals.
+
+// victim returns the timer of the item that the eviction policy selects
+// next among the ones that match reports true for, or nil if none does.
+func (c *Cache[K, V]) victim(match func(entry[K, V]) bool) *itemTimer[K] {
+	switch c.o.eviction {
+	case EvictLRU:
+	case EvictLFU:
+		return c.leastUsed(match)
+	default:
+		return c.soonest(match)
+	}
+	for t := c.recent.prev; t != nil && t != &c.recent; t = t.prev {
+		if match(c.d[t.k]) {
+			return t
+		}
+	}
+	return nil
+}
+
+// used marks the item with timer t as the most recently used one, if
+// recency is tracked.
+func (c *Cache[K, V]) used(t *itemTimer[K]) {
+	if c.o.eviction != EvictLRU {
+		return
+	}
+	if c.recent.next == nil {
+		c.recent.next, c.recent.prev = &c.recent, &c.recent
+	}
+	if t.next != nil {
+		if c.recent.next == t {
+			return
+		}
+		t.prev.next, t.next.prev = t.next, t.prev
+	}
+	t.prev, t.next = &c.recent, c.recent.next
+	t.next.prev = t
+	c.recent.next = t
+}
+
+// unused stops tracking the recency of use of the item with timer t.
+func (c *Cache[K, V]) unused(t *itemTimer[K]) {
+	if t == nil || t.next == nil {
+		return
+	}
+	t.prev.next, t.next.prev = t.next, t.prev
+	t.prev, t.next = nil, nil
+}
+
+// leastUsed returns the timer of the least frequently used item among
+// the ones that match reports true for, or nil if none does.
+func (c *Cache[K, V]) leastUsed(match func(entry[K, V]) bool) *itemTimer[K] {
+	for f := newHeapFrontier(&c.fh); ; {
+		i := f.next()
+		if i < 0 {
+			return nil
+		}
+		if match(c.d[c.fh[i].k]) {
+			return c.fh[i]
+		}
+	}
+}
+
+// ranked tracks the frequency of use of the item with timer t, under
+// EvictLFU, or updates its rank, if tracked already.
+func (c *Cache[K, V]) ranked(t *itemTimer[K]) {
+	switch {
+	case c.o.eviction != EvictLFU:
+	case t.fi == 0:
+		heap.Push(&c.fh, t)
+	default:
+		heap.Fix(&c.fh, t.fi-1)
+	}
+}
+
+// reranked updates the rank of the item with timer t by frequency of
+// use, if tracked, after its hits or expiry change.
+func (c *Cache[K, V]) reranked(t *itemTimer[K]) {
+	if t.fi != 0 {
+		heap.Fix(&c.fh, t.fi-1)
+	}
+}
+
+// unranked stops tracking the frequency of use of the item with timer
+// t.
+func (c *Cache[K, V]) unranked(t *itemTimer[K]) {
+	if t != nil && t.fi != 0 {
+		heap.Remove(&c.fh, t.fi-1)
+	}
+}
+
+// colder returns whether the item with timer a is to be evicted before
+// the one with timer b under EvictLFU.
+func colder[K comparable](a, b *itemTimer[K]) bool {
+	if a.hits != b.hits {
+		return a.hits < b.hits
+	}
+	return a.x.Before(b.x)
+}
+
+// freqHeap orders the timers of the items by colder.
+type freqHeap[K comparable] []*itemTimer[K]
+
+var _ heap.Interface = (*freqHeap[int])(nil)
+
+func (h freqHeap[_]) Len() int {
+	return len(h)
+}
+
+func (h freqHeap[_]) Less(i int, j int) bool {
+	return colder(h[i], h[j])
+}
+
+func (h freqHeap[_]) Swap(i int, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].fi = i + 1
+	h[j].fi = j + 1
+}
+
+func (h *freqHeap[K]) Push(v any) {
+	t := v.(*itemTimer[K])
+	t.fi = len(*h) + 1
+	*h = append(*h, t)
+}
+
+func (h *freqHeap[_]) Pop() any {
+	s := *h
+	i := len(s) - 1
+	v := s[i]
+	s[i] = nil
+	v.fi = 0
+	*h = s[:i]
+	return v
+}
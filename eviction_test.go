@@ -0,0 +1,118 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestEvictLRU(t *testing.T) {
+	c := New[string, int](time.Hour, WithCapacity(3), WithEvictionPolicy(EvictLRU))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.PutWithTTL("a", 1, time.Minute) // Soonest to expire, but used.
+	c.Put("b", 2)
+	c.Put("c", 3, Pin())
+	c.Get("a")
+	if got, want := c.Victims(3), []string{"b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Victims() got=%v, want=%v", got, want)
+	}
+
+	c.Put("d", 4)
+	req.HasNot("b")
+	req.Has("a")
+	c.Put("e", 5)
+	req.HasNot("a")
+	req.Has("c") // Pinned.
+	req.LengthIs(3)
+	if n := c.Stats().Evicted; n != 2 {
+		t.Errorf("Stats().Evicted got=%d, want=2", n)
+	}
+
+	c.Drop("d")
+	c.Put("f", 6)
+	c.Put("g", 7)
+	req.HasNot("e")
+	req.Has("f")
+}
+
+func TestEvictLFU(t *testing.T) {
+	c := New[string, int](time.Hour, WithCapacity(3), WithEvictionPolicy(EvictLFU))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.PutWithTTL("b", 2, time.Minute)
+	c.Put("c", 3)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+	c.Get("c")
+	if got, want := c.Victims(3), []string{"b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Victims() got=%v, want=%v", got, want)
+	}
+
+	c.Put("d", 4)
+	req.HasNot("b")
+	c.Get("d")
+	c.Put("e", 5)
+	req.HasNot("d") // The item just put is spared.
+	req.Has("a")
+	req.Has("c")
+	req.LengthIs(3)
+}
+
+func TestEvictLFUOrder(t *testing.T) {
+	const n = 100
+	c := New[int, int](time.Hour, WithCapacity(n), WithEvictionPolicy(EvictLFU))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	// Item i is looked up i*7%n times, and every tenth one is pinned.
+	for i := 0; i < n; i++ {
+		if i%10 == 0 {
+			c.Put(i, i, Pin())
+		} else {
+			c.Put(i, i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i*7%n; j++ {
+			c.Get(i)
+		}
+	}
+	if err := c.CheckIntegrity(); err != nil {
+		t.Fatalf("CheckIntegrity() error: %v", err)
+	}
+	// Lookups are a bijection of the items, so the ones with the fewest
+	// are 0 (pinned), 43, 86, 29, 72.
+	if got, want := c.Victims(5), []int{0, 43, 86, 29, 72}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Victims() got=%v, want=%v", got, want)
+	}
+
+	// A burst of puts evicts the least used item, then displaces only
+	// the items put during it.
+	for i := n; i < 2*n; i++ {
+		c.Put(i, i)
+	}
+	req.LengthIs(n)
+	req.HasNot(43)
+	for i := 0; i < n; i++ {
+		if i != 43 {
+			req.Has(i)
+		}
+	}
+	req.Has(2*n - 1)
+	if err := c.CheckIntegrity(); err != nil {
+		t.Errorf("CheckIntegrity() error: %v", err)
+	}
+}
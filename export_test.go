@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// JumpWallClock simulates the wall clock jumping by d relative to the
+// monotonic clock and synchronizes the cache with it.
+func (c *Cache[K, V]) JumpWallClock(d time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	now := time.Now()
+	c.markClocks(now)
+	c.syncClock(now, now.Round(0).Add(d))
+}
+
+// Corrupt the size counter of the cache.
+func (c *Cache[K, V]) Corrupt() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.bytes++
+}
+
+// QuotaOrigins returns the number of origins with write quota windows.
+func (c *Cache[K, V]) QuotaOrigins() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return len(c.writes)
+}
+
+// Segments returns the numbers of the items in the hot segment, and of
+// the compressed ones.
+func (c *Cache[K, V]) Segments() (hot, cold int) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for _, val := range c.d {
+		if val.cold != nil {
+			cold++
+		}
+	}
+	return c.hotN, cold
+}
+
+// ColdReleased returns the number of the bytes of the cold segment
+// released since it was last compacted.
+func (c *Cache[K, V]) ColdReleased() int64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.coldSeg.dead
+}
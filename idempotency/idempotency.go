@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package idempotency implements the idempotency-key pattern, e.g.,
+// for HTTP APIs: requests that are retried with the same key are only
+// processed once, and get the result of that.
+package idempotency
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// Header is the HTTP header that the clients send idempotency keys in.
+const Header = "Idempotency-Key"
+
+// Keys tracks the requests by their idempotency keys, and keeps their
+// results of type R.
+//
+// The keys are shared by all the clients, which should be scoped, e.g.,
+// by prefixing them with the IDs of the clients, as needed.
+type Keys[R any] struct {
+	c    *cache.Cache[string, record[R]]
+	hold time.Duration
+}
+
+// New returns new Keys that hold the keys of requests in flight for up
+// to hold, after which they are released, in case the requests have
+// been abandoned without being completed, or aborted.
+func New[R any](hold time.Duration) *Keys[R] {
+	return &Keys[R]{
+		c:    cache.New[string, record[R]](hold, cache.WithoutSliding()),
+		hold: hold,
+	}
+}
+
+// Begin a request with key. Returns the result of the request with it
+// that has completed, if any, with done, or whether one is in flight.
+// Otherwise, the caller holds the key, and must either Complete, or
+// Abort the request.
+func (k *Keys[R]) Begin(key string) (result R, done, inFlight bool) {
+	for {
+		if k.c.PutIfAbsent(key, record[R]{}, cache.TTL(k.hold)) {
+			return
+		}
+		if r, ok := k.c.Get(key); ok {
+			return r.result, r.done, !r.done
+		}
+		// Released meanwhile.
+	}
+}
+
+// Complete the request with key, keeping its result for ttl.
+func (k *Keys[R]) Complete(key string, result R, ttl time.Duration) {
+	k.c.PutWithTTL(key, record[R]{result: result, done: true}, ttl)
+}
+
+// Abort the request with key, releasing it, e.g., so that it can be
+// retried after a failure.
+func (k *Keys[R]) Abort(key string) {
+	k.c.DropIf(key, func(r record[R]) bool {
+		return !r.done
+	})
+}
+
+// Shutdown releases the resources held by the keys.
+func (k *Keys[R]) Shutdown() {
+	k.c.Shutdown()
+}
+
+// A Response recorded by Middleware.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Middleware returns a handler that only passes the requests with an
+// idempotency key in the Header to next once, replaying the responses
+// of the ones that have completed, kept for ttl, and replying with the
+// 409 (Conflict) status to the ones in flight. Requests without a key
+// are passed through.
+//
+// The responses with a 5xx status are not kept, so that the requests
+// can be retried.
+func Middleware(k *Keys[Response], ttl time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		res, done, inFlight := k.Begin(key)
+		switch {
+		case done:
+			h := w.Header()
+			for name, v := range res.Header {
+				h[name] = v
+			}
+			w.WriteHeader(res.Status)
+			w.Write(res.Body)
+			return
+		case inFlight:
+			http.Error(w, "request with the same idempotency key in flight",
+				http.StatusConflict)
+			return
+		}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if p := recover(); p != nil {
+				k.Abort(key)
+				panic(p)
+			}
+			if rec.status >= 500 {
+				k.Abort(key)
+				return
+			}
+			k.Complete(key, Response{
+				Status: rec.status,
+				Header: w.Header().Clone(),
+				Body:   rec.body.Bytes(),
+			}, ttl)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// Internals.
+
+// A record of a request.
+type record[R any] struct {
+	result R
+	done   bool // Whether the request has completed.
+}
+
+// A recorder records the response written through it.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wrote {
+		r.status, r.wrote = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package idempotency_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache/idempotency"
+)
+
+func TestKeys(t *testing.T) {
+	k := New[int](time.Minute)
+	defer k.Shutdown()
+
+	if _, done, inFlight := k.Begin("a"); done || inFlight {
+		t.Fatalf("Begin(a) got done=%t, inFlight=%t, want neither", done, inFlight)
+	}
+	if _, done, inFlight := k.Begin("a"); done || !inFlight {
+		t.Errorf("Begin(a) again got done=%t, inFlight=%t, want in flight", done, inFlight)
+	}
+	k.Complete("a", 42, time.Minute)
+	if r, done, inFlight := k.Begin("a"); r != 42 || !done || inFlight {
+		t.Errorf("Begin(a) once complete got=%d, %t, %t, want=42, true, false",
+			r, done, inFlight)
+	}
+	k.Abort("a") // Does not release completed requests.
+	if _, done, _ := k.Begin("a"); !done {
+		t.Error("Abort() should not release a completed request")
+	}
+
+	k.Begin("b")
+	k.Abort("b")
+	if _, done, inFlight := k.Begin("b"); done || inFlight {
+		t.Errorf("Begin(b) once aborted got done=%t, inFlight=%t, want neither",
+			done, inFlight)
+	}
+}
+
+func TestKeysHold(t *testing.T) {
+	const hold = 10 * time.Millisecond
+	k := New[int](hold)
+	defer k.Shutdown()
+	k.Begin("a")
+	time.Sleep(3 * hold)
+	if _, _, inFlight := k.Begin("a"); inFlight {
+		t.Error("Begin(a) should not be in flight once held for too long")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	k := New[Response](time.Minute)
+	defer k.Shutdown()
+	var n int32
+	h := Middleware(k, time.Minute, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			i := atomic.AddInt32(&n, 1)
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("X-Order", fmt.Sprint(i))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, "order %d", i)
+		}))
+
+	do := func(path, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if key != "" {
+			r.Header.Set(Header, key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	first := do("/", "a")
+	again := do("/", "a")
+	if again.Code != http.StatusCreated || again.Body.String() != "order 1" ||
+		again.Header().Get("X-Order") != "1" || first.Body.String() != "order 1" {
+		t.Errorf("retried request got %d %q, want the first response replayed",
+			again.Code, again.Body.String())
+	}
+	if w := do("/", ""); w.Body.String() != "order 2" {
+		t.Errorf("request without a key got %q, want=%q", w.Body.String(), "order 2")
+	}
+	do("/fail", "b")
+	do("/fail", "b")
+	if got := atomic.LoadInt32(&n); got != 4 {
+		t.Errorf("handler calls: got=%d, want=4", got)
+	}
+
+	k.Begin("c")
+	if w := do("/", "c"); w.Code != http.StatusConflict {
+		t.Errorf("request in flight got status %d, want=%d", w.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,38 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// An ExpiringGetter is a Getter that knows how much longer the values it
+// gets may be used for, e.g., another Cache, which implements it.
+//
+// The values loaded from an ExpiringGetter are put in a cache to expire
+// no later than they do at the getter, without being extended by
+// lookups, so that stacked caches never serve them longer than their
+// source allows.
+type ExpiringGetter[K comparable, V any] interface {
+	Getter[K, V]
+	// GetExpiring gets the value for key with its remaining lifetime,
+	// which is the largest time.Duration, if not limited.
+	GetExpiring(key K) (value V, remaining time.Duration, ok bool)
+}
+
+var _ ExpiringGetter[int, any] = (*Cache[int, any])(nil)
+
+// GetExpiring gets a cached item, like Get does, with its remaining
+// lifetime, which is the largest time.Duration for pinned items.
+func (c *Cache[K, V]) GetExpiring(key K) (value V, remaining time.Duration, ok bool) {
+	c.lock()
+	val, found := c.find(key, true)
+	c.lookup(key, found)
+	remaining = indefinite
+	if found && !val.pin {
+		remaining = time.Until(val.t.x)
+	}
+	c.unlock()
+	value, ok = c.read(key, val.Value(), found)
+	return
+}
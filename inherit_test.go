@@ -0,0 +1,59 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestInheritedTTL(t *testing.T) {
+	l2 := New[string, int](time.Minute)
+	defer l2.Shutdown()
+	l1 := New[string, int](time.Hour)
+	defer l1.Shutdown()
+	req := newAssert(t, l1, true)
+
+	l2.PutWithTTL("a", 1, 2*ttl)
+	l2.Put("b", 2)
+	l2.Put("c", 3, Pin())
+
+	for _, k := range []string{"a", "b", "c"} {
+		if _, ok := l1.GetOrPut(k, l2); !ok {
+			t.Fatalf("GetOrPut(%s) should load from l2", k)
+		}
+	}
+	if e, _ := l1.GetEntry("b"); e.Remaining > time.Minute {
+		t.Errorf("remaining lifetime of b got=%v, want under %v", e.Remaining, time.Minute)
+	}
+	if e, _ := l1.GetEntry("c"); e.TTL != time.Hour {
+		t.Errorf("TTL of c got=%v, want=%v", e.TTL, time.Hour)
+	}
+	time.Sleep(ttl)
+	req.Touch("a") // Does not extend the inherited lifetime.
+	time.Sleep(2 * ttl)
+	req.HasNot("a")
+	req.Has("b")
+}
+
+func TestGetExpiring(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	c.Put("b", 2, Pin())
+
+	if v, rem, ok := c.GetExpiring("a"); !ok || v != 1 ||
+		rem <= 0 || rem > time.Minute {
+		t.Errorf("GetExpiring(a) got=%v,%v,%v, want 1 for up to a minute", v, rem, ok)
+	}
+	if _, rem, _ := c.GetExpiring("b"); rem < time.Hour {
+		t.Errorf("GetExpiring(b) of a pinned item got=%v, want unlimited", rem)
+	}
+	if _, _, ok := c.GetExpiring("c"); ok {
+		t.Error("GetExpiring(c) should not find 'c'")
+	}
+}
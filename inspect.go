@@ -0,0 +1,53 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build cachetest
+
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+// The inspection of the expiry schedule is only built with the
+// cachetest build tag, e.g.,
+//
+//	go test -tags cachetest ./...
+//
+// so that tests can assert the schedule directly, instead of sleeping
+// past it, without it being part of the API otherwise.
+
+// An Expiry scheduled by a Cache.
+type Expiry[K comparable] struct {
+	Key K
+	At  time.Time // Monotonic, like the times of time.Now.
+}
+
+// Expiries returns the expiries of all items scheduled by the cache,
+// in the order they are due, see NextExpiry. Pinned items are scheduled
+// indefinitely far ahead.
+func (c *Cache[K, V]) Expiries() []Expiry[K] {
+	c.lock()
+	defer c.m.Unlock()
+	ts := append(timerHeap[K](nil), c.th...)
+	sort.Sort(ts)
+	xs := make([]Expiry[K], len(ts))
+	for i, t := range ts {
+		xs[i] = Expiry[K]{t.k, t.x}
+	}
+	return xs
+}
+
+// ExpiryOf returns the time the item at key is scheduled to expire at,
+// and whether it is in the cache.
+func (c *Cache[K, V]) ExpiryOf(key K) (at time.Time, ok bool) {
+	c.lock()
+	defer c.m.Unlock()
+	val, found := c.d[key]
+	if !found {
+		return time.Time{}, false
+	}
+	return val.t.x, true
+}
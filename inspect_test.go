@@ -0,0 +1,53 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build cachetest
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestExpiries(t *testing.T) {
+	c := New[string, int](time.Hour)
+	defer c.Shutdown()
+
+	before := time.Now()
+	c.PutWithTTL("a", 1, time.Minute)
+	c.Put("b", 2, Pin())
+	c.Put("c", 3, TTL(time.Minute), MaxLifetime(2*time.Minute))
+	after := time.Now()
+
+	within := func(x time.Time, d time.Duration) bool {
+		return !x.Before(before.Add(d)) && !x.After(after.Add(d))
+	}
+	xs := c.Expiries()
+	if len(xs) != 3 || xs[0].Key != "a" || xs[1].Key != "c" || xs[2].Key != "b" {
+		t.Fatalf("Expiries() got=%v, want a, c, b", xs)
+	}
+	if !within(xs[0].At, time.Minute) {
+		t.Errorf("a scheduled at %v, want a minute after %v", xs[0].At, before)
+	}
+	if k, x, ok := c.NextExpiry(); !ok || k != xs[0].Key || x != xs[0].At {
+		t.Errorf("NextExpiry() got=%v,%v,%v, want=%v,true", k, x, ok, xs[0])
+	}
+
+	// Sliding reschedules, capped at the lifetime.
+	c.Get("c")
+	if x, _ := c.ExpiryOf("c"); !x.After(xs[1].At) ||
+		x.After(after.Add(2*time.Minute)) {
+		t.Errorf("c rescheduled at %v, want after %v", x, xs[1].At)
+	}
+	c.Drop("a")
+	if _, ok := c.ExpiryOf("a"); ok {
+		t.Error("ExpiryOf(a) should not find a dropped item")
+	}
+	if k, _, _ := c.NextExpiry(); k != "c" {
+		t.Errorf("NextExpiry() got=%v, want c", k)
+	}
+}
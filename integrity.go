@@ -0,0 +1,169 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// An IntegrityError reports the inconsistencies of the internal state
+// of a Cache found by CheckIntegrity.
+type IntegrityError struct {
+	Problems []string
+}
+
+func (e *IntegrityError) Error() string {
+	return "cache: integrity check failed: " + strings.Join(e.Problems, "; ")
+}
+
+// WithIntegrityChecks makes a Cache check its integrity every interval,
+// see CheckIntegrity, and call report with the errors of the failed
+// checks, which are also counted in Stats.Corrupt, so that subtle bugs
+// do not go unnoticed, e.g., as memory leaks.
+//
+// The cache is locked for the duration of a check, which takes time
+// proportional to the number of its items. The report function is
+// called after it is unlocked, so it may use the cache.
+func WithIntegrityChecks(interval time.Duration, report func(err error)) Option {
+	return func(o *options) {
+		o.checkEvery = interval
+		o.reportCorrupt = report
+	}
+}
+
+// CheckIntegrity cross-checks the internal state of the cache, i.e.,
+// the items against the expiry timers and the tags, and the item and
+// size counters against the items, returning an *IntegrityError, if it
+// is inconsistent.
+func (c *Cache[K, V]) CheckIntegrity() error {
+	c.lock()
+	defer c.m.Unlock()
+	if p := c.audit(); len(p) > 0 {
+		c.stats.Corrupt++
+		return &IntegrityError{p}
+	}
+	return nil
+}
+
+// Internals.
+
+// audit returns the descriptions of the problems with the internal
+// state of the cache. The cache must be locked.
+func (c *Cache[K, V]) audit() (problems []string) {
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if n := atomic.LoadInt64(&c.n); n != int64(len(c.d)) {
+		report("item count %d, want %d", n, len(c.d))
+	}
+	refreshing := 0
+	for _, val := range c.d {
+		if val.t != nil && val.t.refreshing {
+			refreshing++
+		}
+	}
+	if len(c.th)+refreshing != len(c.d) {
+		report("%d timers and %d refreshing for %d items",
+			len(c.th), refreshing, len(c.d))
+	}
+	for i, t := range c.th {
+		if t.i != i {
+			report("timer of %v at %d indexed %d", t.k, i, t.i)
+		}
+		if val, found := c.d[t.k]; !found {
+			report("timer of missing %v", t.k)
+		} else if val.t != t {
+			report("stray timer of %v", t.k)
+		} else if t.refreshing {
+			report("timer of %v refreshing on the heap", t.k)
+		}
+		if p := (i - 1) / 2; i > 0 && c.th[p].x.After(t.x) {
+			report("timer of %v due before its parent's", t.k)
+		}
+	}
+
+	if c.o.eviction == EvictLRU && len(c.d) > 0 {
+		n := 0
+		for t := c.recent.next; t != nil && t != &c.recent; t = t.next {
+			n++
+		}
+		if n != len(c.d) {
+			report("%d items in the order of recent use, want %d", n, len(c.d))
+		}
+	}
+	if c.o.hotItems > 0 {
+		n := 0
+		for t := c.hot.hnext; t != nil && t != &c.hot; t = t.hnext {
+			n++
+		}
+		if n != c.hotN {
+			report("%d items in the hot segment, counted %d", n, c.hotN)
+		}
+	}
+	if c.o.eviction == EvictLFU && len(c.fh) != len(c.d) {
+		report("%d items in the order of use frequency, want %d", len(c.fh), len(c.d))
+	}
+	for i, t := range c.fh {
+		if t.fi != i+1 {
+			report("frequency of %v at %d indexed %d", t.k, i, t.fi-1)
+		}
+		if p := (i - 1) / 2; i > 0 && colder(t, c.fh[p]) {
+			report("frequency of %v below its parent's", t.k)
+		}
+	}
+
+	var bytes, coldBytes int64
+	classBytes := map[string]int64{}
+	tagged := 0
+	for k, val := range c.d {
+		if val.t == nil {
+			report("%v has no timer", k)
+		}
+		bytes += val.size
+		if val.cold != nil {
+			coldBytes += int64(len(val.cold.data))
+		}
+		classBytes[val.class] += val.size
+		for _, tag := range val.tags {
+			if _, ok := c.tags[tag][k]; !ok {
+				report("%v missing from tag %q", k, tag)
+			}
+		}
+		tagged += len(val.tags)
+	}
+	if bytes != c.bytes {
+		report("size %d, want %d", c.bytes, bytes)
+	}
+	if coldBytes != c.coldSeg.live {
+		report("cold segment size %d, want %d", c.coldSeg.live, coldBytes)
+	}
+	if c.classOf != nil {
+		for class, b := range c.classBytes {
+			if b != classBytes[class] {
+				report("size of class %q %d, want %d", class, b, classBytes[class])
+			}
+		}
+	}
+	n := 0
+	for _, keys := range c.tags {
+		n += len(keys)
+	}
+	if n != tagged {
+		report("%d tagged keys, want %d", n, tagged)
+	}
+	return problems
+}
+
+// checkIntegrity checks the integrity of the cache and reports its
+// failure, if any.
+func (c *Cache[K, V]) checkIntegrity() {
+	if err := c.CheckIntegrity(); err != nil && c.o.reportCorrupt != nil {
+		c.o.reportCorrupt(err)
+	}
+}
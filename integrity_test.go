@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	c := New[int, int](time.Minute,
+		WithCapacity(50),
+		WithEvictionPolicy(EvictLRU),
+	)
+	defer c.Shutdown()
+	for i := 0; i < 100; i++ {
+		c.PutWithTTL(i, i, time.Duration(i%7)*ttl)
+		c.Tag(i, "t")
+		if i%3 == 0 {
+			c.Drop(i / 2)
+		}
+		c.Get(i / 3)
+	}
+	time.Sleep(2 * ttl)
+	c.DropTag("t")
+	c.Put(1, 1)
+	if err := c.CheckIntegrity(); err != nil {
+		t.Fatalf("CheckIntegrity() error: %v", err)
+	}
+
+	c.Corrupt()
+	var ie *IntegrityError
+	if err := c.CheckIntegrity(); !errors.As(err, &ie) || len(ie.Problems) != 1 {
+		t.Errorf("CheckIntegrity() got=%v, want one problem", err)
+	}
+	if n := c.Stats().Corrupt; n != 1 {
+		t.Errorf("Stats().Corrupt got=%d, want=1", n)
+	}
+}
+
+func TestWithIntegrityChecks(t *testing.T) {
+	reported := make(chan error, 1)
+	c := New[int, int](time.Minute, WithIntegrityChecks(ttl, func(err error) {
+		select {
+		case reported <- err:
+		default:
+		}
+	}))
+	defer c.Shutdown()
+	c.Put(1, 1)
+	c.Corrupt()
+	select {
+	case err := <-reported:
+		if err == nil {
+			t.Error("should have reported an error")
+		}
+	case <-time.After(time.Second):
+		t.Error("should have reported the corruption")
+	}
+}
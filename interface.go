@@ -0,0 +1,95 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrReadOnly is returned when modifying a read-only cache.
+var ErrReadOnly = errors.New("cache: read-only")
+
+// An Interface of a cache, for application code to depend on, so that
+// the implementation can be swapped, e.g., in tests.
+//
+// It is implemented by Cache, TieredCache and ReadOnlyCache, as well as
+// by the remote caches in package cachehttp.
+type Interface[K comparable, V any] interface {
+	Getter[K, V]
+	// Has returns whether an item for key is present.
+	Has(key K) bool
+	// PutWithTTLE puts value at key, with the given time-to-live, and
+	// returns an error if it has not been put.
+	PutWithTTLE(key K, value V, ttl time.Duration) error
+	// DropE drops the item at key, if present, and returns an error if
+	// it could not be dropped.
+	DropE(key K) error
+}
+
+var (
+	_ Interface[int, any] = (*Cache[int, any])(nil)
+	_ Interface[int, any] = (*TieredCache[int, any])(nil)
+	_ Interface[int, any] = (*ReadOnlyCache[int, any])(nil)
+)
+
+// DropE drops a cached item, like Drop does, for the Interface, never
+// returning an error.
+func (c *Cache[K, V]) DropE(key K) error {
+	c.Drop(key)
+	return nil
+}
+
+// Has returns whether an item for given key is present in either tier.
+func (c *TieredCache[K, V]) Has(key K) bool {
+	if c.l1.Has(key) {
+		return true
+	}
+	_, ok := c.l2.Get(key)
+	return ok
+}
+
+// PutWithTTLE puts a value in both tiers, like PutWithTTL does, for the
+// Interface.
+func (c *TieredCache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	return c.PutWithTTL(key, value, ttl)
+}
+
+// DropE drops the value at key from both tiers, like Drop does, for the
+// Interface.
+func (c *TieredCache[K, V]) DropE(key K) error {
+	return c.Drop(key)
+}
+
+// A ReadOnlyCache is a view of a cache that cannot be modified through
+// it.
+type ReadOnlyCache[K comparable, V any] struct {
+	c Interface[K, V]
+}
+
+// ReadOnly returns a read-only view of c.
+func ReadOnly[K comparable, V any](c Interface[K, V]) *ReadOnlyCache[K, V] {
+	return &ReadOnlyCache[K, V]{c: c}
+}
+
+// Get the value of a cached item.
+func (r *ReadOnlyCache[K, V]) Get(key K) (value V, ok bool) {
+	return r.c.Get(key)
+}
+
+// Has returns whether an item for given key is present in the cache.
+func (r *ReadOnlyCache[K, V]) Has(key K) bool {
+	return r.c.Has(key)
+}
+
+// PutWithTTLE does not put the value and returns ErrReadOnly.
+func (r *ReadOnlyCache[K, V]) PutWithTTLE(K, V, time.Duration) error {
+	return ErrReadOnly
+}
+
+// DropE does not drop the item and returns ErrReadOnly.
+func (r *ReadOnlyCache[K, V]) DropE(K) error {
+	return ErrReadOnly
+}
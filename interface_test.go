@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestInterface(t *testing.T) {
+	c := New[string, int](ttl)
+	defer c.Shutdown()
+	l1 := New[string, int](ttl)
+	defer l1.Shutdown()
+	tc := NewTiered[string, int](l1, mapStore[string, int]{})
+
+	for name, i := range map[string]Interface[string, int]{
+		"Cache":       c,
+		"TieredCache": tc,
+	} {
+		if err := i.PutWithTTLE("a", 1, ttl); err != nil {
+			t.Fatalf("%s PutWithTTLE() error: %v", name, err)
+		}
+		if v, ok := i.Get("a"); !ok || v != 1 {
+			t.Errorf("%s Get(a) got=%v,%v, want=1,true", name, v, ok)
+		}
+		if err := ReadOnly(i).PutWithTTLE("b", 2, ttl); err != ErrReadOnly {
+			t.Errorf("%s ReadOnly PutWithTTLE() got=%v, want=%v", name, err, ErrReadOnly)
+		}
+		if err := ReadOnly(i).DropE("a"); err != ErrReadOnly || !ReadOnly(i).Has("a") {
+			t.Errorf("%s ReadOnly DropE() got=%v, want=%v", name, err, ErrReadOnly)
+		}
+		if err := i.DropE("a"); err != nil || i.Has("a") {
+			t.Errorf("%s DropE() got=%v, should have dropped", name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package window implements the sliding window counting the events
+// limited by the write quotas of caches and by package ratelimit.
+package window
+
+import "time"
+
+// A Window counts events in a sliding window of a period, approximated
+// from the counts of the current and the previous fixed periods,
+// weighing the latter by how much of it the window still covers.
+//
+// It is not safe for concurrent use.
+type Window struct {
+	start time.Time // Of the current period.
+	prev  int       // Count in the previous period.
+	cur   int       // Count in the current period.
+}
+
+// Add n events at now, unless that would exceed limit in the window of
+// period per, and return whether they have been added.
+func (w *Window) Add(now time.Time, per time.Duration, limit, n int) bool {
+	if elapsed := now.Sub(w.start); elapsed >= 2*per {
+		w.start, w.prev, w.cur = now, 0, 0
+	} else if elapsed >= per {
+		w.start, w.prev, w.cur = w.start.Add(per), w.cur, 0
+	}
+	weight := 1 - float64(now.Sub(w.start))/float64(per)
+	if float64(w.prev)*weight+float64(w.cur+n) > float64(limit) {
+		return false
+	}
+	w.cur += n
+	return true
+}
+
+// Idle returns whether the window of period per counts no events at
+// now, so that it can be discarded.
+func (w *Window) Idle(now time.Time, per time.Duration) bool {
+	return now.Sub(w.start) >= 2*per
+}
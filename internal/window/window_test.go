@@ -0,0 +1,32 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package window_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache/internal/window"
+)
+
+func TestWindow(t *testing.T) {
+	const per = time.Second
+	now := time.Now()
+	var w Window
+	if !w.Add(now, per, 2, 2) || w.Add(now, per, 2, 1) {
+		t.Fatal("Add() should allow 2 events per period, and no more")
+	}
+	// Half of the previous period is still in the window.
+	now = now.Add(3 * per / 2)
+	if !w.Add(now, per, 2, 1) || w.Add(now, per, 2, 1) {
+		t.Fatal("Add() should allow 1 event half a period later")
+	}
+	if w.Idle(now, per) {
+		t.Fatal("Idle() should be false with events in the window")
+	}
+	if now = now.Add(2 * per); !w.Idle(now, per) {
+		t.Fatal("Idle() should be true two periods later")
+	}
+}
@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrZeroKey is returned when putting a value at the zero value of the
+// key type in a cache that rejects it, see WithoutZeroKeys.
+var ErrZeroKey = errors.New("cache: zero key")
+
+// WithoutZeroKeys makes a Cache reject the zero value of its key type,
+// e.g., an empty string or a zero ID, which usually is a bug, rather
+// than a meaningful key. Values are not put at rejected keys, which
+// PutE and PutWithTTLE report with ErrZeroKey.
+func WithoutZeroKeys() Option {
+	return func(o *options) {
+		o.noZeroKeys = true
+	}
+}
+
+// WithKeyCheck sets a function that checks the keys of the values put
+// in a Cache of the same key type, rejecting the ones it returns an
+// error for. Values are not put at rejected keys, which PutE and
+// PutWithTTLE report with the error returned.
+//
+// New panics if the key type does not match that of the cache.
+func WithKeyCheck[K comparable](check func(key K) error) Option {
+	return func(o *options) {
+		o.keyCheck = check
+	}
+}
+
+// PutE puts a value in cache, like Put does, and returns an error if
+// it has not been put, due to the key being rejected, the cache being
+// quiesced (ErrQuiesced), or a write quota exceeded (QuotaError).
+func (c *Cache[K, V]) PutE(key K, value V, opts ...CallOption) error {
+	return c.put(key, value, c.callOptions(key, opts))
+}
+
+// PutWithTTLE puts a value in cache, like PutWithTTL does, and returns
+// an error if it has not been put, like PutE does.
+func (c *Cache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	return c.put(key, value, callOptions{ttl: ttl, ttlSet: true})
+}
+
+// Internals.
+
+// keyCheckOf returns the function checking keys according to the
+// options, or nil if keys are not checked.
+func keyCheckOf[K comparable](o options) func(K) error {
+	var check func(K) error
+	if o.keyCheck != nil {
+		var ok bool
+		if check, ok = o.keyCheck.(func(K) error); !ok {
+			panic("cache: WithKeyCheck key type does not match that of the cache")
+		}
+	}
+	if !o.noZeroKeys {
+		return check
+	}
+	return func(key K) error {
+		var zero K
+		if key == zero {
+			return ErrZeroKey
+		}
+		if check != nil {
+			return check(key)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithoutZeroKeys(t *testing.T) {
+	c := New[string, int](ttl, WithoutZeroKeys())
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	if err := c.PutE("", 1); err != ErrZeroKey {
+		t.Fatalf("PutE(zero key): got %v, want %v", err, ErrZeroKey)
+	}
+	c.Put("", 1)
+	req.HasNot("")
+	req.GetOrPut("", SimpleGetterFunc[string, int](func() int { return 2 }))
+	req.HasNot("")
+
+	if err := c.PutWithTTLE("a", 1, ttl); err != nil {
+		t.Fatalf("PutWithTTLE: %v", err)
+	}
+	req.Has("a")
+
+	c.Quiesce()
+	if err := c.PutE("b", 1); err != ErrQuiesced {
+		t.Fatalf("PutE(quiesced): got %v, want %v", err, ErrQuiesced)
+	}
+}
+
+func TestWithKeyCheck(t *testing.T) {
+	errSpace := errors.New("space in key")
+	c := New[string, int](ttl, WithoutZeroKeys(), WithKeyCheck(
+		func(key string) error {
+			if strings.Contains(key, " ") {
+				return errSpace
+			}
+			return nil
+		},
+	))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	if err := c.PutE("a b", 1); err != errSpace {
+		t.Fatalf("PutE(invalid key): got %v, want %v", err, errSpace)
+	}
+	req.HasNot("a b")
+	if err := c.PutE("", 1); err != ErrZeroKey {
+		t.Fatalf("PutE(zero key): got %v, want %v", err, ErrZeroKey)
+	}
+	if err := c.PutE("ab", 1); err != nil {
+		t.Fatalf("PutE: %v", err)
+	}
+	req.Has("ab")
+}
+
+func TestWithKeyCheckTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("New should panic")
+		}
+	}()
+	New[string, int](ttl, WithKeyCheck(func(int) error { return nil }))
+}
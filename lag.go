@@ -0,0 +1,57 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// WithLazyExpiry makes the lookups in a Cache check whether the items
+// have expired, and drop the ones that have, while the goroutine
+// processing item expiry lags behind by more than maxLag, e.g., due to
+// a mass expiry or slow OnEvict functions, so that expired values are
+// not served under load. How far behind it is shows in Stats.
+//
+// The lock-free reads of WithLockFreeReads are not checked.
+func WithLazyExpiry(maxLag time.Duration) Option {
+	return func(o *options) {
+		o.lazyExpiry = true
+		o.maxLag = maxLag
+	}
+}
+
+// WithStrictExpiry makes the lookups in a Cache always check whether
+// the items have expired, and drop the ones that have, so that values
+// are never served past their expiry, even by microseconds, before the
+// goroutine processing item expiry drops them.
+//
+// The lock-free reads of WithLockFreeReads are not checked.
+func WithStrictExpiry() Option {
+	return func(o *options) {
+		o.strictExpiry = true
+	}
+}
+
+// Internals.
+
+// lag records that an item has been dropped d past its expiry time.
+func (c *Cache[K, V]) lag(d time.Duration) {
+	if d > c.stats.MaxExpiryLag {
+		c.stats.MaxExpiryLag = d
+	}
+}
+
+// overdue returns how long past now the soonest expiry is overdue.
+func (c *Cache[K, V]) overdue(now time.Time) time.Duration {
+	if len(c.th) == 0 || c.th[0].x.After(now) {
+		return 0
+	}
+	return now.Sub(c.th[0].x)
+}
+
+// checksExpiry returns whether expiry is checked on lookups, strictly,
+// or due to lagging behind.
+func (c *Cache[K, V]) checksExpiry() bool {
+	return c.o.strictExpiry ||
+		c.o.lazyExpiry && c.overdue(time.Now()) > c.o.maxLag
+}
@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithLazyExpiry(t *testing.T) {
+	// A slow OnEvict function holds the expiry goroutine up.
+	var blocked int32
+	block := make(chan struct{})
+	c := New[string, int](ttl,
+		WithLazyExpiry(ttl),
+		WithOnEvict(func(string, int, EvictReason) {
+			if atomic.CompareAndSwapInt32(&blocked, 0, 1) {
+				<-block
+			}
+		}),
+	)
+	defer c.Shutdown()
+	defer close(block)
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.PutWithTTL("b", 2, 3*ttl)
+	c.PutWithTTL("c", 3, 3*ttl)
+	c.PutWithTTL("d", 4, time.Minute)
+	time.Sleep(3 * ttl)
+	req.Has("b") // Not overdue by more than ttl yet.
+	time.Sleep(2 * ttl)
+	if s := c.Stats(); s.ExpiryLag < ttl {
+		t.Errorf("Stats().ExpiryLag got=%v, want over %v", s.ExpiryLag, ttl)
+	}
+	req.HasNot("b")
+	req.GetNot("c")
+	req.Has("d")
+	if s := c.Stats(); s.ExpiryLag != 0 || s.Expired != 3 {
+		t.Errorf("Stats() after lazy expiry got lag=%v, expired=%d", s.ExpiryLag, s.Expired)
+	}
+}
+
+func TestWithStrictExpiry(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		var opts []Option
+		if strict {
+			opts = append(opts, WithStrictExpiry())
+		}
+		c := New[string, int](time.Minute, opts...)
+		// Nothing else drops expired items once shut down.
+		c.Shutdown()
+		c.PutUntil("a", 1, time.Now().Add(-time.Second))
+		if _, ok := c.Get("a"); ok == strict {
+			t.Errorf("Get(a) of expired with strict=%v got ok=%v", strict, ok)
+		}
+	}
+
+	c := New[string, int](time.Minute, WithStrictExpiry())
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	c.PutUntil("a", 1, time.Now().Add(-time.Second))
+	req.GetNot("a")
+	c.PutWithTTL("b", 2, time.Minute)
+	req.Has("b")
+	if s := c.Stats(); s.Expired != 1 {
+		t.Errorf("Stats().Expired got=%d, want=1", s.Expired)
+	}
+}
@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package lease implements in-process leases of keys on a cache, which
+// expire unless renewed, e.g., to elect a single worker for a job.
+package lease
+
+import (
+	"sync"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// A Manager grants leases of keys, one at a time per key.
+type Manager[K comparable] struct {
+	m sync.Mutex // Serializes the changes of holders.
+	c *cache.Cache[K, *token]
+}
+
+// New Manager of leases.
+func New[K comparable]() *Manager[K] {
+	return &Manager[K]{
+		c: cache.New[K, *token](0,
+			cache.WithoutSliding(),
+			cache.WithOnEvict(func(_ K, t *token, _ cache.EvictReason) {
+				close(t.lost)
+			}),
+		),
+	}
+}
+
+// AcquireLease of key for ttl. Returns false if the key is leased.
+func (m *Manager[K]) AcquireLease(key K, ttl time.Duration) (*Lease[K], bool) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.c.Has(key) {
+		return nil, false
+	}
+	t := &token{lost: make(chan struct{})}
+	m.c.PutWithTTL(key, t, ttl)
+	return &Lease[K]{m: m, key: key, t: t}, true
+}
+
+// Held returns whether key is leased.
+func (m *Manager[K]) Held(key K) bool {
+	return m.c.Has(key)
+}
+
+// Shutdown the underlying cache.
+func (m *Manager[K]) Shutdown() {
+	m.c.Shutdown()
+}
+
+// A Lease of a key.
+type Lease[K comparable] struct {
+	m   *Manager[K]
+	key K
+	t   *token
+}
+
+// Key returns the leased key.
+func (l *Lease[K]) Key() K {
+	return l.key
+}
+
+// Renew the lease for ttl from now. Returns false if it has expired or
+// been released.
+func (l *Lease[K]) Renew(ttl time.Duration) bool {
+	l.m.m.Lock()
+	defer l.m.m.Unlock()
+	if !l.holds() {
+		return false
+	}
+	_, ok := l.m.c.GetAndTouch(l.key, ttl)
+	return ok
+}
+
+// Release the lease early. Returns false if it has expired or been
+// released already.
+func (l *Lease[K]) Release() bool {
+	l.m.m.Lock()
+	defer l.m.m.Unlock()
+	if !l.holds() {
+		return false
+	}
+	l.m.c.Drop(l.key)
+	return true
+}
+
+// Lost returns a channel that is closed when the lease expires or is
+// released.
+func (l *Lease[K]) Lost() <-chan struct{} {
+	return l.t.lost
+}
+
+// Internals.
+
+// A token identifies a lease.
+type token struct {
+	lost chan struct{} // Closed once the lease has ended.
+}
+
+// holds returns whether the lease is the current one of its key.
+func (l *Lease[K]) holds() bool {
+	t, ok := l.m.c.GetWith(l.key, cache.NoSlide())
+	return ok && t == l.t
+}
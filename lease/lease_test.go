@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package lease_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache/lease"
+)
+
+const ttl = 20 * time.Millisecond
+
+func TestAcquireLease(t *testing.T) {
+	m := New[string]()
+	defer m.Shutdown()
+
+	l, ok := m.AcquireLease("job", 2*ttl)
+	if !ok || l.Key() != "job" {
+		t.Fatal("AcquireLease(job) should succeed")
+	}
+	if _, ok := m.AcquireLease("job", ttl); ok {
+		t.Fatal("AcquireLease(job) while leased should fail")
+	}
+	time.Sleep(ttl)
+	if !l.Renew(2 * ttl) {
+		t.Fatal("Renew() should succeed")
+	}
+	time.Sleep(3 * ttl / 2)
+	if !m.Held("job") {
+		t.Fatal("renewed lease should be held")
+	}
+	select {
+	case <-l.Lost():
+	case <-time.After(2 * ttl):
+		t.Fatal("lease should have been lost")
+	}
+	if l.Renew(ttl) || l.Release() {
+		t.Fatal("Renew() and Release() of an expired lease should fail")
+	}
+
+	l2, ok := m.AcquireLease("job", time.Minute)
+	if !ok {
+		t.Fatal("AcquireLease(job) after expiry should succeed")
+	}
+	if l.Release() || !m.Held("job") {
+		t.Fatal("Release() of a previous lease should not release the current")
+	}
+	if !l2.Release() {
+		t.Fatal("Release() should succeed")
+	}
+	<-l2.Lost()
+	if m.Held("job") {
+		t.Fatal("released lease should not be held")
+	}
+}
@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "sync/atomic"
+
+// WithLockFreeReads makes Get and Has in a Cache never lock it, for
+// read-mostly workloads, by reading from an immutable copy of the
+// values that every modification of the cache replaces, which makes
+// modifications take time proportional to the number of items.
+//
+// Get then does not extend the lifetimes of the items, which only
+// touching them, or the other lookup methods, do.
+func WithLockFreeReads() Option {
+	return func(o *options) {
+		o.lockFree = true
+	}
+}
+
+// Internals.
+
+// getLockFree gets a cached item from the published copy of the values.
+func (c *Cache[K, V]) getLockFree(key K) (value V, ok bool) {
+	value, ok = c.snap.Load().(map[K]V)[key]
+	c.countKey(key)
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return c.read(key, value, ok)
+}
+
+// hasLockFree returns whether the published copy of the values has one
+// at key.
+func (c *Cache[K, V]) hasLockFree(key K) bool {
+	_, found := c.snap.Load().(map[K]V)[key]
+	return found
+}
+
+// publish a copy of the values for lock-free reads, if modified since
+// the last one.
+func (c *Cache[K, V]) publish() {
+	if !c.stale {
+		return
+	}
+	c.stale = false
+	m := make(map[K]V, len(c.d))
+	for k, val := range c.d {
+		m[k] = val.Value()
+	}
+	c.snap.Store(m)
+}
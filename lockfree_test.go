@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithLockFreeReads(t *testing.T) {
+	c := New[string, int](2*ttl, WithLockFreeReads())
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.GetNot("a")
+	c.Put("a", 1)
+	if got := req.Get("a"); got != 1 {
+		t.Fatalf("Get: got=%d, want=1", got)
+	}
+	c.Put("a", 2)
+	if got := req.Get("a"); got != 2 {
+		t.Fatalf("Get: got=%d, want=2", got)
+	}
+	c.Drop("a")
+	req.HasNot("a")
+
+	c.Put("b", 1)
+	time.Sleep(3 * ttl / 2)
+	req.Get("b") // Does not slide.
+	time.Sleep(ttl)
+	req.HasNot("b")
+
+	s := c.Stats()
+	if s.Hits != 3 || s.Misses != 1 {
+		t.Fatalf("Stats: got hits=%d, misses=%d, want 3, 1", s.Hits, s.Misses)
+	}
+}
+
+func TestWithLockFreeReadsRaces(t *testing.T) {
+	c := New[int, int](ttl, WithLockFreeReads())
+	defer c.Shutdown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Put(j%10, i)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Get(j % 10)
+				c.Has(j % 10)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkGetLockFree(b *testing.B) {
+	c := New[int, int](time.Minute, WithLockFreeReads())
+	defer c.Shutdown()
+	c.Put(1, 1)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Get(1)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// A MultiMap holds multiple values per key, each with its own lifetime,
+// e.g., the active connections per user.
+type MultiMap[K, V comparable] struct {
+	c *Cache[pair[K, V], uint64] // Of the generations the pairs were added in.
+
+	// The index is never locked during a cache call, which may report
+	// evictions to unindex, and the adds are serialized, so that the
+	// pairs are put in the order of their generations.
+	a   sync.Mutex
+	m   sync.Mutex
+	gen uint64
+	idx map[K]map[V]uint64 // Generations of the values by key.
+}
+
+// NewMultiMap returns a new MultiMap of values that expire after
+// defaultTTL, unless added with another time-to-live.
+//
+// The options apply to the underlying cache of key-value pairs, so,
+// e.g., its capacity limits the number of pairs. The OnEvict function
+// of the cache is set by the MultiMap.
+func NewMultiMap[K, V comparable](defaultTTL time.Duration, opts ...Option) *MultiMap[K, V] {
+	m := &MultiMap[K, V]{idx: make(map[K]map[V]uint64)}
+	m.c = New[pair[K, V], uint64](defaultTTL, append(opts[:len(opts):len(opts)],
+		WithOnEvict(func(p pair[K, V], gen uint64, _ EvictReason) {
+			m.unindex(p, gen)
+		}),
+	)...)
+	return m
+}
+
+// Add value at key, with the cache-default time-to-live. Adding a
+// present value extends its lifetime.
+func (m *MultiMap[K, V]) Add(key K, value V) {
+	m.add(key, value, m.c.callOptions(pair[K, V]{key, value}, nil))
+}
+
+// AddWithTTL adds value at key with the given time-to-live.
+func (m *MultiMap[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	m.add(key, value, callOptions{ttl: ttl, ttlSet: true})
+}
+
+// Values returns the values at key, in no particular order.
+func (m *MultiMap[K, V]) Values(key K) []V {
+	m.m.Lock()
+	values := make([]V, 0, len(m.idx[key]))
+	for v := range m.idx[key] {
+		values = append(values, v)
+	}
+	m.m.Unlock()
+	// Expired pairs may not have been unindexed yet.
+	n := 0
+	for _, v := range values {
+		if m.c.Has(pair[K, V]{key, v}) {
+			values[n] = v
+			n++
+		}
+	}
+	return values[:n]
+}
+
+// Count returns the number of values at key.
+func (m *MultiMap[K, V]) Count(key K) int {
+	return len(m.Values(key))
+}
+
+// Keys returns the keys that have values, in no particular order.
+func (m *MultiMap[K, V]) Keys() []K {
+	m.m.Lock()
+	defer m.m.Unlock()
+	keys := make([]K, 0, len(m.idx))
+	for k := range m.idx {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Remove value from key. Returns whether it was present.
+func (m *MultiMap[K, V]) Remove(key K, value V) bool {
+	_, ok := m.c.Drop(pair[K, V]{key, value})
+	return ok
+}
+
+// RemoveAll values from key and return their number.
+func (m *MultiMap[K, V]) RemoveAll(key K) (n int) {
+	for _, v := range m.Values(key) {
+		if m.Remove(key, v) {
+			n++
+		}
+	}
+	return
+}
+
+// Shutdown the underlying cache.
+func (m *MultiMap[K, V]) Shutdown() {
+	m.c.Shutdown()
+}
+
+// Internals.
+
+// A pair of a key and a value of a MultiMap.
+type pair[K, V comparable] struct {
+	k K
+	v V
+}
+
+func (m *MultiMap[K, V]) add(key K, value V, o callOptions) {
+	m.a.Lock()
+	defer m.a.Unlock()
+	m.m.Lock()
+	m.gen++
+	gen := m.gen
+	if m.idx[key] == nil {
+		m.idx[key] = make(map[V]uint64)
+	}
+	m.idx[key][value] = gen
+	m.m.Unlock()
+	m.c.put(pair[K, V]{key, value}, gen, o)
+}
+
+// unindex a pair added in the gen generation that has left the cache,
+// unless it has been added again since.
+func (m *MultiMap[K, V]) unindex(p pair[K, V], gen uint64) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if g, ok := m.idx[p.k][p.v]; !ok || g != gen {
+		return
+	}
+	delete(m.idx[p.k], p.v)
+	if len(m.idx[p.k]) == 0 {
+		delete(m.idx, p.k)
+	}
+}
@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestMultiMap(t *testing.T) {
+	m := NewMultiMap[string, int](2 * ttl)
+	defer m.Shutdown()
+
+	m.Add("u", 1)
+	m.Add("u", 2)
+	m.AddWithTTL("u", 3, time.Minute)
+	m.Add("v", 1)
+	got := m.Values("u")
+	sort.Ints(got)
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Fatalf("Values(u) got=%v, want=[1 2 3]", got)
+	}
+	if n := len(m.Keys()); n != 2 {
+		t.Fatalf("Keys() got %d keys, want 2", n)
+	}
+
+	if !m.Remove("u", 2) || m.Remove("u", 2) {
+		t.Fatal("Remove(u, 2) should only remove 2 once")
+	}
+	time.Sleep(3 * ttl)
+	if got := m.Values("u"); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("Values(u) after expiry got=%v, want=[3]", got)
+	}
+	if got := m.Count("v"); got != 0 {
+		t.Fatalf("Count(v) after expiry got=%d, want=0", got)
+	}
+	if keys := m.Keys(); len(keys) != 1 || keys[0] != "u" {
+		t.Fatalf("Keys() after expiry got=%v, want=[u]", keys)
+	}
+	if n := m.RemoveAll("u"); n != 1 || m.Count("u") != 0 {
+		t.Fatalf("RemoveAll(u) got=%d, want=1", n)
+	}
+}
+
+func TestMultiMapCapacity(t *testing.T) {
+	m := NewMultiMap[string, int](time.Minute, WithCapacity(1))
+	defer m.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Add("a", 1)
+		m.Add("a", 2) // Evicts 1.
+		m.Add("b", 1) // Evicts 2.
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Add() with evictions deadlocked")
+	}
+	if got := m.Values("a"); len(got) != 0 {
+		t.Errorf("Values(a) got=%v, want none", got)
+	}
+	if keys := m.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("Keys() got=%v, want=[b]", keys)
+	}
+}
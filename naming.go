@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// Span attribute keys of the identity of a cache.
+const (
+	// AttrName is the string name of the cache, see WithName.
+	AttrName = "cache.name"
+	// AttrLabelPrefix prefixes the names of the labels of the cache,
+	// see WithLabels, in the keys of their string values.
+	AttrLabelPrefix = "cache.label."
+)
+
+// WithName sets a stable name of a Cache, e.g., "sessions", which the
+// observability features attribute their output to, so that it can be
+// told apart from that of other caches in the same application.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithLabels sets arbitrary labels of a Cache, e.g., its tenant or
+// region, which the observability features attribute their output to,
+// along with the name. The map is copied, and the labels given by
+// repeated options are merged.
+func WithLabels(labels map[string]string) Option {
+	return func(o *options) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// Name returns the name of the cache set by WithName, if any.
+func (c *Cache[K, V]) Name() string {
+	return c.o.name
+}
+
+// Labels returns a copy of the labels of the cache set by WithLabels.
+func (c *Cache[K, V]) Labels() map[string]string {
+	labels := make(map[string]string, len(c.o.labels))
+	for k, v := range c.o.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// Internals.
+
+// identify span as one of the cache, by its name and labels, if any.
+func (c *Cache[K, V]) identify(span Span) {
+	if c.o.name != "" {
+		span.SetAttribute(AttrName, c.o.name)
+	}
+	for k, v := range c.o.labels {
+		span.SetAttribute(AttrLabelPrefix+k, v)
+	}
+}
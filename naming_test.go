@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestNaming(t *testing.T) {
+	tr := &recordingTracer{}
+	labels := map[string]string{"region": "eu"}
+	c := New[string, int](time.Minute,
+		WithTracer(tr),
+		WithName("sessions"),
+		WithLabels(labels),
+		WithLabels(map[string]string{"tier": "web"}),
+	)
+	defer c.Shutdown()
+	labels["region"] = "us" // Copied.
+
+	if n := c.Name(); n != "sessions" {
+		t.Errorf("Name() got=%q, want=%q", n, "sessions")
+	}
+	want := map[string]string{"region": "eu", "tier": "web"}
+	got := c.Labels()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() got=%v, want=%v", got, want)
+	}
+	got["tier"] = "db"
+	if c.Labels()["tier"] != "web" {
+		t.Error("Labels() should return a copy")
+	}
+
+	c.GetContext(context.Background(), "a")
+	attrs := tr.spans[0].attrs
+	for k, v := range map[string]any{
+		AttrName:                   "sessions",
+		AttrLabelPrefix + "region": "eu",
+		AttrLabelPrefix + "tier":   "web",
+	} {
+		if attrs[k] != v {
+			t.Errorf("span attribute %s got=%v, want=%v", k, attrs[k], v)
+		}
+	}
+}
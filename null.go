@@ -0,0 +1,33 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+var _ Interface[int, any] = NullCache[int, any]{}
+
+// A NullCache never has any items, it discards all the values put in
+// it, e.g., to disable caching by configuration.
+type NullCache[K comparable, V any] struct{}
+
+// Get always misses.
+func (NullCache[K, V]) Get(K) (value V, ok bool) {
+	return
+}
+
+// Has always returns false.
+func (NullCache[K, V]) Has(K) bool {
+	return false
+}
+
+// PutWithTTLE discards the value.
+func (NullCache[K, V]) PutWithTTLE(K, V, time.Duration) error {
+	return nil
+}
+
+// DropE does nothing.
+func (NullCache[K, V]) DropE(K) error {
+	return nil
+}
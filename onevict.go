@@ -0,0 +1,200 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// An EvictReason tells why an item has left a Cache.
+type EvictReason int
+
+const (
+	// ReasonExpired items have outlived their time-to-live.
+	ReasonExpired EvictReason = iota
+	// ReasonEvicted items have been evicted to keep the cache within
+	// its limits.
+	ReasonEvicted
+	// ReasonDropped items have been dropped explicitly.
+	ReasonDropped
+	// ReasonInvalid items have failed validation.
+	ReasonInvalid
+	// ReasonDependency items have been dropped with a dependency, see
+	// DependOn.
+	ReasonDependency
+	// ReasonTransferred items have been moved to another cache, see
+	// TransferTo.
+	ReasonTransferred
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case ReasonExpired:
+		return "expired"
+	case ReasonEvicted:
+		return "evicted"
+	case ReasonDropped:
+		return "dropped"
+	case ReasonInvalid:
+		return "invalid"
+	case ReasonDependency:
+		return "dependency"
+	case ReasonTransferred:
+		return "transferred"
+	}
+	return "unknown"
+}
+
+// WithOnEvict sets a function to call with every item that leaves a
+// Cache of the same key and value types, and the reason it did, e.g.,
+// to release the resources held by the value. Putting a new value at
+// a key does not evict the old one.
+//
+// The function is called after the cache is unlocked, so it may use
+// the cache, by the goroutine that caused the item to leave it.
+//
+// New panics if the types do not match those of the cache.
+func WithOnEvict[K comparable, V any](f func(key K, value V, reason EvictReason)) Option {
+	return func(o *options) {
+		o.onEvict = f
+	}
+}
+
+// WithZeroTTLDrops makes putting an item with a time-to-live that is
+// not positive in a Cache drop the item at its key, if any, explicitly
+// (with ReasonDropped), instead of putting the new one to expire at
+// once, which passes it through the expiry timer to be dropped with
+// ReasonExpired.
+func WithZeroTTLDrops() Option {
+	return func(o *options) {
+		o.zeroDrops = true
+	}
+}
+
+// WithEntryValidator makes a Cache validate all its items every
+// interval, and drop the ones that validate reports false for, e.g.,
+// dead connections or revoked tokens, with ReasonInvalid.
+//
+// The cache is not locked while validate is called. Items modified
+// meanwhile are not dropped.
+//
+// New panics if the types do not match those of the cache.
+func WithEntryValidator[K comparable, V any](
+	interval time.Duration,
+	validate func(key K, value V) bool,
+) Option {
+	return func(o *options) {
+		o.validateEvery = interval
+		o.validate = validate
+	}
+}
+
+// Internals.
+
+// An eviction of an item to report to the OnEvict function.
+type eviction[K comparable, V any] struct {
+	k   K
+	v   V
+	r   EvictReason
+	ctx context.Context // Of the call that caused it, if given.
+}
+
+// onEvictOf returns the function set by WithOnEvict, or by
+// WithOnEvictContext.
+func onEvictOf[K comparable, V any](
+	o options,
+) func(context.Context, K, V, EvictReason) {
+	switch f := o.onEvict.(type) {
+	case nil:
+		return nil
+	case func(context.Context, K, V, EvictReason):
+		return f
+	case func(K, V, EvictReason):
+		return func(_ context.Context, k K, v V, r EvictReason) {
+			f(k, v, r)
+		}
+	}
+	panic("cache: WithOnEvict types do not match those of the cache")
+}
+
+// validatorOf returns the function set by WithEntryValidator.
+func validatorOf[K comparable, V any](o options) func(K, V) bool {
+	if o.validate == nil {
+		return nil
+	}
+	f, ok := o.validate.(func(K, V) bool)
+	if !ok {
+		panic("cache: WithEntryValidator types do not match those of the cache")
+	}
+	return f
+}
+
+// unlock the cache, publish its values for lock-free reads, if needed,
+// and report the evictions made while it was locked.
+func (c *Cache[K, V]) unlock() {
+	c.report(c.unlockOnly())
+}
+
+// unlockOnly unlocks the cache, publishing its values for lock-free
+// reads, if needed, and returns the evictions made while it was locked,
+// which are yet to be reported.
+func (c *Cache[K, V]) unlockOnly() []eviction[K, V] {
+	evicted := c.evicted
+	c.evicted = nil
+	c.ctx = nil
+	c.publish()
+	c.m.Unlock()
+	return evicted
+}
+
+// report the evicted items. The cache must not be locked.
+func (c *Cache[K, V]) report(evicted []eviction[K, V]) {
+	for _, e := range evicted {
+		ctx := e.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		c.onEvict(ctx, e.k, e.v, e.r)
+	}
+}
+
+// validate all items, dropping the invalid ones.
+func (c *Cache[K, V]) validateAll() {
+	type item struct {
+		k   K
+		v   V
+		gen uint64
+	}
+	c.lock()
+	items := make([]item, 0, len(c.d))
+	for k, val := range c.d {
+		items = append(items, item{k, val.Value(), val.gen})
+	}
+	c.m.Unlock()
+
+	var invalid []item
+	for _, it := range items {
+		if !c.validate(it.k, it.v) {
+			invalid = append(invalid, it)
+		}
+	}
+	if len(invalid) == 0 {
+		return
+	}
+	c.lock()
+	defer c.unlock()
+	for _, it := range invalid {
+		c.invalidate(it.k, it.gen)
+	}
+}
+
+// invalidate the item at key, unless modified after the gen generation.
+func (c *Cache[K, V]) invalidate(key K, gen uint64) {
+	if val, found := c.d[key]; found && val.gen == gen {
+		c.drop(key, val, ReasonInvalid)
+		c.stats.Invalid++
+	}
+}
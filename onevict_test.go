@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestOnEvict(t *testing.T) {
+	var (
+		m   sync.Mutex
+		got = map[string]EvictReason{}
+	)
+	var c *Cache[string, int]
+	c = New[string, int](time.Minute,
+		WithCapacity(4),
+		WithOnEvict(func(k string, v int, r EvictReason) {
+			// The cache is not locked.
+			c.Has(k)
+			m.Lock()
+			defer m.Unlock()
+			got[k] = r
+		}),
+	)
+	defer c.Shutdown()
+
+	c.PutWithTTL("expired", 1, ttl)
+	time.Sleep(3 * ttl / 2)
+	c.Put("dropped", 2)
+	c.Put("tagged", 3)
+	c.Tag("tagged", "t")
+	c.DropTag("t")
+	c.Drop("dropped")
+	c.Put("a", 4)
+	c.Put("b", 5)
+	c.PutWithTTL("evicted", 6, time.Second)
+	c.Put("c", 7)
+	c.Put("invalid", 8)
+	c.GetOrReload("invalid", func(int) bool { return false },
+		GetterFunc[string, int](func(string) (int, bool) { return 0, false }))
+	c.Put("a", 9) // Replacing is not evicting.
+
+	m.Lock()
+	defer m.Unlock()
+	want := map[string]EvictReason{
+		"expired": ReasonExpired,
+		"tagged":  ReasonDropped,
+		"dropped": ReasonDropped,
+		"evicted": ReasonEvicted,
+		"invalid": ReasonInvalid,
+	}
+	if len(got) != len(want) {
+		t.Errorf("evictions got=%v, want=%v", got, want)
+	}
+	for k, r := range want {
+		if got[k] != r {
+			t.Errorf("reason for %q got=%v, want=%v", k, got[k], r)
+		}
+	}
+	if s := c.Stats(); s.Expired != 1 || s.Evicted != 1 {
+		t.Errorf("Stats() got=%+v, want 1 expired and 1 evicted", s)
+	}
+}
+
+func TestEntryValidator(t *testing.T) {
+	evicted := make(chan string, 2)
+	c := New[string, int](time.Minute,
+		WithEntryValidator(ttl, func(k string, v int) bool { return v > 0 }),
+		WithOnEvict(func(k string, v int, r EvictReason) {
+			if r == ReasonInvalid {
+				evicted <- k
+			}
+		}),
+	)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", -1)
+	select {
+	case k := <-evicted:
+		req.Assert(k == "b", "evicted got=%q, want=%q", k, "b")
+	case <-time.After(time.Second):
+		t.Fatal("the invalid item has not been evicted")
+	}
+	req.Has("a")
+	req.HasNot("b")
+	req.Assert(c.Stats().Invalid == 1, "Invalid got=%d, want=1", c.Stats().Invalid)
+}
+
+func TestZeroTTLDrops(t *testing.T) {
+	var got []EvictReason
+	c := New[string, int](time.Minute,
+		WithZeroTTLDrops(),
+		WithOnEvict(func(k string, v int, r EvictReason) {
+			got = append(got, r)
+		}),
+	)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.PutWithTTL("a", 2, 0)
+	req.HasNot("a")
+	c.PutWithTTL("b", 3, -time.Second)
+	req.HasNot("b")
+	c.Put("c", 4, TTL(0), Pin()) // Pinned items do not expire.
+	req.Has("c")
+
+	if len(got) != 1 || got[0] != ReasonDropped {
+		t.Errorf("evictions got=%v, want=[dropped]", got)
+	}
+}
@@ -0,0 +1,57 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// An Option configures a Cache at construction.
+type Option func(*options)
+
+type options struct {
+	name         string
+	labels       map[string]string
+	clockSync    time.Duration
+	tracer       Tracer
+	zero         ZeroPolicy
+	negativeTTL  time.Duration
+	capacity     int
+	maxBytes     int64
+	sizer        any // Of func(K, V) int64.
+	ttlRules     any // Of []TTLRule[K].
+	ttlFunc      any // Of func(K, V) (time.Duration, bool).
+	transform    any // Of func(K, V) V.
+	onEvict      any // Of func([context.Context, ]K, V, EvictReason).
+	touch        TouchPolicy
+	noSlide      bool
+	noZeroKeys   bool
+	keyCheck     any // Of func(K) error.
+	lockFree     bool
+	contention   bool
+	classOf      any // Of func(K, V) string.
+	budgets      map[string]int64
+	cardWindow   time.Duration
+	cardHash     any // Of func(K) uint64.
+	lazyExpiry   bool
+	maxLag       time.Duration
+	strictExpiry bool
+	quotaPeriod  time.Duration
+	quotas       map[string]int
+	refreshers   int
+	eviction     EvictionPolicy
+	zeroDrops    bool
+	replay       int
+	digest       any // Of func(V) string.
+	coldAfter    time.Duration
+	compress     any // Of func(V) ([]byte, bool).
+	decompress   any // Of func([]byte) V.
+	hotItems     int
+	chaos        *chaos
+
+	checkEvery    time.Duration
+	reportCorrupt func(error)
+
+	validateEvery time.Duration
+	validate      any // Of func(K, V) bool.
+}
@@ -0,0 +1,50 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Origin records the component putting the item by the call, e.g., the
+// name of a subsystem, so that, in a shared cache, the ones that fill
+// the key space can be traced, see GetEntry.
+func Origin(origin string) CallOption {
+	return func(o *callOptions) {
+		o.origin = origin
+	}
+}
+
+// ContextWithOrigin returns a copy of ctx that carries origin, which
+// PutContext and GetOrPutContext record as that of the values they put,
+// see Origin.
+func ContextWithOrigin(ctx context.Context, origin string) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFromContext returns the origin carried by ctx, if any, see
+// ContextWithOrigin.
+func OriginFromContext(ctx context.Context) string {
+	origin, _ := ctx.Value(originKey{}).(string)
+	return origin
+}
+
+// GetEntry returns a description of a cached item, including its
+// origin, without extending its lifetime.
+func (c *Cache[K, V]) GetEntry(key K) (e Entry[K, V], ok bool) {
+	c.lock()
+	defer c.unlock()
+	val, found := c.find(key, false)
+	if !found {
+		return
+	}
+	return val.describe(key, time.Now()), true
+}
+
+// Internals.
+
+// originKey is the key of the origin in a context.Context.
+type originKey struct{}
@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestOrigin(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	ctx := ContextWithOrigin(context.Background(), "sessions")
+
+	c.Put("a", 1, Origin("billing"))
+	c.PutContext(ctx, "b", 2)
+	c.GetOrPutContext(ctx, "c", SimpleGetterFunc[string, int](func() int {
+		return 3
+	}))
+	c.Put("d", 4)
+	for k, want := range map[string]string{
+		"a": "billing",
+		"b": "sessions",
+		"c": "sessions",
+		"d": "",
+	} {
+		if e, ok := c.GetEntry(k); !ok || e.Origin != want || e.Key != k {
+			t.Errorf("GetEntry(%s) got=%+v,%v, want origin %q", k, e, ok, want)
+		}
+	}
+	c.Put("a", 5)
+	if e, _ := c.GetEntry("a"); e.Origin != "" || e.Value != 5 {
+		t.Errorf("GetEntry(a) after Put got=%+v, want no origin", e)
+	}
+	if _, ok := c.GetEntry("x"); ok {
+		t.Error("GetEntry(x) should not find 'x'")
+	}
+}
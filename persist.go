@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Internals.
+
+// interval returns the interval of the snapshots persisting a cache to
+// cfg.Path, if set.
+func (cfg SnapshotConfig) interval() (time.Duration, error) {
+	if cfg.Path == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(cfg.Every)
+	if err != nil {
+		return 0, fmt.Errorf("cache: config snapshot every: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("cache: config snapshot every %v not positive", d)
+	}
+	return d, nil
+}
+
+// persist writes snapshots of c, configured by cfg, to the files at
+// path every interval until it is shut down, counting the failed ones
+// in Stats.Unsaved.
+func persist[K comparable, V any](
+	c *Cache[K, V],
+	cfg SnapshotConfig,
+	path string,
+	every time.Duration,
+) {
+	s := NewSnapshotter(c, cfg)
+	c.on(Every(every), func() {
+		if err := s.dumpFiles(path); err != nil {
+			c.lock()
+			c.stats.Unsaved++
+			c.m.Unlock()
+		}
+	})
+}
+
+// restoreShards restores s from the files that persistShards writes at
+// path. The items restored are put in the shards their keys are
+// assigned to now, so that the number of shards can change between the
+// runs.
+func restoreShards[K comparable, V any](s *Sharded[K, V], path string) error {
+	for i := 0; ; i++ {
+		p := shardPath(path, i)
+		if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		restored := New[K, V](time.Minute)
+		err := restored.restoreFiles(p)
+		for _, shard := range s.shards {
+			if err == nil {
+				_, err = restored.TransferTo(shard, func(k K, _ V) bool {
+					return s.Shard(k) == shard
+				})
+			}
+		}
+		restored.Shutdown()
+		if err != nil {
+			return err
+		}
+		if i >= len(s.shards) {
+			// Of a shard that is gone, with its items moved to others.
+			if err := removeFiles(p, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// persistShards persists each shard of s like persist does, to the
+// files at path followed by a hyphen and the index of the shard.
+func persistShards[K comparable, V any](
+	s *Sharded[K, V],
+	cfg SnapshotConfig,
+	path string,
+	every time.Duration,
+) {
+	for i, shard := range s.shards {
+		persist(shard, cfg, shardPath(path, i), every)
+	}
+}
+
+// shardPath returns the path of the files of the shard with index i.
+func shardPath(path string, i int) string {
+	return path + "-" + strconv.Itoa(i)
+}
+
+// snapshotPath returns the path of the file of the snapshot with index
+// i in a cycle, of the full one, if zero.
+func snapshotPath(path string, i int) string {
+	if i == 0 {
+		return path
+	}
+	return path + "." + strconv.Itoa(i)
+}
+
+// restoreFiles restores c from the full snapshot in the file at path,
+// if any, and the incremental ones taken after it.
+func (c *Cache[K, V]) restoreFiles(path string) error {
+	for i := 0; ; i++ {
+		name := snapshotPath(path, i)
+		err := c.RestoreFrom(func() (io.ReadCloser, error) {
+			return os.Open(name)
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cache: restore %s: %w", name, err)
+		}
+	}
+}
+
+// dumpFiles writes the next snapshot in the cycle to the file for it at
+// path, see snapshotPath, through a temporary file, renamed once the
+// snapshot is written, dropping the incremental snapshots of the
+// previous cycle before a full one replaces theirs.
+func (s *Snapshotter[K, V]) dumpFiles(path string) error {
+	i := 0
+	if s.cfg.FullEvery > 1 {
+		i = s.n % s.cfg.FullEvery
+	}
+	name := snapshotPath(path, i)
+	f, err := os.Create(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	at, err := s.write(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil && i == 0 {
+		err = removeFiles(path, 1)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	s.commit(at)
+	return nil
+}
+
+// removeFiles removes the files of the snapshots in a cycle at path,
+// from the one with index i on.
+func removeFiles(path string, i int) error {
+	for ; ; i++ {
+		err := os.Remove(snapshotPath(path, i))
+		if errors.Is(err, fs.ErrNotExist) && i > 0 {
+			return nil
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// Replaced summarizes the item replaced by a put, see PutEx.
+type Replaced struct {
+	Replaced  bool          // Whether there was an item at the key.
+	Remaining time.Duration // Its remaining lifetime; zero if pinned.
+	Pinned    bool
+}
+
+// PutEx puts a value in cache at the given key, with the given
+// time-to-live, like PutWithTTL does, and summarizes the item it has
+// replaced, if any, e.g., to detect unexpected overwrites, or measure
+// churn. An error is returned if the value has not been put, like PutE
+// does.
+func (c *Cache[K, V]) PutEx(key K, value V, ttl time.Duration) (Replaced, error) {
+	if c.checkKey != nil {
+		if err := c.checkKey(key); err != nil {
+			return Replaced{}, err
+		}
+	}
+	c.lock()
+	defer c.unlock()
+	now := time.Now()
+	var r Replaced
+	if val, found := c.d[key]; found && val.t.x.After(now) {
+		r.Replaced = true
+		r.Pinned = val.pin
+		if !val.pin {
+			r.Remaining = val.t.x.Sub(now)
+		}
+	}
+	if err := c.set(key, value, callOptions{ttl: ttl, ttlSet: true}); err != nil {
+		return Replaced{}, err
+	}
+	return r, nil
+}
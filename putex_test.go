@@ -0,0 +1,38 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestPutEx(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+
+	if r, err := c.PutEx("a", 1, time.Hour); err != nil || r.Replaced {
+		t.Errorf("PutEx(a) got=%+v, %v, want nothing replaced", r, err)
+	}
+	r, err := c.PutEx("a", 2, time.Minute)
+	if err != nil || !r.Replaced || r.Remaining <= time.Minute || r.Remaining > time.Hour {
+		t.Errorf("PutEx(a) got=%+v, %v, want about an hour remaining", r, err)
+	}
+	if v, _ := c.Get("a"); v != 2 {
+		t.Errorf("Get(a) got=%v, want=2", v)
+	}
+
+	c.Put("b", 1, Pin())
+	if r, _ := c.PutEx("b", 2, time.Minute); !r.Replaced || !r.Pinned || r.Remaining != 0 {
+		t.Errorf("PutEx(b) got=%+v, want a pinned item replaced", r)
+	}
+
+	c.Quiesce()
+	if r, err := c.PutEx("a", 3, time.Minute); err != ErrQuiesced || r.Replaced {
+		t.Errorf("PutEx(a) in a quiesced cache got=%+v, %v, want=%v", r, err, ErrQuiesced)
+	}
+}
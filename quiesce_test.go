@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestQuiesce(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.PutWithTTL("short", 2, ttl)
+	var buf bytes.Buffer
+	if err := c.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+
+	c.Quiesce()
+	req.Assert(c.IsQuiesced(), "should be quiesced")
+	c.Put("a", 10)
+	c.Put("b", 2)
+	v := req.GetOrPut("c", SimpleGetterFunc[string, int](func() int { return 3 }))
+	req.Assert(v == 3, "GetOrPut(c) got=%d, want=3", v)
+	req.Assert(req.Get("a") == 1, "Get(a) should still be 1")
+	req.HasNot("b")
+	req.HasNot("c")
+	if err := c.Restore(&buf); !errors.Is(err, ErrQuiesced) {
+		t.Errorf("Restore() error: got=%v, want=%v", err, ErrQuiesced)
+	}
+
+	// Items keep expiring and can be dropped.
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("short")
+	c.Drop("a")
+	req.HasNot("a")
+
+	c.Resume()
+	req.AssertNot(c.IsQuiesced(), "should not be quiesced")
+	c.Put("b", 2)
+	req.Has("b")
+}
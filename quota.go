@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/antichris/go-cache/internal/window"
+)
+
+// A QuotaError is returned when putting a value in a cache from an
+// origin that has exceeded its write quota, see WithWriteQuotas.
+type QuotaError struct {
+	Origin string // Of the write rejected, see Origin.
+}
+
+func (e *QuotaError) Error() string {
+	return "cache: write quota of origin " + strconv.Quote(e.Origin) +
+		" exceeded"
+}
+
+// WithWriteQuotas limits the number of values put in a Cache per period
+// by each origin, see Origin, so that a misbehaving component cannot
+// churn the whole of a shared cache. The limit at the empty origin
+// applies to each of the origins not listed, separately, and to the
+// writes without one. Origins without a limit are not limited.
+//
+// Writes over the limits are counted in Stats.Throttled. Values are not
+// put, which PutE and PutWithTTLE report with a QuotaError, and those
+// loaded by GetOrPut and PutWithProvider are returned without being
+// put. The items bound to providers expire when their refreshes are
+// over the limits.
+func WithWriteQuotas(per time.Duration, limits map[string]int) Option {
+	return func(o *options) {
+		o.quotaPeriod = per
+		o.quotas = limits
+	}
+}
+
+// Internals.
+
+// allowWrite returns whether origin may put a value as of now, counting
+// it towards its quota, if so, or the write throttled, if not. The
+// cache must be locked.
+func (c *Cache[K, V]) allowWrite(origin string, now time.Time) bool {
+	if c.o.quotas == nil {
+		return true
+	}
+	limit, ok := c.o.quotas[origin]
+	if !ok {
+		limit, ok = c.o.quotas[""]
+	}
+	if !ok {
+		return true
+	}
+	per := c.o.quotaPeriod
+	if c.writes == nil {
+		c.writes = make(map[string]*window.Window)
+		c.pruned = now
+	}
+	if now.Sub(c.pruned) >= per {
+		c.pruneWrites(now)
+	}
+	w := c.writes[origin]
+	if w == nil {
+		w = &window.Window{}
+		c.writes[origin] = w
+	}
+	if !w.Add(now, per, limit, 1) {
+		c.stats.Throttled++
+		return false
+	}
+	return true
+}
+
+// pruneWrites forgets the writes of the origins that have not put any
+// values for two periods, so that the windows of origins that are gone
+// do not accumulate. The cache must be locked.
+func (c *Cache[K, V]) pruneWrites(now time.Time) {
+	for origin, w := range c.writes {
+		if w.Idle(now, c.o.quotaPeriod) {
+			delete(c.writes, origin)
+		}
+	}
+	c.pruned = now
+}
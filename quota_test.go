@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithWriteQuotas(t *testing.T) {
+	c := New[int, int](time.Minute, WithWriteQuotas(time.Hour, map[string]int{
+		"":      2,
+		"batch": 1,
+	}))
+	defer c.Shutdown()
+
+	if err := c.PutE(1, 1, Origin("batch")); err != nil {
+		t.Fatalf("PutE() error: %v", err)
+	}
+	err := c.PutE(2, 2, Origin("batch"))
+	var qe *QuotaError
+	if !errors.As(err, &qe) || qe.Origin != "batch" {
+		t.Errorf("PutE() over quota got=%v, want a QuotaError of batch", err)
+	}
+	// Other origins have quotas of their own.
+	for i := 0; i < 2; i++ {
+		if err := c.PutE(10+i, i, Origin("web")); err != nil {
+			t.Errorf("PutE() from web error: %v", err)
+		}
+		if err := c.PutE(20+i, i); err != nil {
+			t.Errorf("PutE() without an origin error: %v", err)
+		}
+	}
+	c.Put(30, 3)
+	if _, ok := c.GetOrPut(31, SimpleGetterFunc[int, int](func() int {
+		return 3
+	})); !ok {
+		t.Error("GetOrPut() over quota should return the value loaded")
+	}
+
+	if c.Has(2) || c.Has(30) || c.Has(31) {
+		t.Error("values over quota should not be put")
+	}
+	if n := c.Stats().Throttled; n != 3 {
+		t.Errorf("Stats().Throttled got=%d, want=3", n)
+	}
+}
+
+func TestWriteQuotasPruned(t *testing.T) {
+	c := New[int, int](time.Minute,
+		WithWriteQuotas(ttl, map[string]int{"": 10}))
+	defer c.Shutdown()
+
+	for i := 0; i < 100; i++ {
+		c.Put(i, i, Origin(strconv.Itoa(i)))
+	}
+	if n := c.QuotaOrigins(); n != 100 {
+		t.Fatalf("QuotaOrigins() got=%d, want=100", n)
+	}
+	time.Sleep(2 * ttl)
+	c.Put(0, 0, Origin("late"))
+	if n := c.QuotaOrigins(); n != 1 {
+		t.Errorf("QuotaOrigins() after the others idled got=%d, want=1", n)
+	}
+}
+
+func TestWriteQuotasPutWithProvider(t *testing.T) {
+	c := New[string, int](time.Minute,
+		WithWriteQuotas(time.Hour, map[string]int{"": 2}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	g := SimpleGetterFunc[string, int](func() int { return 1 })
+	c.PutWithProvider("a", g, ttl)
+	c.Put("b", 1)
+	if v, ok := c.PutWithProvider("c", g, ttl); !ok || v != 1 {
+		t.Errorf("PutWithProvider() over quota got=%v,%v, want the value loaded", v, ok)
+	}
+	req.Has("a")
+	req.HasNot("c")
+
+	// The refresh of "a" is over the quota too.
+	time.Sleep(3 * ttl)
+	req.HasNot("a")
+	if n := c.Stats().Throttled; n != 2 {
+		t.Errorf("Stats().Throttled got=%d, want=2", n)
+	}
+}
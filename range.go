@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "errors"
+
+// ErrModified is returned by Range when the cache is modified while it
+// is being iterated over.
+var ErrModified = errors.New("cache: modified during iteration")
+
+// Range calls f for each item in the cache, in no particular order,
+// until f returns false.
+//
+// The cache is only locked while a chunk of items is being read from
+// it, not while f is called, so f may use the cache. Range either
+// visits the items as they were when it was called, or, if the cache
+// is modified in between (which includes items being put, dropped and
+// expiring), stops and returns ErrModified.
+func (c *Cache[K, V]) Range(f func(key K, value V) bool) error {
+	c.lock()
+	gen := c.gen
+	keys := make([]K, 0, len(c.d))
+	for k := range c.d {
+		keys = append(keys, k)
+	}
+	c.m.Unlock()
+
+	values := make([]V, 0, rangeChunk)
+	for len(keys) > 0 {
+		n := rangeChunk
+		if n > len(keys) {
+			n = len(keys)
+		}
+		values = values[:0]
+		c.lock()
+		if c.gen != gen {
+			c.m.Unlock()
+			return ErrModified
+		}
+		for _, k := range keys[:n] {
+			values = append(values, c.d[k].Value())
+		}
+		c.m.Unlock()
+
+		for i, v := range values {
+			if v, _ = c.read(keys[i], v, true); !f(keys[i], v) {
+				return nil
+			}
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+// Walk calls f for each item in the cache, in no particular order,
+// until f returns false, like Range does, but without ever stopping
+// due to the cache being modified meanwhile, e.g., to iterate over a
+// huge cache that is being written to.
+//
+// The cache is only locked while a chunk of items is being read from
+// it, not while f is called, so f may use the cache. Each item that is
+// in the cache for the entire duration of the walk is visited once,
+// with its value at the time its chunk was read. Items that are put or
+// dropped during the walk may or may not be visited.
+func (c *Cache[K, V]) Walk(f func(key K, value V) bool) {
+	keys := make([]K, 0, rangeChunk)
+	values := make([]V, 0, rangeChunk)
+	c.lock()
+	for k, val := range c.d {
+		keys, values = append(keys, k), append(values, val.Value())
+		if len(keys) < rangeChunk {
+			continue
+		}
+		c.unlock()
+		if !c.visit(keys, values, f) {
+			return
+		}
+		keys, values = keys[:0], values[:0]
+		// The iteration over the map resumes where it stopped, with the
+		// same guarantees as if it were modified by f.
+		c.lock()
+	}
+	c.unlock()
+	c.visit(keys, values, f)
+}
+
+// All returns an iterator over the items in the cache that walks it
+// like Walk does. It is assignable to iter.Seq2[K, V], so, in Go 1.23
+// and later, the cache can be ranged over with
+//
+//	for k, v := range c.All() {
+//		// ...
+//	}
+//
+// without the items being copied to a slice first.
+func (c *Cache[K, V]) All() func(yield func(key K, value V) bool) {
+	return c.Walk
+}
+
+// KeySeq returns an iterator over the keys of the items in the cache,
+// like All does, assignable to iter.Seq[K].
+func (c *Cache[K, V]) KeySeq() func(yield func(key K) bool) {
+	return keySeq(c.Walk)
+}
+
+// Internals.
+
+// visit the items with keys and values with f, until it returns false,
+// and return whether it has not.
+func (c *Cache[K, V]) visit(keys []K, values []V, f func(K, V) bool) bool {
+	for i, v := range values {
+		if v, _ = c.read(keys[i], v, true); !f(keys[i], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeChunk is the number of items Range reads while holding the lock.
+const rangeChunk = 256
+
+// keySeq returns an iterator over the keys of the items that walk
+// walks.
+func keySeq[K comparable, V any](
+	walk func(func(K, V) bool),
+) func(yield func(K) bool) {
+	return func(yield func(K) bool) {
+		walk(func(k K, _ V) bool { return yield(k) })
+	}
+}
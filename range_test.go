@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestRange(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	const n = 1000
+	for i := 0; i < n; i++ {
+		c.Put(i, 2*i)
+	}
+
+	seen := map[int]bool{}
+	err := c.Range(func(k, v int) bool {
+		if v != 2*k {
+			t.Errorf("Range visited %d: %d, want %d", k, v, 2*k)
+		}
+		seen[k] = true
+		c.Get(k) // Reads do not interfere.
+		return true
+	})
+	if err != nil || len(seen) != n {
+		t.Errorf("Range() visited %d items, error: %v, want %d, nil",
+			len(seen), err, n)
+	}
+
+	visited := 0
+	err = c.Range(func(int, int) bool {
+		visited++
+		return visited < 10
+	})
+	if err != nil || visited != 10 {
+		t.Errorf("Range() stopped after %d, error: %v, want 10, nil",
+			visited, err)
+	}
+}
+
+func TestRangeModified(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	for i := 0; i < 1000; i++ {
+		c.Put(i, i)
+	}
+
+	err := c.Range(func(k, _ int) bool {
+		c.Drop(k)
+		return true
+	})
+	if !errors.Is(err, ErrModified) {
+		t.Errorf("Range() error: got=%v, want=%v", err, ErrModified)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	const n = 1000
+	for i := 0; i < n; i++ {
+		c.Put(i, 2*i)
+	}
+
+	seen := map[int]int{}
+	c.Walk(func(k, v int) bool {
+		if k < n && v != 2*k {
+			t.Errorf("Walk visited %d: %d, want %d", k, v, 2*k)
+		}
+		seen[k]++
+		c.Put(n+k, 0) // Writes do not stop the walk.
+		if k%2 == 0 {
+			c.Drop(k)
+		}
+		return true
+	})
+	for i := 0; i < n; i++ {
+		if seen[i] != 1 {
+			t.Errorf("Walk visited %d %d times, want once", i, seen[i])
+		}
+	}
+
+	visited := 0
+	c.Walk(func(int, int) bool {
+		visited++
+		return visited < 10
+	})
+	if visited != 10 {
+		t.Errorf("Walk stopped after %d, want 10", visited)
+	}
+}
+
+func TestAll(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	for i := 0; i < 3; i++ {
+		c.Put(i, 2*i)
+	}
+
+	// Called like the range statement does in Go 1.23 and later.
+	got := map[int]int{}
+	c.All()(func(k, v int) bool {
+		got[k] = v
+		return true
+	})
+	if len(got) != 3 || got[1] != 2 || got[2] != 4 {
+		t.Errorf("All() yielded %v", got)
+	}
+
+	keys := 0
+	c.KeySeq()(func(k int) bool {
+		keys++
+		return false
+	})
+	if keys != 1 {
+		t.Errorf("KeySeq() yielded %d keys after stopping, want 1", keys)
+	}
+}
@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package ratelimit implements per-key rate limiting on a cache.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/antichris/go-cache"
+	"github.com/antichris/go-cache/internal/window"
+)
+
+// A Limiter allows up to a number of events per period for each key,
+// counted in a sliding window.
+//
+// The window is approximated from the counts of the current and the
+// previous fixed periods, weighing the latter by how much of it the
+// window still covers. The counters of keys without events for two
+// periods expire.
+type Limiter[K comparable] struct {
+	c     *cache.Cache[K, *counter]
+	limit int
+	per   time.Duration
+}
+
+// New Limiter of limit events per period.
+func New[K comparable](limit int, per time.Duration) *Limiter[K] {
+	return &Limiter[K]{
+		c:     cache.New[K, *counter](2 * per),
+		limit: limit,
+		per:   per,
+	}
+}
+
+// Allow reports whether an event for key is allowed now, counting it
+// if so.
+func (l *Limiter[K]) Allow(key K) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events for key are allowed now, counting
+// them if so.
+func (l *Limiter[K]) AllowN(key K, n int) bool {
+	w, _ := l.c.GetOrPut(key, cache.SimpleGetterFunc[K, *counter](
+		func() *counter { return &counter{} },
+	))
+	return w.add(time.Now(), l.per, l.limit, n)
+}
+
+// Shutdown the underlying cache.
+func (l *Limiter[K]) Shutdown() {
+	l.c.Shutdown()
+}
+
+// Internals.
+
+// A counter of the events for a key, in a sliding window.
+type counter struct {
+	m sync.Mutex
+	w window.Window
+}
+
+// add n events at now, unless that would exceed limit in the sliding
+// window of period per, and return whether they have been added.
+func (c *counter) add(now time.Time, per time.Duration, limit, n int) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.w.Add(now, per, limit, n)
+}
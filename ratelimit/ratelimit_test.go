@@ -0,0 +1,69 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package ratelimit_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache/ratelimit"
+)
+
+func TestAllow(t *testing.T) {
+	const per = 50 * time.Millisecond
+	l := New[string](3, per)
+	defer l.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow("a") {
+			t.Fatalf("Allow(a) #%d should be allowed", i)
+		}
+	}
+	if l.Allow("a") {
+		t.Error("Allow(a) over the limit should not be allowed")
+	}
+	if !l.Allow("b") {
+		t.Error("Allow(b) should be allowed, keys are limited separately")
+	}
+	if l.AllowN("b", 3) {
+		t.Error("AllowN(b, 3) over the limit should not be allowed")
+	}
+
+	// The window slides over the previous period.
+	time.Sleep(per + per/10)
+	if l.Allow("a") {
+		t.Error("Allow(a) early in the next period should not be allowed")
+	}
+	time.Sleep(2 * per)
+	if !l.Allow("a") {
+		t.Error("Allow(a) after the window has passed should be allowed")
+	}
+}
+
+func TestAllowConcurrent(t *testing.T) {
+	l := New[int](100, time.Minute)
+	defer l.Shutdown()
+	var (
+		wg      sync.WaitGroup
+		allowed int32
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if l.Allow(1) {
+					atomic.AddInt32(&allowed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 100 {
+		t.Errorf("allowed got=%d, want=100", allowed)
+	}
+}
@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// An OpKind is the kind of an Op.
+type OpKind int
+
+const (
+	// OpGet is a Get.
+	OpGet OpKind = iota
+	// OpHas is a Has.
+	OpHas
+	// OpPut is a PutWithTTLE.
+	OpPut
+	// OpDrop is a DropE.
+	OpDrop
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpGet:
+		return "get"
+	case OpHas:
+		return "has"
+	case OpPut:
+		return "put"
+	case OpDrop:
+		return "drop"
+	}
+	return "unknown"
+}
+
+// An Op is an operation recorded by a RecordingCache.
+type Op[K comparable, V any] struct {
+	Kind  OpKind
+	Key   K
+	Value V             // Put, or found by Get.
+	TTL   time.Duration // Of Put.
+	OK    bool          // Whether Get or Has found the item.
+	Err   error         // Returned by Put or Drop.
+}
+
+var _ Interface[int, any] = (*RecordingCache[int, any])(nil)
+
+// A RecordingCache records the operations on the cache it wraps, e.g.,
+// for tests to make assertions about. It is safe for concurrent use.
+type RecordingCache[K comparable, V any] struct {
+	c   Interface[K, V]
+	m   sync.Mutex
+	ops []Op[K, V]
+}
+
+// NewRecording returns a new RecordingCache wrapping c.
+func NewRecording[K comparable, V any](c Interface[K, V]) *RecordingCache[K, V] {
+	return &RecordingCache[K, V]{c: c}
+}
+
+// Get the value of a cached item.
+func (r *RecordingCache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok = r.c.Get(key)
+	r.record(Op[K, V]{Kind: OpGet, Key: key, Value: value, OK: ok})
+	return
+}
+
+// Has returns whether an item for given key is present in the cache.
+func (r *RecordingCache[K, V]) Has(key K) bool {
+	ok := r.c.Has(key)
+	r.record(Op[K, V]{Kind: OpHas, Key: key, OK: ok})
+	return ok
+}
+
+// PutWithTTLE puts a value in the cache with the given time-to-live.
+func (r *RecordingCache[K, V]) PutWithTTLE(key K, value V, ttl time.Duration) error {
+	err := r.c.PutWithTTLE(key, value, ttl)
+	r.record(Op[K, V]{Kind: OpPut, Key: key, Value: value, TTL: ttl, Err: err})
+	return err
+}
+
+// DropE drops the item at key.
+func (r *RecordingCache[K, V]) DropE(key K) error {
+	err := r.c.DropE(key)
+	r.record(Op[K, V]{Kind: OpDrop, Key: key, Err: err})
+	return err
+}
+
+// Ops returns the operations recorded, in order.
+func (r *RecordingCache[K, V]) Ops() []Op[K, V] {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return append([]Op[K, V](nil), r.ops...)
+}
+
+// Reset forgets the operations recorded.
+func (r *RecordingCache[K, V]) Reset() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.ops = nil
+}
+
+// Internals.
+
+func (r *RecordingCache[K, V]) record(op Op[K, V]) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.ops = append(r.ops, op)
+}
@@ -0,0 +1,48 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestNullCache(t *testing.T) {
+	var c Interface[string, int] = NullCache[string, int]{}
+	if err := c.PutWithTTLE("a", 1, ttl); err != nil {
+		t.Fatalf("PutWithTTLE() error: %v", err)
+	}
+	if _, ok := c.Get("a"); ok || c.Has("a") {
+		t.Error("should not have 'a'")
+	}
+}
+
+func TestRecordingCache(t *testing.T) {
+	c := New[string, int](ttl)
+	defer c.Shutdown()
+	r := NewRecording[string, int](c)
+
+	r.PutWithTTLE("a", 1, ttl)
+	r.Get("a")
+	r.Get("b")
+	r.Has("a")
+	r.DropE("a")
+	want := []Op[string, int]{
+		{Kind: OpPut, Key: "a", Value: 1, TTL: ttl},
+		{Kind: OpGet, Key: "a", Value: 1, OK: true},
+		{Kind: OpGet, Key: "b"},
+		{Kind: OpHas, Key: "a", OK: true},
+		{Kind: OpDrop, Key: "a"},
+	}
+	if got := r.Ops(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ops() got=%+v, want=%+v", got, want)
+	}
+	r.Reset()
+	if got := r.Ops(); len(got) != 0 {
+		t.Errorf("Ops() after Reset() got=%+v", got)
+	}
+}
@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// PutWithProvider puts the value returned by provider in cache at the
+// given key, with the given time-to-live, binding provider to the item,
+// which is then refreshed with a new value from it whenever it expires,
+// rather than dropped, keeping it perpetually warm. The old value is
+// served while the new one is loaded, with the expiry it is past, which
+// lookups do not extend. Should provider fail to return a value, the
+// item expires.
+//
+// Putting a new value at the key unbinds provider.
+//
+// The value, and each new one, is put without an origin, counting
+// towards its write quota, if limited, see WithWriteQuotas. A new value
+// over the quota is not put, and the item expires.
+func (c *Cache[K, V]) PutWithProvider(
+	key K,
+	provider Getter[K, V],
+	ttl time.Duration,
+) (value V, ok bool) {
+	start := time.Now()
+	value, ok = provider.Get(key)
+	c.lock()
+	defer c.unlock()
+	c.stats.load(time.Since(start))
+	if !ok || c.quiet || !c.keyOK(key) {
+		return
+	}
+	now := time.Now()
+	if !c.allowWrite("", now) {
+		return
+	}
+	val, found := c.d[key]
+	val.v = value
+	val.cold = nil
+	val.lease = nil
+	val.costSet = false
+	val.ttl = ttl
+	val.pin = false
+	val.fixed = false
+	val.put = now
+	val.limit = time.Time{}
+	val.origin = ""
+	val.provider = provider
+	if x := now.Add(ttl); found {
+		c.resetTimerAt(val.t, x)
+		val.t.hits = 0
+	} else {
+		val.t = c.addTimerAt(key, x)
+	}
+	c.dedup(&val)
+	c.emit(EventPut, key, value, 0)
+	c.store(key, val)
+	c.dropDependents(key)
+	return c.read(key, value, true)
+}
+
+// WithRefreshConcurrency limits the number of the items bound to
+// providers, see PutWithProvider, that a Cache refreshes concurrently
+// to n. Items that expire while n refreshes are in flight are queued,
+// and refreshed in the order of the number of lookups that found them
+// since they were last put or refreshed, most first, then of their
+// expiry, soonest first, so that the most valuable items are renewed
+// first.
+func WithRefreshConcurrency(n int) Option {
+	return func(o *options) {
+		o.refreshers = n
+	}
+}
+
+// Internals.
+
+// refresh the item val at key, which has expired, with a new value from
+// its provider, in a new goroutine, serving the old value meanwhile,
+// or queue it, if refreshing as many items as allowed already. Its
+// timer t must have been popped off the heap. The cache must be locked.
+func (c *Cache[K, V]) refresh(key K, val entry[K, V], t *itemTimer[K]) {
+	job := &refreshJob[K, V]{k: key, val: val, hits: t.hits, x: t.x}
+	// Off the heap, so that it neither expires, nor is refreshed again,
+	// until rescheduled once refreshed, while its expiry stays as it was.
+	t.refreshing = true
+	t.hits = 0
+	c.reranked(t)
+	if c.o.refreshers > 0 && c.refreshing >= c.o.refreshers {
+		heap.Push(&c.refreshQ, job)
+		return
+	}
+	c.refreshing++
+	go c.reload(job)
+}
+
+// reload the item of job with a new value from its provider, unless
+// replaced meanwhile, then start the next refresh queued, if any.
+func (c *Cache[K, V]) reload(job *refreshJob[K, V]) {
+	key, val := job.k, job.val
+	start := time.Now()
+	v, ok := val.provider.Get(key)
+	c.lock()
+	defer c.unlock()
+	c.stats.load(time.Since(start))
+	defer c.next()
+	cur, found := c.d[key]
+	if !found || replaced(cur, val) {
+		// The put rescheduled it, if it did not drop it.
+		return
+	}
+	if !ok || !c.allowWrite(cur.origin, time.Now()) {
+		c.drop(key, cur, ReasonExpired)
+		c.stats.Expired++
+		return
+	}
+	cur.v = v
+	cur.cold = nil
+	cur.costSet = false
+	cur.put = time.Now()
+	c.resetTimer(cur.t, cur.ttl)
+	c.dedup(&cur)
+	c.emit(EventPut, key, v, 0)
+	c.store(key, cur)
+	c.dropDependents(key)
+}
+
+// next starts the next refresh queued for an item that has not been
+// replaced since, if any, in place of one that has completed. The cache
+// must be locked.
+func (c *Cache[K, V]) next() {
+	for c.refreshQ.Len() > 0 {
+		job := heap.Pop(&c.refreshQ).(*refreshJob[K, V])
+		if cur, found := c.d[job.k]; found && !replaced(cur, job.val) {
+			go c.reload(job)
+			return
+		}
+	}
+	c.refreshing--
+}
+
+// replaced returns whether the item cur has had a new value put since
+// it was val, unbinding its provider, rather than only being modified
+// otherwise, e.g., tagged, or refreshed.
+func replaced[K comparable, V any](cur, val entry[K, V]) bool {
+	return cur.provider == nil || !cur.put.Equal(val.put)
+}
+
+// A refreshJob is a refresh of an item, queued.
+type refreshJob[K comparable, V any] struct {
+	k    K
+	val  entry[K, V] // As of when it expired.
+	hits uint32      // Lookups that found the item since last put.
+	x    time.Time   // Expiry time.
+}
+
+// A refreshQueue is a heap of refreshJobs, with the most valuable first.
+type refreshQueue[K comparable, V any] []*refreshJob[K, V]
+
+func (q refreshQueue[_, _]) Len() int {
+	return len(q)
+}
+
+func (q refreshQueue[_, _]) Less(i, j int) bool {
+	if q[i].hits != q[j].hits {
+		return q[i].hits > q[j].hits
+	}
+	return q[i].x.Before(q[j].x)
+}
+
+func (q refreshQueue[_, _]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *refreshQueue[K, V]) Push(v any) {
+	*q = append(*q, v.(*refreshJob[K, V]))
+}
+
+func (q *refreshQueue[_, _]) Pop() any {
+	old := *q
+	n := len(old) - 1
+	job := old[n]
+	old[n] = nil
+	*q = old[:n]
+	return job
+}
@@ -0,0 +1,183 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestPutWithProvider(t *testing.T) {
+	c := New[string, int32](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	var n, fail int32
+	g := GetterFunc[string, int32](func(string) (int32, bool) {
+		return atomic.AddInt32(&n, 1), atomic.LoadInt32(&fail) == 0
+	})
+	if v, ok := c.PutWithProvider("a", g, 2*ttl); !ok || v != 1 {
+		t.Fatalf("PutWithProvider(a) got=%v,%v, want=1,true", v, ok)
+	}
+	time.Sleep(5 * ttl)
+	if v := req.Get("a"); v < 2 {
+		t.Fatalf("Get(a) got=%v, want a refreshed value", v)
+	}
+
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(3 * ttl)
+	req.HasNot("a")
+
+	// Putting a value unbinds the provider.
+	atomic.StoreInt32(&fail, 0)
+	c.PutWithProvider("b", g, ttl)
+	c.PutWithTTL("b", 0, ttl)
+	time.Sleep(3 * ttl)
+	req.HasNot("b")
+}
+
+func TestRefreshModified(t *testing.T) {
+	c := New[string, int32](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	var n int32
+	loading := make(chan struct{}, 1)
+	release := make(chan struct{})
+	g := GetterFunc[string, int32](func(string) (int32, bool) {
+		if v := atomic.AddInt32(&n, 1); v != 2 {
+			return v, true
+		}
+		loading <- struct{}{}
+		<-release
+		return 2, true
+	})
+	c.PutWithProvider("a", g, ttl)
+	<-loading
+	c.Tag("a", "t") // Modifies the item mid-refresh.
+	close(release)
+
+	time.Sleep(3 * ttl)
+	if v := req.Get("a"); v < 3 {
+		t.Fatalf("Get(a) got=%v, want one refreshed after the tagging", v)
+	}
+}
+
+func TestRefreshKeepsExpiry(t *testing.T) {
+	c := New[string, int32](time.Minute, WithStrictExpiry())
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	var n int32
+	loading := make(chan struct{}, 1)
+	release := make(chan struct{})
+	g := GetterFunc[string, int32](func(string) (int32, bool) {
+		if v := atomic.AddInt32(&n, 1); v != 2 {
+			return v, true
+		}
+		loading <- struct{}{}
+		<-release
+		return 2, true
+	})
+	c.PutWithProvider("a", g, ttl)
+	<-loading
+	if d, ok := c.TTL("a"); !ok || d > 0 {
+		t.Errorf("TTL(a) while refreshing got=%v,%v, want none left", d, ok)
+	}
+	// Served, and not rescheduled to be refreshed again.
+	req.Assert(req.Get("a") == 1, "Get(a) while refreshing should get the old value")
+	time.Sleep(2 * ttl)
+	if err := c.CheckIntegrity(); err != nil {
+		t.Errorf("CheckIntegrity() while refreshing error: %v", err)
+	}
+	close(release)
+
+	time.Sleep(ttl / 2)
+	req.Assert(req.Get("a") == 2, "Get(a) should get the refreshed value")
+	if v := atomic.LoadInt32(&n); v != 2 {
+		t.Errorf("provider called %d times, want=2", v)
+	}
+	if d, ok := c.TTL("a"); !ok || d <= 0 || d > ttl {
+		t.Errorf("TTL(a) once refreshed got=%v,%v, want up to %v", d, ok, ttl)
+	}
+}
+
+func TestRefreshQueuedModified(t *testing.T) {
+	c := New[string, int32](time.Minute, WithRefreshConcurrency(1))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	var n int32
+	loading := make(chan struct{}, 1)
+	release := make(chan struct{})
+	g := GetterFunc[string, int32](func(k string) (int32, bool) {
+		v := atomic.AddInt32(&n, 1)
+		if k == "blocker" && v > 2 {
+			select {
+			case loading <- struct{}{}:
+				<-release
+			default:
+			}
+		}
+		return v, true
+	})
+	c.PutWithProvider("blocker", g, ttl)
+	c.PutWithProvider("a", g, 2*ttl)
+	<-loading
+	time.Sleep(2 * ttl) // "a" expires, and is queued.
+	c.Tag("a", "t")     // Modifies it while queued.
+	close(release)
+
+	time.Sleep(2 * ttl)
+	if v := req.Get("a"); v <= 2 {
+		t.Fatalf("Get(a) got=%v, want one refreshed after the tagging", v)
+	}
+}
+
+func TestWithRefreshConcurrency(t *testing.T) {
+	c := New[string, int](time.Minute, WithRefreshConcurrency(1))
+	defer c.Shutdown()
+
+	release := make(chan struct{})
+	defer close(release)
+	order := make(chan string, 4)
+	var loads int32
+	g := GetterFunc[string, int](func(k string) (int, bool) {
+		// All but the initial loads wait.
+		if atomic.AddInt32(&loads, 1) > 4 {
+			select {
+			case order <- k:
+			default:
+			}
+			<-release
+		}
+		return 0, true
+	})
+	for _, k := range []string{"blocker", "cold", "hot", "warm"} {
+		c.PutWithProvider(k, g, ttl)
+	}
+	for i := 0; i < 3; i++ {
+		c.Get("hot")
+	}
+	c.Get("warm")
+
+	time.Sleep(3 * ttl) // All have expired, one is being refreshed.
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, <-order)
+		release <- struct{}{}
+	}
+	// The first one refreshed is whichever expired first.
+	want := []string{"hot", "warm", "cold"}
+	for i, k := range want {
+		if got[i+1] != k {
+			t.Errorf("refreshed %v, want %v after the first", got, want)
+			break
+		}
+	}
+}
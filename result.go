@@ -0,0 +1,60 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// A Result of an operation, successful or not, to cache either way.
+type Result[V any] struct {
+	Value    V
+	Err      error
+	LoadedAt time.Time
+
+	ttl time.Duration
+}
+
+// NewResult of an operation that has returned v and err just now.
+func NewResult[V any](v V, err error) Result[V] {
+	return Result[V]{
+		Value:    v,
+		Err:      err,
+		LoadedAt: time.Now(),
+	}
+}
+
+// Unwrap returns the value and the error of the result.
+func (r Result[V]) Unwrap() (V, error) {
+	return r.Value, r.Err
+}
+
+// TTL returns the time-to-live that the result has been loaded with by
+// GetOrLoad, or zero for the cache to choose one.
+func (r Result[V]) TTL() time.Duration {
+	return r.ttl
+}
+
+// GetOrLoad returns the value and the error of the result cached in c
+// at key, or, if absent, the ones returned by load, after having put
+// them in the cache with ttl, if the error is nil, or errTTL, if not.
+//
+// Concurrent calls for the same key share the result of a single call
+// to load, including the error.
+func GetOrLoad[K comparable, V any](
+	c *Cache[K, Result[V]],
+	key K,
+	load func(key K) (V, error),
+	ttl, errTTL time.Duration,
+) (V, error) {
+	r, _ := c.GetOrPut(key, GetterFunc[K, Result[V]](
+		func(key K) (Result[V], bool) {
+			r := NewResult(load(key))
+			if r.ttl = ttl; r.Err != nil {
+				r.ttl = errTTL
+			}
+			return r, true
+		},
+	))
+	return r.Unwrap()
+}
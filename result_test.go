@@ -0,0 +1,49 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	c := New[string, Result[int]](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	errFailed := errors.New("failed")
+	loads := 0
+	load := func(key string) (int, error) {
+		loads++
+		if key == "bad" {
+			return 0, errFailed
+		}
+		return len(key), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := GetOrLoad(c, "good", load, time.Minute, ttl)
+		req.Assert(v == 4 && err == nil, "GetOrLoad(good) got=%d, %v", v, err)
+		_, err = GetOrLoad(c, "bad", load, time.Minute, ttl)
+		req.Assert(errors.Is(err, errFailed), "GetOrLoad(bad) error got=%v", err)
+	}
+	req.Assert(loads == 2, "loads got=%d, want=2", loads)
+	r := req.Get("bad")
+	req.Assert(!r.LoadedAt.IsZero(), "LoadedAt should be set")
+
+	// Failures are cached for errTTL.
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("bad")
+	req.Has("good")
+
+	// Results put directly get the cache-default TTL.
+	c.Put("direct", NewResult(1, nil))
+	time.Sleep(3 * ttl / 2)
+	req.Has("direct")
+}
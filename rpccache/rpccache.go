@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package rpccache caches the responses of idempotent RPCs.
+//
+// An Interceptor is not tied to any RPC framework, so that this module
+// does not depend on one. It is adapted to a gRPC unary client
+// interceptor, e.g.:
+//
+//	grpc.WithUnaryInterceptor(func(
+//		ctx context.Context, method string, req, reply any,
+//		cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+//		opts ...grpc.CallOption,
+//	) error {
+//		return i.Intercept(ctx, method, req, reply,
+//			func(ctx context.Context) error {
+//				return invoker(ctx, method, req, reply, cc, opts...)
+//			})
+//	})
+package rpccache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/antichris/go-cache"
+	"github.com/antichris/go-cache/codec"
+)
+
+// Config of an Interceptor.
+type Config struct {
+	// Codec marshals the requests, to digest them, and the responses,
+	// to cache them, e.g., one adapting proto.Marshal, for gRPC.
+	Codec codec.Codec
+	// Methods whose responses are cached, by their full names, e.g.,
+	// "/pkg.Service/Method", with the TTLs to cache them for, or zero
+	// for the cache-default one. The responses of other methods are not
+	// cached, as they may not be idempotent.
+	Methods map[string]time.Duration
+}
+
+// An Interceptor caches the responses of RPCs.
+type Interceptor struct {
+	c   *cache.Cache[string, []byte]
+	cfg Config
+}
+
+// New returns a new Interceptor caching responses in c, as configured.
+func New(c *cache.Cache[string, []byte], cfg Config) *Interceptor {
+	return &Interceptor{c: c, cfg: cfg}
+}
+
+// Intercept an RPC of method with req, setting reply to the cached
+// response to an identical request, if any, or invoking the RPC, and
+// caching its response, if it succeeds.
+func (i *Interceptor) Intercept(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	invoke func(ctx context.Context) error,
+) error {
+	ttl, ok := i.cfg.Methods[method]
+	if !ok {
+		return invoke(ctx)
+	}
+	key, err := i.Key(method, req)
+	if err != nil {
+		return invoke(ctx)
+	}
+	if b, ok := i.c.Get(key); ok && i.cfg.Codec.Unmarshal(b, reply) == nil {
+		return nil
+	}
+	if err := invoke(ctx); err != nil {
+		return err
+	}
+	b, err := i.cfg.Codec.Marshal(reply)
+	if err != nil {
+		return nil // The response is just not cached.
+	}
+	if ttl == 0 {
+		i.c.Put(key, b)
+	} else {
+		i.c.PutWithTTL(key, b, ttl)
+	}
+	return nil
+}
+
+// Key returns the key that the response of method to req is cached at:
+// the method, followed by a digest of the marshaled request.
+func (i *Interceptor) Key(method string, req any) (string, error) {
+	b, err := i.cfg.Codec.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return method + " #" + hex.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package rpccache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	"github.com/antichris/go-cache/codec"
+	. "github.com/antichris/go-cache/rpccache"
+)
+
+type request struct{ ID int }
+
+type response struct{ Name string }
+
+func TestInterceptor(t *testing.T) {
+	c := cache.New[string, []byte](time.Minute)
+	defer c.Shutdown()
+	i := New(c, Config{
+		Codec:   codec.JSON,
+		Methods: map[string]time.Duration{"/users.Users/Get": time.Hour},
+	})
+	ctx := context.Background()
+	calls := 0
+	call := func(method string, id int, fail bool) (response, error) {
+		req, reply := request{id}, response{}
+		err := i.Intercept(ctx, method, req, &reply, func(context.Context) error {
+			calls++
+			if fail {
+				return errors.New("unavailable")
+			}
+			reply.Name = method
+			return nil
+		})
+		return reply, err
+	}
+
+	if _, err := call("/users.Users/Get", 1, true); err == nil {
+		t.Error("Intercept() should return the error of the RPC")
+	}
+	for n := 0; n < 2; n++ {
+		if r, err := call("/users.Users/Get", 1, false); err != nil ||
+			r.Name != "/users.Users/Get" {
+			t.Errorf("Intercept() got=%+v, %v", r, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls: got=%d, want=2", calls)
+	}
+	call("/users.Users/Get", 2, false)
+	call("/users.Users/Delete", 1, false)
+	call("/users.Users/Delete", 1, false)
+	if calls != 5 {
+		t.Errorf("calls: got=%d, want=5", calls)
+	}
+
+	key, _ := i.Key("/users.Users/Get", request{1})
+	if e, ok := c.DropEntry(key); !ok || e.TTL != time.Hour {
+		t.Errorf("cached response TTL got=%v,%v, want=%v", e.TTL, ok, time.Hour)
+	}
+}
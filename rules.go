@@ -0,0 +1,126 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// A TTLer is a value that has its own time-to-live, e.g., a DNS record.
+//
+// Values that implement it are cached with the time-to-live it returns,
+// if positive, unless one is given explicitly, instead of the one set
+// by the rules, or the cache-default one.
+type TTLer interface {
+	TTL() time.Duration
+}
+
+// A TTLRule sets the time-to-live of the items with keys it matches.
+type TTLRule[K comparable] struct {
+	Match func(key K) bool
+	TTL   time.Duration
+}
+
+// PrefixRule returns a TTLRule matching the keys that start with prefix.
+func PrefixRule(prefix string, ttl time.Duration) TTLRule[string] {
+	return TTLRule[string]{
+		Match: func(key string) bool {
+			return strings.HasPrefix(key, prefix)
+		},
+		TTL: ttl,
+	}
+}
+
+// WithTTLRules sets the rules for the time-to-live of the items put in
+// a Cache of the same key type without one given explicitly, instead
+// of the cache-default one. The first rule matching the key of an item
+// applies.
+//
+// New panics if the key type does not match that of the cache.
+func WithTTLRules[K comparable](rules ...TTLRule[K]) Option {
+	return func(o *options) {
+		o.ttlRules = rules
+	}
+}
+
+// WithTTLFunc sets a function returning the time-to-live of the items
+// put in a Cache of the same key and value types without one given
+// explicitly, if it reports ok, instead of the one set by the rules, or
+// the cache-default one. Values that are TTLers take precedence.
+//
+// New panics if the types do not match those of the cache.
+func WithTTLFunc[K comparable, V any](
+	f func(key K, value V) (ttl time.Duration, ok bool),
+) Option {
+	return func(o *options) {
+		o.ttlFunc = f
+	}
+}
+
+// Internals.
+
+// rulesOf returns the rules set by WithTTLRules.
+func rulesOf[K comparable](o options) []TTLRule[K] {
+	if o.ttlRules == nil {
+		return nil
+	}
+	rules, ok := o.ttlRules.([]TTLRule[K])
+	if !ok {
+		panic("cache: WithTTLRules key type does not match that of the cache")
+	}
+	return rules
+}
+
+// ttlFuncOf returns the function set by WithTTLFunc.
+func ttlFuncOf[K comparable, V any](o options) func(K, V) (time.Duration, bool) {
+	if o.ttlFunc == nil {
+		return nil
+	}
+	f, ok := o.ttlFunc.(func(K, V) (time.Duration, bool))
+	if !ok {
+		panic("cache: WithTTLFunc types do not match those of the cache")
+	}
+	return f
+}
+
+// mayBeTTLer returns whether values of type V may be TTLers.
+func mayBeTTLer[V any]() bool {
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	return t.Kind() == reflect.Interface ||
+		t.Implements(reflect.TypeOf((*TTLer)(nil)).Elem())
+}
+
+// valueTTL returns o with the time-to-live of value put at key, unless
+// o has one given explicitly.
+func (c *Cache[K, V]) valueTTL(key K, value V, o callOptions) callOptions {
+	if o.ttlSet {
+		return o
+	}
+	if c.ttler {
+		if t, ok := any(value).(TTLer); ok && t.TTL() > 0 {
+			o.ttl = t.TTL()
+			return o
+		}
+	}
+	if c.ttlFunc != nil {
+		if ttl, ok := c.ttlFunc(key, value); ok {
+			o.ttl = ttl
+		}
+	}
+	return o
+}
+
+// ttlFor returns the time-to-live of an item put at key without one
+// given explicitly.
+func (c *Cache[K, V]) ttlFor(key K) time.Duration {
+	for _, r := range c.rules {
+		if r.Match(key) {
+			return r.TTL
+		}
+	}
+	return c.ttl
+}
@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestTTLRules(t *testing.T) {
+	c := New[string, int](time.Minute, WithTTLRules(
+		PrefixRule("session/", ttl),
+		TTLRule[string]{
+			Match: func(key string) bool { return len(key) == 1 },
+			TTL:   ttl,
+		},
+		PrefixRule("session/long", time.Minute), // Shadowed.
+	))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("session/1", 1)
+	c.Put("session/long", 2)
+	c.Put("a", 3)
+	c.Put("user/1", 4)
+	c.Put("session/2", 5, TTL(time.Minute))
+	c.GetOrPut("b", SimpleGetterFunc[string, int](func() int { return 6 }))
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("session/1")
+	req.HasNot("session/long")
+	req.HasNot("a")
+	req.HasNot("b")
+	req.Has("user/1")
+	req.Has("session/2")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New() should panic on mismatched rule key types")
+		}
+	}()
+	New[int, int](time.Minute, WithTTLRules(PrefixRule("", ttl)))
+}
+
+type record struct {
+	ttl time.Duration
+}
+
+func (r record) TTL() time.Duration {
+	return r.ttl
+}
+
+func TestTTLer(t *testing.T) {
+	c := New[string, record](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", record{ttl})
+	c.GetOrPut("b", SimpleGetterFunc[string, record](func() record {
+		return record{ttl}
+	}))
+	c.Put("c", record{ttl}, TTL(time.Minute))
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("a")
+	req.HasNot("b")
+	req.Has("c")
+
+	// Values held in interfaces are checked too.
+	ci := New[string, any](time.Minute)
+	defer ci.Shutdown()
+	ci.Put("a", record{ttl})
+	ci.Put("b", 1)
+	time.Sleep(3 * ttl / 2)
+	reqi := newAssert(t, ci, true)
+	reqi.HasNot("a")
+	reqi.Has("b")
+}
+
+func TestTTLFunc(t *testing.T) {
+	c := New[string, int](time.Minute, WithTTLFunc(func(_ string, v int) (time.Duration, bool) {
+		return time.Duration(v) * ttl, v > 0
+	}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", 0)
+	c.PutWithTTL("c", 1, time.Minute)
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("a")
+	req.Has("b")
+	req.Has("c")
+}
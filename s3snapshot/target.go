@@ -0,0 +1,268 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package s3snapshot stores cache snapshots in S3-compatible object
+// storage.
+//
+// A Target provides the factories that Snapshotter.DumpTo and
+// Cache.RestoreFrom take:
+//
+//	t := &s3snapshot.Target{...}
+//	err := snapshotter.DumpTo(t.Create)
+//	...
+//	err = c.RestoreFrom(t.Open)
+//
+// Snapshots larger than a single part are uploaded in multiple parts.
+package s3snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultPartSize is the part size used when a Target has none set.
+const DefaultPartSize = 8 << 20
+
+// MinPartSize is the minimum size of all parts of a multipart upload
+// but the last one, as mandated by S3.
+const MinPartSize = 5 << 20
+
+// Target is an object in S3-compatible storage.
+type Target struct {
+	// Endpoint URL of the storage service, e.g.,
+	// "https://s3.eu-west-1.amazonaws.com". Objects are addressed in
+	// the path style: Endpoint/Bucket/Key.
+	Endpoint string
+	// Region to sign requests for; "us-east-1", if empty.
+	Region string
+	Bucket string
+	Key    string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken of temporary credentials, if any.
+	SessionToken string
+
+	// PartSize for multipart uploads; DefaultPartSize, if zero. Values
+	// below MinPartSize are raised to it.
+	PartSize int
+	// HTTPClient to make requests with; http.DefaultClient, if nil.
+	HTTPClient *http.Client
+	// Context for the requests; context.Background(), if nil.
+	Context context.Context
+}
+
+// Create returns a writer that uploads the object on Close.
+//
+// Data is buffered up to the part size. Objects that fit in a single
+// part are uploaded with a single request, larger ones with a
+// multipart upload, which is aborted if any of its requests fail.
+func (t *Target) Create() (io.WriteCloser, error) {
+	size := t.PartSize
+	if size == 0 {
+		size = DefaultPartSize
+	}
+	if size < MinPartSize {
+		size = MinPartSize
+	}
+	return &writer{
+		t:    t,
+		size: size,
+	}, nil
+}
+
+// Open returns a reader of the object.
+func (t *Target) Open() (io.ReadCloser, error) {
+	res, err := t.do(http.MethodGet, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Internals.
+
+type writer struct {
+	t     *Target
+	size  int
+	buf   bytes.Buffer
+	id    string   // Multipart upload ID.
+	etags []string // Of uploaded parts.
+	err   error
+}
+
+func (w *writer) Write(p []byte) (n int, err error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	for len(p) > 0 {
+		m := w.size - w.buf.Len()
+		if m > len(p) {
+			m = len(p)
+		}
+		w.buf.Write(p[:m])
+		n += m
+		p = p[m:]
+		if w.buf.Len() == w.size {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.id == "" {
+		res, err := w.t.do(http.MethodPut, nil, w.buf.Bytes())
+		if err != nil {
+			return w.fail(err)
+		}
+		res.Body.Close()
+		return nil
+	}
+	if w.buf.Len() > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	return w.fail(w.complete())
+}
+
+// flush the buffer as the next part.
+func (w *writer) flush() error {
+	if w.id == "" {
+		if err := w.fail(w.start()); err != nil {
+			return err
+		}
+	}
+	q := url.Values{
+		"partNumber": {strconv.Itoa(len(w.etags) + 1)},
+		"uploadId":   {w.id},
+	}
+	res, err := w.t.do(http.MethodPut, q, w.buf.Bytes())
+	if err != nil {
+		return w.fail(err)
+	}
+	res.Body.Close()
+	w.etags = append(w.etags, res.Header.Get("ETag"))
+	w.buf.Reset()
+	return nil
+}
+
+// start a multipart upload.
+func (w *writer) start() error {
+	res, err := w.t.do(http.MethodPost, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var r struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(res.Body).Decode(&r); err != nil {
+		return err
+	}
+	if r.UploadID == "" {
+		return errors.New("s3snapshot: no upload ID in response")
+	}
+	w.id = r.UploadID
+	return nil
+}
+
+// complete the multipart upload.
+func (w *writer) complete() error {
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	body := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+	for i, etag := range w.etags {
+		body.Parts = append(body.Parts, part{i + 1, etag})
+	}
+	b, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+	res, err := w.t.do(http.MethodPost, url.Values{"uploadId": {w.id}}, b)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// S3 may report a failure with a 200 (OK) status.
+	var r struct {
+		XMLName xml.Name
+		Message string
+	}
+	if err := xml.NewDecoder(res.Body).Decode(&r); err == nil &&
+		r.XMLName.Local == "Error" {
+		return fmt.Errorf("s3snapshot: %s", r.Message)
+	}
+	return nil
+}
+
+// fail the writer with err, if not nil, aborting a multipart upload.
+func (w *writer) fail(err error) error {
+	if err == nil {
+		return nil
+	}
+	w.err = err
+	if w.id != "" {
+		if res, err := w.t.do(http.MethodDelete,
+			url.Values{"uploadId": {w.id}}, nil); err == nil {
+			res.Body.Close()
+		}
+	}
+	return err
+}
+
+// do a signed request for the object, returning an error for any
+// response with a status other than 2xx.
+func (t *Target) do(method string, q url.Values, body []byte) (*http.Response, error) {
+	ctx := t.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	u := strings.TrimSuffix(t.Endpoint, "/") + "/" +
+		escapePath(t.Bucket) + "/" + escapePath(t.Key)
+	if len(q) > 0 {
+		u += "?" + canonicalQuery(q)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	t.sign(req, body)
+
+	hc := t.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return nil, fmt.Errorf("s3snapshot: %s %s: %s: %s",
+		method, t.Key, res.Status, bytes.TrimSpace(msg))
+}
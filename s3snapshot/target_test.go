@@ -0,0 +1,207 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package s3snapshot_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/s3snapshot"
+)
+
+func TestSnapshot(t *testing.T) {
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+	target := &Target{
+		Endpoint:        srv.URL,
+		Bucket:          "bucket",
+		Key:             "snapshots/cache one",
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+	}
+
+	src := cache.New[string, int](time.Minute)
+	defer src.Shutdown()
+	src.Put("a", 1)
+	s := cache.NewSnapshotter(src, cache.SnapshotConfig{Compress: true})
+	if err := s.DumpTo(target.Create); err != nil {
+		t.Fatalf("DumpTo() error: %v", err)
+	}
+	if s3.uploads != 0 {
+		t.Errorf("small snapshots should not use multipart uploads")
+	}
+
+	dst := cache.New[string, int](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.RestoreFrom(target.Open); err != nil {
+		t.Fatalf("RestoreFrom() error: %v", err)
+	}
+	if v, ok := dst.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) got=%v,%v, want=1,true", v, ok)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+	target := &Target{
+		Endpoint: srv.URL,
+		Bucket:   "bucket",
+		Key:      "big",
+		PartSize: 1, // Raised to MinPartSize.
+	}
+
+	data := make([]byte, 2*MinPartSize+42)
+	rand.Read(data)
+	w, _ := target.Create()
+	// Write in odd chunks to exercise buffering.
+	for p := data; len(p) > 0; {
+		n := 3 << 19
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if s3.uploads != 1 || s3.parts != 3 {
+		t.Errorf("got %d uploads of %d parts, want 1 of 3", s3.uploads, s3.parts)
+	}
+
+	r, err := target.Open()
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer r.Close()
+	got, _ := io.ReadAll(r)
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded object differs from uploaded")
+	}
+}
+
+func TestFailure(t *testing.T) {
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+	target := &Target{
+		Endpoint: srv.URL,
+		Bucket:   "bucket",
+		Key:      "missing",
+		PartSize: MinPartSize,
+	}
+
+	if _, err := target.Open(); err == nil {
+		t.Error("Open() of a missing object should fail")
+	}
+
+	s3.failParts = true
+	w, _ := target.Create()
+	w.Write(make([]byte, MinPartSize))
+	if err := w.Close(); err == nil {
+		t.Error("Close() should fail when a part upload does")
+	}
+	if s3.aborted != 1 {
+		t.Errorf("failed upload should be aborted")
+	}
+}
+
+// fakeS3 implements just enough of the S3 API for the Target.
+type fakeS3 struct {
+	m         sync.Mutex
+	objects   map[string][]byte
+	pending   map[string]map[int][]byte
+	uploads   int
+	parts     int
+	aborted   int
+	failParts bool
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects: map[string][]byte{},
+		pending: map[string]map[int][]byte{},
+	}
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if a := r.Header.Get("Authorization"); !strings.HasPrefix(a, "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	q := r.URL.Query()
+	key := r.URL.Path
+	id := q.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodGet:
+		b, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.uploads++
+		id := strconv.Itoa(s.uploads)
+		s.pending[id] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s"+
+			"</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && id != "":
+		if s.failParts {
+			http.Error(w, "InternalError", http.StatusInternalServerError)
+			return
+		}
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		s.pending[id][n] = body
+		s.parts++
+		w.Header().Set("ETag", fmt.Sprintf("%q", strconv.Itoa(n)))
+	case r.Method == http.MethodPost && id != "":
+		var c struct {
+			Parts []struct{ PartNumber int } `xml:"Part"`
+		}
+		xml.Unmarshal(body, &c)
+		var nums []int
+		for _, p := range c.Parts {
+			nums = append(nums, p.PartNumber)
+		}
+		sort.Ints(nums)
+		var b []byte
+		for _, n := range nums {
+			b = append(b, s.pending[id][n]...)
+		}
+		s.objects[key] = b
+		delete(s.pending, id)
+		fmt.Fprint(w, "<CompleteMultipartUploadResult/>")
+	case r.Method == http.MethodDelete && id != "":
+		delete(s.pending, id)
+		s.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+	default:
+		http.Error(w, "unsupported", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,85 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// A Schedule of recurring events.
+type Schedule interface {
+	// Next returns the time of the first event after t.
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc is a func that implements the Schedule interface.
+type ScheduleFunc func(t time.Time) time.Time
+
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// Every returns a Schedule of events d apart.
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Add(d)
+	})
+}
+
+// Daily returns a Schedule of events at the given hour and minute of
+// every day in loc, e.g., Daily(0, 0, time.UTC) for every midnight UTC.
+func Daily(hour, min int, loc *time.Location) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		t = t.In(loc)
+		y, m, d := t.Date()
+		next := time.Date(y, m, d, hour, min, 0, 0, loc)
+		if !next.After(t) {
+			next = time.Date(y, m, d+1, hour, min, 0, 0, loc)
+		}
+		return next
+	})
+}
+
+// DropTagOn drops all cached items tagged with tag at every event of s,
+// until cancel is called or the cache is shut down.
+func (c *Cache[K, V]) DropTagOn(s Schedule, tag string) (cancel func()) {
+	return c.on(s, func() { c.DropTag(tag) })
+}
+
+// Internals.
+
+// on calls f at every event of s in a new goroutine, until cancel is
+// called or the cache is shut down.
+func (c *Cache[K, V]) on(s Schedule, f func()) (cancel func()) {
+	stop := make(emptyChan)
+	go func() {
+		next := s.Next(time.Now())
+		t := time.NewTimer(time.Until(next))
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				f()
+				// Events that have passed meanwhile are skipped.
+				next = s.Next(maxTime(next, time.Now()))
+				t.Reset(time.Until(next))
+			case <-stop:
+				return
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestDaily(t *testing.T) {
+	s := Daily(0, 30, time.UTC)
+	for _, tt := range []struct {
+		t, want time.Time
+	}{
+		{
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC),
+		}, {
+			time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC),
+			time.Date(2024, 3, 2, 0, 30, 0, 0, time.UTC),
+		}, {
+			time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 30, 0, 0, time.UTC),
+		},
+	} {
+		if got := s.Next(tt.t); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) got=%v, want=%v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestDropTagOn(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Tag("a", "pricing")
+	cancel := c.DropTagOn(Every(2*ttl), "pricing")
+	time.Sleep(3 * ttl)
+	req.HasNot("a")
+	req.Has("b")
+
+	c.Put("a", 1)
+	c.Tag("a", "pricing")
+	time.Sleep(2 * ttl)
+	req.HasNot("a")
+
+	cancel()
+	cancel()
+	c.Put("a", 1)
+	c.Tag("a", "pricing")
+	time.Sleep(4 * ttl)
+	req.Has("a")
+	if n := c.DropTag("pricing"); n != 1 {
+		t.Errorf("DropTag() got=%d, want=1", n)
+	}
+	req.HasNot("a")
+}
@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// A SeedFormat is the format of a seed read by LoadSeed.
+type SeedFormat int
+
+// The formats of the seeds.
+const (
+	// SeedJSONLines is a JSON object per line, with the "key", the
+	// "value", and, optionally, the "ttl" of an item, e.g.:
+	//
+	//	{"key": "a", "value": 1, "ttl": "5m"}
+	SeedJSONLines SeedFormat = iota
+	// SeedCSV is a CSV record per item, with its key, value, and,
+	// optionally, TTL, e.g.:
+	//
+	//	a,1,5m
+	//
+	// A header record of "key,value[,ttl]" is skipped. Keys and values
+	// are read from text by their UnmarshalText methods, for
+	// encoding.TextUnmarshalers, as is, for strings, or as JSON,
+	// otherwise.
+	SeedCSV
+)
+
+// ErrSeedFormat is returned by LoadSeed for an unknown seed format.
+var ErrSeedFormat = errors.New("cache: unknown seed format")
+
+// LoadSeed puts the items read from a seed in format from r in the
+// cache, e.g., one exported from a dataset to pre-populate the cache at
+// deploy time, and returns the number of items put.
+//
+// The TTLs are parsed by time.ParseDuration. Items without one get the
+// cache-default TTL for their keys. Loading stops at the first item
+// that fails to be read or put, with an error that tells its line.
+func (c *Cache[K, V]) LoadSeed(r io.Reader, format SeedFormat) (n int, err error) {
+	var next func() (seedItem[K, V], error)
+	switch format {
+	case SeedJSONLines:
+		next = jsonSeed[K, V](r)
+	case SeedCSV:
+		next = csvSeed[K, V](r)
+	default:
+		return 0, ErrSeedFormat
+	}
+	for line := 1; ; line++ {
+		it, err := next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err == nil {
+			err = c.putSeed(it)
+		}
+		if err != nil {
+			return n, fmt.Errorf("cache: seed line %d: %w", line, err)
+		}
+		if !it.skip {
+			n++
+		}
+	}
+}
+
+// Internals.
+
+// A seedItem is an item read from a seed.
+type seedItem[K comparable, V any] struct {
+	Key   K
+	Value V
+	TTL   string
+
+	skip bool // Whether this is not an item, e.g., a header or blank.
+}
+
+// putSeed puts it in the cache, unless skipped.
+func (c *Cache[K, V]) putSeed(it seedItem[K, V]) error {
+	if it.skip {
+		return nil
+	}
+	o := c.callOptions(it.Key, nil)
+	if it.TTL != "" {
+		ttl, err := time.ParseDuration(it.TTL)
+		if err != nil {
+			return err
+		}
+		o.ttl, o.ttlSet = ttl, true
+	}
+	return c.put(it.Key, it.Value, o)
+}
+
+// jsonSeed returns a function reading the items of a JSON Lines seed
+// from r, one per line.
+func jsonSeed[K comparable, V any](r io.Reader) func() (seedItem[K, V], error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	return func() (it seedItem[K, V], err error) {
+		if !s.Scan() {
+			if err = s.Err(); err == nil {
+				err = io.EOF
+			}
+			return
+		}
+		b := bytes.TrimSpace(s.Bytes())
+		if len(b) == 0 {
+			it.skip = true
+			return
+		}
+		var j struct {
+			Key   K      `json:"key"`
+			Value V      `json:"value"`
+			TTL   string `json:"ttl"`
+		}
+		err = json.Unmarshal(b, &j)
+		return seedItem[K, V]{Key: j.Key, Value: j.Value, TTL: j.TTL}, err
+	}
+}
+
+// csvSeed returns a function reading the items of a CSV seed from r,
+// one per record.
+func csvSeed[K comparable, V any](r io.Reader) func() (seedItem[K, V], error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	first := true
+	return func() (it seedItem[K, V], err error) {
+		rec, err := cr.Read()
+		if err != nil {
+			return
+		}
+		header := first && len(rec) >= 2 && rec[0] == "key" && rec[1] == "value"
+		first = false
+		switch {
+		case header:
+			it.skip = true
+			return
+		case len(rec) < 2 || len(rec) > 3:
+			err = fmt.Errorf("%d fields, want 2 or 3", len(rec))
+			return
+		}
+		if err = parseText(rec[0], &it.Key); err != nil {
+			return
+		}
+		if err = parseText(rec[1], &it.Value); err != nil {
+			return
+		}
+		if len(rec) == 3 {
+			it.TTL = rec[2]
+		}
+		return
+	}
+}
+
+// parseText s into v, by its UnmarshalText method, for
+// encoding.TextUnmarshalers, as is, for strings, or as JSON, otherwise.
+func parseText[T any](s string, v *T) error {
+	if u, ok := any(v).(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if rv := reflect.ValueOf(v).Elem(); rv.Kind() == reflect.String {
+		rv.SetString(s)
+		return nil
+	}
+	return json.Unmarshal([]byte(s), v)
+}
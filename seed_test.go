@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestLoadSeed(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		format SeedFormat
+		seed   string
+	}{
+		{"JSONLines", SeedJSONLines, `{"key": "a", "value": 1}
+{"key": "b", "value": 2, "ttl": "1h"}
+
+{"key": "short", "value": 3, "ttl": "10ms"}
+`},
+		{"CSV", SeedCSV, `key,value,ttl
+a,1
+b,2,1h
+short,3,10ms
+`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](time.Minute)
+			defer c.Shutdown()
+			req := newAssert(t, c, true)
+
+			n, err := c.LoadSeed(strings.NewReader(tt.seed), tt.format)
+			if err != nil || n != 3 {
+				t.Fatalf("LoadSeed() got=%d, %v, want=3, nil", n, err)
+			}
+			req.Assert(req.Get("a") == 1, "a should be 1")
+			req.Assert(req.Get("b") == 2, "b should be 2")
+			time.Sleep(2 * ttl)
+			req.HasNot("short")
+			req.LengthIs(2)
+		})
+	}
+}
+
+func TestLoadSeedErrors(t *testing.T) {
+	c := New[string, int](time.Minute, WithoutZeroKeys())
+	defer c.Shutdown()
+
+	for _, tt := range []struct {
+		name   string
+		format SeedFormat
+		seed   string
+		n      int
+	}{
+		{"format", SeedFormat(-1), "", 0},
+		{"JSON", SeedJSONLines, "{\"key\": \"a\", \"value\": 1}\n{", 1},
+		{"value", SeedCSV, "a,1\nb,x\n", 1},
+		{"fields", SeedCSV, "a\n", 0},
+		{"TTL", SeedCSV, "a,1,soon\n", 0},
+		{"key", SeedCSV, ",1\n", 0},
+	} {
+		n, err := c.LoadSeed(strings.NewReader(tt.seed), tt.format)
+		if err == nil || n != tt.n {
+			t.Errorf("%s: LoadSeed() got=%d, %v, want=%d and an error",
+				tt.name, n, err, tt.n)
+		}
+	}
+	_, err := c.LoadSeed(strings.NewReader(",1\n"), SeedCSV)
+	if !errors.Is(err, ErrZeroKey) {
+		t.Errorf("LoadSeed() error got=%v, want=%v", err, ErrZeroKey)
+	}
+}
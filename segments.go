@@ -0,0 +1,131 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// WithHotSegment splits the items of a Cache that compresses cold ones,
+// see WithColdCompression, into a hot segment of up to n of the most
+// recently looked up ones, kept as they are, and a cold segment of the
+// others, compressed densely, in blocks shared by many, e.g., for
+// workloads with popularity skewed strongly enough that most items are
+// rarely looked up.
+//
+// Items join the hot segment when they are put, and are promoted to it
+// when looked up. The ones that have not been looked up for a while are
+// demoted to the cold segment, as by WithColdCompression alone, and so
+// are the least recently looked up ones beyond n, at the same times,
+// so the hot segment may hold more than n items in between.
+func WithHotSegment(n int) Option {
+	return func(o *options) {
+		o.hotItems = n
+	}
+}
+
+// Internals.
+
+// heat puts the item with timer t at the front of the hot segment, if
+// bounded.
+func (c *Cache[K, V]) heat(t *itemTimer[K]) {
+	if c.o.hotItems <= 0 {
+		return
+	}
+	if c.hot.hnext == nil {
+		c.hot.hnext, c.hot.hprev = &c.hot, &c.hot
+	}
+	if t.hnext != nil {
+		if c.hot.hnext == t {
+			return
+		}
+		t.hprev.hnext, t.hnext.hprev = t.hnext, t.hprev
+	} else {
+		c.hotN++
+	}
+	t.hprev, t.hnext = &c.hot, c.hot.hnext
+	t.hnext.hprev = t
+	c.hot.hnext = t
+}
+
+// cool takes the item with timer t out of the hot segment.
+func (c *Cache[K, V]) cool(t *itemTimer[K]) {
+	if t == nil || t.hnext == nil {
+		return
+	}
+	t.hprev.hnext, t.hnext.hprev = t.hnext, t.hprev
+	t.hprev, t.hnext = nil, nil
+	c.hotN--
+}
+
+// overflow returns the timers of the least recently looked up items in
+// the hot segment beyond its bound.
+func (c *Cache[K, V]) overflow() map[*itemTimer[K]]bool {
+	n := c.hotN - c.o.hotItems
+	if c.o.hotItems <= 0 || n <= 0 {
+		return nil
+	}
+	ts := make(map[*itemTimer[K]]bool, n)
+	for t := c.hot.hprev; t != &c.hot && len(ts) < n; t = t.hprev {
+		ts[t] = true
+	}
+	return ts
+}
+
+// A coldSegment stores the compressed values of the cold items densely,
+// in blocks shared by many, rather than in an allocation each. Blocks
+// are never written to where filled, so the values stored in them stay
+// valid after they are released, or moved by compaction.
+type coldSegment struct {
+	block []byte // Being filled.
+	live  int64  // Bytes of the values stored.
+	dead  int64  // Bytes of the values released since compacted.
+}
+
+// coldBlockSize is the size of the blocks of a coldSegment. Values
+// larger than a quarter of it are stored on their own.
+const coldBlockSize = 64 << 10
+
+// store a copy of data in s, returning it.
+func (s *coldSegment) store(data []byte) []byte {
+	s.live += int64(len(data))
+	if len(data) > coldBlockSize/4 {
+		return append([]byte(nil), data...)
+	}
+	if cap(s.block)-len(s.block) < len(data) {
+		s.block = make([]byte, 0, coldBlockSize)
+	}
+	i := len(s.block)
+	s.block = append(s.block, data...)
+	return s.block[i:len(s.block):len(s.block)]
+}
+
+// release data stored in s.
+func (s *coldSegment) release(data []byte) {
+	s.live -= int64(len(data))
+	s.dead += int64(len(data))
+}
+
+// fragmented returns whether more of the blocks of s is released than
+// holds values.
+func (s *coldSegment) fragmented() bool {
+	return s.dead > s.live && s.dead >= coldBlockSize
+}
+
+// uncold releases the compressed value of the item old, if cold, once
+// replaced by val. The cache must be locked.
+func (c *Cache[K, V]) uncold(old, val entry[K, V]) {
+	if old.cold != nil && old.cold != val.cold {
+		c.coldSeg.release(old.cold.data)
+	}
+}
+
+// compactCold moves the compressed values of the cold items to new
+// blocks, releasing the ones they were in. The cache must be locked.
+func (c *Cache[K, V]) compactCold() {
+	c.coldSeg = coldSegment{}
+	for k, val := range c.d {
+		if val.cold != nil {
+			val.cold = &coldValue[V]{c.coldSeg.store(val.cold.data), val.cold.decompress}
+			c.d[k] = val
+		}
+	}
+}
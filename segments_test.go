@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestWithHotSegment(t *testing.T) {
+	var packed, unpacked int32
+	c := New[int, string](time.Minute,
+		WithColdCompression(5*ttl,
+			func(v string) ([]byte, bool) {
+				atomic.AddInt32(&packed, 1)
+				return []byte(v), true
+			},
+			func(data []byte) string {
+				atomic.AddInt32(&unpacked, 1)
+				return string(data)
+			},
+		),
+		WithHotSegment(2),
+	)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	value := func(i int) string { return strings.Repeat("v", 100) + strconv.Itoa(i) }
+	for i := 0; i < 5; i++ {
+		c.Put(i, value(i))
+	}
+	for i := 0; i < 5; i++ {
+		c.Get(i)
+	}
+	// None has been idle for a sweep yet, but the least recently looked
+	// up ones overflow the hot segment.
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&packed) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if hot, cold := c.Segments(); hot != 2 || cold != 3 {
+		t.Fatalf("Segments() got=%d,%d, want=2,3", hot, cold)
+	}
+	req.Assert(req.Get(3) == value(3), "Get(3) got a wrong value")
+	req.Assert(atomic.LoadInt32(&unpacked) == 0, "Get(3) should be served by the hot segment")
+	req.Assert(req.Get(0) == value(0), "Get(0) got a wrong value")
+	req.Assert(atomic.LoadInt32(&unpacked) == 1, "Get(0) should be served by the cold segment")
+	if hot, cold := c.Segments(); hot != 3 || cold != 2 {
+		t.Errorf("Segments() after a promotion got=%d,%d, want=3,2", hot, cold)
+	}
+	if err := c.CheckIntegrity(); err != nil {
+		t.Errorf("CheckIntegrity() error: %v", err)
+	}
+}
+
+func TestColdSegmentCompaction(t *testing.T) {
+	c := New[int, string](time.Minute, WithColdCompression(5*ttl,
+		func(v string) ([]byte, bool) { return []byte(v), true },
+		func(data []byte) string { return string(data) },
+	))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	const n = 1000
+	value := func(i int) string { return strings.Repeat("v", 200) + strconv.Itoa(i) }
+	for i := 0; i < n; i++ {
+		c.Put(i, value(i))
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, cold := c.Segments(); cold == n {
+			break
+		}
+		time.Sleep(ttl)
+	}
+	// Decompressing most of the values releases most of the segment,
+	// which is compacted on the next sweep.
+	for i := 0; i < n*9/10; i++ {
+		req.Assert(req.Get(i) == value(i), "Get(%d) got a wrong value", i)
+	}
+	req.Assert(c.ColdReleased() > 0, "decompressed values should be released")
+	for c.ColdReleased() > 0 && time.Now().Before(deadline) {
+		time.Sleep(ttl)
+	}
+	req.Assert(c.ColdReleased() == 0, "the cold segment should have been compacted")
+	for i := n * 9 / 10; i < n; i++ {
+		req.Assert(req.Get(i) == value(i), "Get(%d) after compaction got a wrong value", i)
+	}
+	if err := c.CheckIntegrity(); err != nil {
+		t.Errorf("CheckIntegrity() error: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// A Set of keys that expire, e.g., a deduplication window, or the
+// recently seen items.
+type Set[K comparable] struct {
+	c *Cache[K, struct{}]
+}
+
+// NewSet returns a new Set of keys that expire after defaultTTL, unless
+// added with another time-to-live.
+func NewSet[K comparable](defaultTTL time.Duration, opts ...Option) *Set[K] {
+	return &Set[K]{c: New[K, struct{}](defaultTTL, opts...)}
+}
+
+// Add key to the set, with the time-to-live set by the rules, or the
+// set-default one, unless overridden by opts. Returns whether the key
+// was absent. Adding a present key extends its lifetime, like a lookup
+// does.
+func (s *Set[K]) Add(key K, opts ...CallOption) (added bool) {
+	s.c.GetOrPut(key, GetterFunc[K, struct{}](
+		func(K) (struct{}, bool) {
+			added = true
+			return struct{}{}, true
+		},
+	), opts...)
+	return
+}
+
+// AddWithTTL adds key to the set, like Add does, with the given
+// time-to-live.
+func (s *Set[K]) AddWithTTL(key K, ttl time.Duration) bool {
+	return s.Add(key, TTL(ttl))
+}
+
+// Contains returns whether key is in the set, without extending its
+// lifetime.
+func (s *Set[K]) Contains(key K) bool {
+	return s.c.Has(key)
+}
+
+// Remove key from the set. Returns whether it was present.
+func (s *Set[K]) Remove(key K) bool {
+	_, ok := s.c.Drop(key)
+	return ok
+}
+
+// Len is the number of keys in the set.
+func (s *Set[K]) Len() int {
+	return s.c.Length()
+}
+
+// Members returns the keys in the set, in no particular order.
+func (s *Set[K]) Members() []K {
+	return s.c.Keys()
+}
+
+// Cache returns the underlying cache.
+func (s *Set[K]) Cache() *Cache[K, struct{}] {
+	return s.c
+}
+
+// Shutdown the underlying cache.
+func (s *Set[K]) Shutdown() {
+	s.c.Shutdown()
+}
@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet[string](2 * ttl)
+	defer s.Shutdown()
+
+	if !s.Add("a") {
+		t.Fatal("Add(a) should add 'a'")
+	}
+	if s.Add("a") {
+		t.Fatal("Add(a) again should not add 'a'")
+	}
+	s.AddWithTTL("b", time.Minute)
+	if got := s.Len(); got != 2 {
+		t.Fatalf("Len() got=%d, want=2", got)
+	}
+	if !s.Contains("a") || s.Contains("c") {
+		t.Fatal("should contain 'a' and not 'c'")
+	}
+	if got := s.Members(); len(got) != 2 {
+		t.Fatalf("Members() got=%v", got)
+	}
+
+	time.Sleep(3 * ttl)
+	if s.Contains("a") || !s.Contains("b") {
+		t.Fatal("'a' should have expired and 'b' not")
+	}
+	if !s.Remove("b") || s.Remove("b") {
+		t.Fatal("Remove(b) should only remove 'b' once")
+	}
+}
@@ -0,0 +1,199 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// A Sharded cache spreads its items across a number of Caches, the
+// shards, by the hashes of their keys.
+//
+// Every shard has its own lock, expiry schedule and goroutine
+// processing it, so that lookups of keys in different shards do not
+// contend, and mass expirations are processed in parallel, without one
+// shard delaying the expiry in another.
+type Sharded[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	hash   func(K) uint64
+}
+
+// NewSharded returns a new Sharded cache of n shards (at least one),
+// with keys assigned to them by hash, e.g., HashString.
+//
+// All shards are constructed with opts, so the limits they set, e.g.,
+// WithCapacity, apply to every shard separately.
+func NewSharded[K comparable, V any](
+	n int,
+	hash func(key K) uint64,
+	defaultTTL time.Duration,
+	opts ...Option,
+) *Sharded[K, V] {
+	if n < 1 {
+		n = 1
+	}
+	s := &Sharded[K, V]{
+		shards: make([]*Cache[K, V], n),
+		hash:   hash,
+	}
+	for i := range s.shards {
+		s.shards[i] = New[K, V](defaultTTL, opts...)
+	}
+	return s
+}
+
+// HashString returns the FNV-1a hash of key, for a Sharded cache.
+func HashString(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Shard returns the shard that holds the item at key.
+func (s *Sharded[K, V]) Shard(key K) *Cache[K, V] {
+	return s.shards[s.hash(key)%uint64(len(s.shards))]
+}
+
+// Shards returns all the shards.
+func (s *Sharded[K, V]) Shards() []*Cache[K, V] {
+	return append([]*Cache[K, V](nil), s.shards...)
+}
+
+// Has returns whether an item for given key is present in the cache.
+func (s *Sharded[K, V]) Has(key K) bool {
+	return s.Shard(key).Has(key)
+}
+
+// Get cached item.
+func (s *Sharded[K, V]) Get(key K) (value V, ok bool) {
+	return s.Shard(key).Get(key)
+}
+
+// Peek gets a cached item without extending its lifetime, like
+// Cache.Peek does.
+func (s *Sharded[K, V]) Peek(key K) (value V, ok bool) {
+	return s.Shard(key).Peek(key)
+}
+
+// TTL returns the remaining lifetime of a cached item, like Cache.TTL
+// does.
+func (s *Sharded[K, V]) TTL(key K) (remaining time.Duration, ok bool) {
+	return s.Shard(key).TTL(key)
+}
+
+// Put a value in cache at the given key, like Cache.Put does.
+func (s *Sharded[K, V]) Put(key K, value V, opts ...CallOption) {
+	s.Shard(key).Put(key, value, opts...)
+}
+
+// PutWithTTL puts a value in cache at the given key, with the given
+// time-to-live.
+func (s *Sharded[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	s.Shard(key).PutWithTTL(key, value, ttl)
+}
+
+// PutUntil puts a value in cache at the given key, to expire at the
+// deadline, like Cache.PutUntil does.
+func (s *Sharded[K, V]) PutUntil(key K, value V, deadline time.Time) {
+	s.Shard(key).PutUntil(key, value, deadline)
+}
+
+// GetOrPut returns the value in cache at the given key, or the one
+// returned by provider, like Cache.GetOrPut does.
+func (s *Sharded[K, V]) GetOrPut(
+	key K,
+	provider Getter[K, V],
+	opts ...CallOption,
+) (value V, ok bool) {
+	return s.Shard(key).GetOrPut(key, provider, opts...)
+}
+
+// Touch a cached value, if present, to extend its lifetime. Returns
+// false if the key has not been found in the cache.
+func (s *Sharded[K, V]) Touch(key K) bool {
+	return s.Shard(key).Touch(key)
+}
+
+// SetTTL sets the time-to-live of a cached item, like Cache.SetTTL does.
+func (s *Sharded[K, V]) SetTTL(key K, ttl time.Duration) bool {
+	return s.Shard(key).SetTTL(key, ttl)
+}
+
+// Drop cached item and return its last value.
+func (s *Sharded[K, V]) Drop(key K) (value V, ok bool) {
+	return s.Shard(key).Drop(key)
+}
+
+// Walk calls f for each item in the shards, one shard after another,
+// until f returns false, like Cache.Walk does.
+func (s *Sharded[K, V]) Walk(f func(key K, value V) bool) {
+	for _, c := range s.shards {
+		stopped := false
+		c.Walk(func(k K, v V) bool {
+			stopped = !f(k, v)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// All returns an iterator over the items in the shards, like
+// Cache.All does.
+func (s *Sharded[K, V]) All() func(yield func(key K, value V) bool) {
+	return s.Walk
+}
+
+// KeySeq returns an iterator over the keys of the items in the shards,
+// like Cache.KeySeq does.
+func (s *Sharded[K, V]) KeySeq() func(yield func(key K) bool) {
+	return keySeq(s.Walk)
+}
+
+// Length of cache is the number of items currently in all the shards.
+func (s *Sharded[K, V]) Length() (n int) {
+	for _, c := range s.shards {
+		n += c.Length()
+	}
+	return
+}
+
+// Stats returns the usage statistics of all the shards combined.
+func (s *Sharded[K, V]) Stats() (st Stats) {
+	for _, c := range s.shards {
+		st.merge(c.Stats())
+	}
+	return
+}
+
+// Clear drops all the items in the shards, one shard after another,
+// and returns their number.
+func (s *Sharded[K, V]) Clear() (n int) {
+	for _, c := range s.shards {
+		n += c.Clear()
+	}
+	return n
+}
+
+// Shutdown terminates the goroutines processing the item expiry timers
+// of all the shards.
+func (s *Sharded[K, V]) Shutdown() {
+	for _, c := range s.shards {
+		c.Shutdown()
+	}
+}
+
+// IsShutDown returns whether item expiry timer processing is terminated
+// in all the shards.
+func (s *Sharded[K, V]) IsShutDown() bool {
+	for _, c := range s.shards {
+		if !c.IsShutDown() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestSharded(t *testing.T) {
+	s := NewSharded[string, int](4, HashString, 2*ttl)
+	for i := 0; i < 100; i++ {
+		s.Put(strconv.Itoa(i), i)
+	}
+	if got := s.Length(); got != 100 {
+		t.Fatalf("Length(): got=%d, want=100", got)
+	}
+	for _, c := range s.Shards() {
+		if c.Length() == 0 {
+			t.Fatal("shard should not be empty")
+		}
+	}
+	if v, ok := s.Get("42"); !ok || v != 42 {
+		t.Fatalf("Get: got=%d, %v, want=42, true", v, ok)
+	}
+	if !s.Shard("42").Has("42") {
+		t.Fatal("Shard should have '42'")
+	}
+	s.Get("nope")
+	st := s.Stats()
+	if st.Items != 100 || st.Hits != 1 || st.Misses != 1 {
+		t.Fatalf("Stats: got %+v", st)
+	}
+
+	time.Sleep(3 * ttl)
+	if got := s.Length(); got != 0 {
+		t.Fatalf("Length() after expiry: got=%d, want=0", got)
+	}
+	if got := s.Stats().Expired; got != 100 {
+		t.Fatalf("Stats().Expired: got=%d, want=100", got)
+	}
+
+	s.Shutdown()
+	if !s.IsShutDown() {
+		t.Fatal("should be shut down")
+	}
+}
+
+func TestShardedWalk(t *testing.T) {
+	s := NewSharded[string, int](4, HashString, time.Minute)
+	defer s.Shutdown()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		s.Put(k, 1)
+	}
+
+	sum := 0
+	s.Walk(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 5 {
+		t.Errorf("Walk visited %d items, want 5", sum)
+	}
+	visited := 0
+	s.Walk(func(string, int) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Walk stopped after %d, want 1", visited)
+	}
+}
+
+func TestShardedPutUntil(t *testing.T) {
+	s := NewSharded[string, int](4, HashString, time.Minute)
+	defer s.Shutdown()
+
+	// A wall-clock deadline, e.g., of a token, without a monotonic reading.
+	s.PutUntil("a", 1, time.Unix(time.Now().Add(ttl).Unix()+1, 0))
+	if _, ok := s.Get("a"); !ok {
+		t.Fatal("should have 'a'")
+	}
+	d, ok := s.TTL("a")
+	if !ok || d > time.Second+ttl {
+		t.Fatalf("TTL() got=%v,%v, want at most %v", d, ok, time.Second+ttl)
+	}
+}
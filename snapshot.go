@@ -0,0 +1,373 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrSnapshotVersion is returned when restoring a snapshot written in
+// an unsupported format version.
+var ErrSnapshotVersion = errors.New("cache: unsupported snapshot version")
+
+// ErrQuiesced is returned when restoring a snapshot to, or putting a
+// value with PutE in, a quiesced cache.
+var ErrQuiesced = errors.New("cache: quiesced")
+
+// Dump writes a full, uncompressed snapshot of the cache to w.
+//
+// Both the keys and the values must be encodable by encoding/gob, or,
+// for the keys, be encoding.TextMarshalers, which are written in their
+// textual forms. The items are copied (shallowly) before they are
+// written, so the cache remains usable for the duration of the write,
+// which does not reflect any changes made after Dump was called.
+func (c *Cache[K, V]) Dump(w io.Writer) error {
+	_, err := c.dump(w, snapshotMark{})
+	return err
+}
+
+// Restore puts the items from a snapshot read from r in the cache, with
+// the expiry times recorded in the snapshot, and drops the ones that an
+// incremental snapshot records as dropped. Items that have expired
+// since the snapshot was taken are skipped.
+//
+// Compressed snapshots are detected and decompressed automatically.
+// Restoring a full snapshot followed by the incremental ones taken
+// after it, in order, reproduces the contents of the original cache.
+func (c *Cache[K, V]) Restore(r io.Reader) error {
+	br := bufio.NewReader(r)
+	r = br
+	if magic, err := br.Peek(2); err == nil &&
+		magic[0] == gzipID1 && magic[1] == gzipID2 {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	d := gob.NewDecoder(r)
+	var h snapshotHeader
+	if err := d.Decode(&h); err != nil {
+		return err
+	}
+	if h.Version != snapshotVersion {
+		return ErrSnapshotVersion
+	}
+	drops := make([]K, h.Drops)
+	for i := range drops {
+		if err := decodeKey(d, &drops[i], h.TextKeys); err != nil {
+			return err
+		}
+	}
+	items := make([]snapshotItem[K, V], h.Items)
+	for i := range items {
+		if err := decodeItem(d, &items[i], h.TextKeys); err != nil {
+			return err
+		}
+	}
+
+	c.lock()
+	defer c.unlock()
+	if c.quiet {
+		return ErrQuiesced
+	}
+	for _, k := range drops {
+		if val, found := c.d[k]; found {
+			c.drop(k, val, ReasonDropped)
+		}
+	}
+	now := time.Now()
+	for _, it := range items {
+		c.restore(it, now)
+	}
+	for c.processTimers() {
+	}
+	return nil
+}
+
+// RestoreFrom restores a snapshot from the reader returned by open,
+// closing it afterwards.
+func (c *Cache[K, V]) RestoreFrom(open func() (io.ReadCloser, error)) error {
+	r, err := open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return c.Restore(r)
+}
+
+// SnapshotConfig configures a Snapshotter.
+type SnapshotConfig struct {
+	// Compress snapshots with gzip.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+	// FullEvery is the number of snapshots in a cycle that starts with
+	// a full baseline followed by incremental ones, which only contain
+	// the items put, dropped, or with lifetimes extended (e.g., by
+	// lookups) since the previous snapshot. Values
+	// below 2 make every snapshot full.
+	FullEvery int `json:"fullEvery,omitempty" yaml:"fullEvery,omitempty"`
+
+	// Path is the file that a cache constructed by FromConfig is
+	// restored from, and persisted to, if not empty. Full snapshots are
+	// written to it, and the incremental ones after each to Path.1,
+	// Path.2, and so on, each to a temporary file first, renamed once
+	// written. The shards of a cache constructed by ShardedFromConfig
+	// are persisted to the files at Path followed by a hyphen and the
+	// index of the shard, e.g., Path-0.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Every is the interval of the snapshots persisting a cache to
+	// Path, e.g., "1m", parsed by time.ParseDuration; required with it.
+	Every string `json:"every,omitempty" yaml:"every,omitempty"`
+}
+
+// A Snapshotter writes consecutive snapshots of a cache.
+//
+// It is not safe for concurrent use, and there should be no more than
+// one incremental Snapshotter per cache.
+type Snapshotter[K comparable, V any] struct {
+	c   *Cache[K, V]
+	cfg SnapshotConfig
+	at  snapshotMark // Cache generations at the previous snapshot.
+	n   int          // Number of snapshots written.
+}
+
+// NewSnapshotter returns a new Snapshotter for c.
+func NewSnapshotter[K comparable, V any](
+	c *Cache[K, V],
+	cfg SnapshotConfig,
+) *Snapshotter[K, V] {
+	if cfg.FullEvery > 1 {
+		c.lock()
+		if c.tombs == nil {
+			c.tombs = make(map[K]uint64)
+		}
+		c.m.Unlock()
+	}
+	return &Snapshotter[K, V]{
+		c:   c,
+		cfg: cfg,
+	}
+}
+
+// Dump writes the next snapshot in the cycle to w.
+func (s *Snapshotter[K, V]) Dump(w io.Writer) error {
+	at, err := s.write(w)
+	if err != nil {
+		return err
+	}
+	s.commit(at)
+	return nil
+}
+
+// DumpTo writes the next snapshot in the cycle to the writer returned
+// by create, closing it afterwards. The snapshot is only considered
+// written when closing succeeds, which lets writers that finalize
+// their output on Close (e.g., uploads) report failures.
+func (s *Snapshotter[K, V]) DumpTo(create func() (io.WriteCloser, error)) error {
+	w, err := create()
+	if err != nil {
+		return err
+	}
+	at, err := s.write(w)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	s.commit(at)
+	return nil
+}
+
+// Internals.
+
+const (
+	snapshotVersion = 1
+
+	gzipID1 = 0x1f
+	gzipID2 = 0x8b
+)
+
+// A snapshotMark records the generations of a cache at a snapshot: of
+// its modifications, and of its expiry schedule, which lookups and
+// touches change without modifying the items.
+type snapshotMark struct {
+	gen, xgen uint64
+}
+
+type snapshotHeader struct {
+	Version int
+	Drops   int // Number of dropped keys that follow.
+	Items   int // Number of items that follow the dropped keys.
+
+	TextKeys bool // Whether the keys are in their textual forms.
+}
+
+type snapshotItem[K comparable, V any] struct {
+	Key      K
+	Value    V
+	TTL      time.Duration
+	Expires  time.Time
+	Tags     []string
+	Pinned   bool
+	Fixed    bool
+	Inserted time.Time
+}
+
+// dump the items modified or rescheduled after the since mark and the
+// keys dropped after it, unless it is zero, to w. Returns the mark of
+// the cache at the time of the snapshot.
+//
+// The lock is only held while the items are copied, the copies are
+// encoded after it is released, so that other goroutines can keep using
+// the cache while the snapshot is being written.
+func (c *Cache[K, V]) dump(w io.Writer, since snapshotMark) (snapshotMark, error) {
+	drops, items, at := c.copyModified(since)
+	text := textKeyed[K]()
+
+	e := gob.NewEncoder(w)
+	err := e.Encode(snapshotHeader{
+		Version:  snapshotVersion,
+		Drops:    len(drops),
+		Items:    len(items),
+		TextKeys: text,
+	})
+	if err != nil {
+		return snapshotMark{}, err
+	}
+	for i := range drops {
+		if err := encodeKey(e, drops[i], text); err != nil {
+			return snapshotMark{}, err
+		}
+	}
+	for i := range items {
+		if err := encodeItem(e, items[i], text); err != nil {
+			return snapshotMark{}, err
+		}
+	}
+	return at, nil
+}
+
+// copyModified returns the keys dropped after the since mark, unless it
+// is zero, copies of the items modified or rescheduled after it, and the
+// current mark of the cache.
+func (c *Cache[K, V]) copyModified(since snapshotMark) (
+	drops []K,
+	items []snapshotItem[K, V],
+	at snapshotMark,
+) {
+	c.lock()
+	defer c.m.Unlock()
+
+	if since.gen > 0 {
+		for k, g := range c.tombs {
+			if g > since.gen {
+				drops = append(drops, k)
+			}
+		}
+	}
+	if since.gen == 0 {
+		items = make([]snapshotItem[K, V], 0, len(c.d))
+	}
+	for k, val := range c.d {
+		if val.gen <= since.gen && val.t.xgen <= since.xgen {
+			continue
+		}
+		items = append(items, snapshotItem[K, V]{
+			Key:      k,
+			Value:    val.Value(),
+			TTL:      val.ttl,
+			Expires:  val.t.x,
+			Tags:     append([]string(nil), val.tags...),
+			Pinned:   val.pin,
+			Fixed:    val.fixed,
+			Inserted: val.put,
+		})
+	}
+	return drops, items, snapshotMark{c.gen, c.xgen}
+}
+
+// restore the item it as of now, unless it has expired, and return
+// whether it has been restored. The cache must be locked.
+func (c *Cache[K, V]) restore(it snapshotItem[K, V], now time.Time) bool {
+	if !it.Expires.After(now) {
+		return false
+	}
+	// Decoded times have no monotonic clock reading, which all expiry
+	// times must have.
+	x := now.Add(it.Expires.Sub(now))
+	val, found := c.d[it.Key]
+	val.v = it.Value
+	val.cold = nil
+	val.lease = nil
+	val.costSet = false
+	val.provider = nil
+	val.ttl = it.TTL
+	val.pin = it.Pinned
+	val.fixed = it.Fixed
+	val.put = it.Inserted
+	val.limit = time.Time{}
+	val.origin = ""
+	if found {
+		c.resetTimerAt(val.t, x)
+	} else {
+		val.t = c.addTimerAt(it.Key, x)
+	}
+	c.dedup(&val)
+	c.emit(EventPut, it.Key, it.Value, 0)
+	c.store(it.Key, val)
+	if len(it.Tags) > 0 {
+		c.tag(it.Key, it.Tags)
+	}
+	return true
+}
+
+// write the next snapshot in the cycle to w and return the mark of the
+// cache it was taken at.
+func (s *Snapshotter[K, V]) write(w io.Writer) (at snapshotMark, err error) {
+	var since snapshotMark
+	if s.cfg.FullEvery > 1 && s.n%s.cfg.FullEvery != 0 {
+		since = s.at
+	}
+	if s.cfg.Compress {
+		zw := gzip.NewWriter(w)
+		defer func() {
+			if zErr := zw.Close(); err == nil {
+				err = zErr
+			}
+		}()
+		w = zw
+	}
+	return s.c.dump(w, since)
+}
+
+// commit a snapshot taken at the at mark as the previous one.
+func (s *Snapshotter[K, V]) commit(at snapshotMark) {
+	if s.cfg.FullEvery > 1 {
+		s.c.pruneTombs(at.gen)
+	}
+	s.at = at
+	s.n++
+}
+
+// pruneTombs forgets the keys dropped up to (and including) the gen
+// generation.
+func (c *Cache[K, V]) pruneTombs(gen uint64) {
+	c.lock()
+	defer c.m.Unlock()
+	for k, g := range c.tombs {
+		if g <= gen {
+			delete(c.tombs, k)
+		}
+	}
+}
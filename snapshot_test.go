@@ -0,0 +1,299 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestDumpRestore(t *testing.T) {
+	src := New[string, int](time.Minute)
+	defer src.Shutdown()
+	src.Put("a", 1)
+	src.Put("b", 2)
+	src.PutWithTTL("short", 3, ttl)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	time.Sleep(ttl)
+
+	dst := New[string, int](time.Minute)
+	defer dst.Shutdown()
+	req := newAssert(t, dst, true)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	req.LengthIs(2)
+	req.Assert(req.Get("a") == 1, "Get(a) should be 1")
+	req.Assert(req.Get("b") == 2, "Get(b) should be 2")
+	req.HasNot("short")
+}
+
+func TestDumpRestoreTextKeys(t *testing.T) {
+	src := New[textKey, int](time.Minute)
+	defer src.Shutdown()
+	src.Put(textKey{1, 2}, 3)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	dst := New[textKey, int](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if v, ok := dst.Get(textKey{1, 2}); !ok || v != 3 {
+		t.Errorf("Get() got=%v,%v, want=3,true", v, ok)
+	}
+}
+
+// textKey has no exported fields, but can be marshaled as text.
+type textKey struct{ x, y int }
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", k.x, k.y)), nil
+}
+
+func (k *textKey) UnmarshalText(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "%d,%d", &k.x, &k.y)
+	return err
+}
+
+func TestSnapshotterCompressed(t *testing.T) {
+	src := New[int, string](time.Minute)
+	defer src.Shutdown()
+	for i := 0; i < 100; i++ {
+		src.Put(i, "value")
+	}
+
+	var buf bytes.Buffer
+	s := NewSnapshotter(src, SnapshotConfig{Compress: true})
+	if err := s.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	if _, err := gzip.NewReader(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("snapshot should be gzipped: %v", err)
+	}
+
+	dst := New[int, string](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	newAssert(t, dst, true).LengthIs(100)
+}
+
+func TestSnapshotterIncremental(t *testing.T) {
+	src := New[string, int](time.Minute)
+	defer src.Shutdown()
+	s := NewSnapshotter(src, SnapshotConfig{FullEvery: 3})
+
+	var snaps []*bytes.Buffer
+	dump := func() int {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := s.Dump(buf); err != nil {
+			t.Fatalf("Dump() error: %v", err)
+		}
+		snaps = append(snaps, buf)
+		return buf.Len()
+	}
+
+	src.Put("a", 1)
+	src.Put("b", 2)
+	src.Put("c", 3)
+	full := dump()
+
+	src.Put("a", 10)
+	src.Drop("b")
+	delta := dump()
+	if delta >= full {
+		t.Errorf("delta size %d should be less than full %d", delta, full)
+	}
+
+	src.Put("b", 20)
+	src.Drop("c")
+	dump()
+
+	dst := New[string, int](time.Minute)
+	defer dst.Shutdown()
+	req := newAssert(t, dst, true)
+	for i, buf := range snaps {
+		if err := dst.Restore(buf); err != nil {
+			t.Fatalf("Restore() snapshot %d error: %v", i, err)
+		}
+	}
+	req.LengthIs(2)
+	req.Assert(req.Get("a") == 10, "Get(a) should be 10")
+	req.Assert(req.Get("b") == 20, "Get(b) should be 20")
+	req.HasNot("c")
+
+	// The cycle starts over with a full baseline.
+	dump()
+	dst = New[string, int](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(snaps[len(snaps)-1]); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	newAssert(t, dst, true).LengthIs(2)
+}
+
+func TestSnapshotterIncrementalExpiry(t *testing.T) {
+	src := New[string, int](time.Minute,
+		WithTouchPolicy(TouchPolicy{Extend: time.Hour}))
+	defer src.Shutdown()
+	s := NewSnapshotter(src, SnapshotConfig{FullEvery: 2})
+
+	src.Put("a", 1)
+	var base, delta bytes.Buffer
+	if err := s.Dump(&base); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	src.Touch("a") // Extends the lifetime without modifying the item.
+	if err := s.Dump(&delta); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+
+	dst := New[string, int](time.Minute)
+	defer dst.Shutdown()
+	for _, buf := range []*bytes.Buffer{&base, &delta} {
+		if err := dst.Restore(buf); err != nil {
+			t.Fatalf("Restore() error: %v", err)
+		}
+	}
+	if d, ok := dst.TTL("a"); !ok || d < time.Hour {
+		t.Errorf("TTL(a) got=%v,%v, want over an hour, as touched", d, ok)
+	}
+}
+
+func TestDumpDoesNotBlock(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	for i := 0; i < 10; i++ {
+		c.Put(i, i)
+	}
+
+	w := &blockingWriter{
+		writing: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	done := make(chan error)
+	go func() {
+		done <- c.Dump(w)
+	}()
+	<-w.writing
+
+	put := make(chan struct{})
+	go func() {
+		c.Put(10, 10)
+		close(put)
+	}()
+	select {
+	case <-put:
+	case <-time.After(time.Second):
+		t.Fatal("Put blocked while Dump was writing")
+	}
+	close(w.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+
+	dst := New[int, int](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(&w.buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	newAssert(t, dst, true).LengthIs(10)
+}
+
+func TestDumpTo(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("a", 1)
+	s := NewSnapshotter(c, SnapshotConfig{FullEvery: 2})
+
+	errClose := errors.New("close failed")
+	w := &closer{err: errClose}
+	err := s.DumpTo(func() (io.WriteCloser, error) { return w, nil })
+	if !errors.Is(err, errClose) {
+		t.Fatalf("DumpTo() error: got=%v, want=%v", err, errClose)
+	}
+
+	// The failed baseline is written again.
+	w = &closer{}
+	if err := s.DumpTo(func() (io.WriteCloser, error) { return w, nil }); err != nil {
+		t.Fatalf("DumpTo() error: %v", err)
+	}
+	if !w.closed {
+		t.Error("DumpTo() should close the writer")
+	}
+
+	dst := New[string, int](time.Minute)
+	defer dst.Shutdown()
+	err = dst.RestoreFrom(func() (io.ReadCloser, error) {
+		return io.NopCloser(&w.buf), nil
+	})
+	if err != nil {
+		t.Fatalf("RestoreFrom() error: %v", err)
+	}
+	newAssert(t, dst, true).Has("a")
+}
+
+func TestRestoreVersion(t *testing.T) {
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(struct{ Version int }{Version: 0})
+
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	err := c.Restore(&buf)
+	if !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("Restore() error: got=%v, want=%v", err, ErrSnapshotVersion)
+	}
+}
+
+// blockingWriter signals on its first write and blocks until released.
+type blockingWriter struct {
+	buf     bytes.Buffer
+	once    sync.Once
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.writing)
+		<-w.release
+	})
+	return w.buf.Write(p)
+}
+
+// closer is a writer that fails to close with err, if not nil.
+type closer struct {
+	buf    bytes.Buffer
+	err    error
+	closed bool
+}
+
+func (w *closer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *closer) Close() error {
+	w.closed = true
+	return w.err
+}
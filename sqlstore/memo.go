@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antichris/go-cache/codec"
+)
+
+func init() {
+	// Values of the columns of this type are held by Rows as any.
+	gob.Register(time.Time{})
+}
+
+// Rows are the memoized result of a query.
+type Rows struct {
+	Columns []string
+	// Values of the columns of each row, as scanned into any: int64,
+	// float64, bool, []byte, string, time.Time, or nil.
+	Values [][]any
+}
+
+// A Memo caches the results of read-only queries to a database, for
+// repeated queries to be answered without it.
+type Memo struct {
+	db *sql.DB
+	c  *codec.Cache[string, Rows]
+}
+
+// NewMemo returns a new Memo querying db and caching the results in c.
+//
+// The results should be serialized by codec.Gob, which preserves the
+// types of the values, unlike codec.JSON. They are cached with the TTLs
+// set by the rules of the inner cache of c, if any, see QueryKey, or
+// its default one.
+func NewMemo(db *sql.DB, c *codec.Cache[string, Rows]) *Memo {
+	return &Memo{db: db, c: c}
+}
+
+// Query returns the cached result of query with args, if any, or
+// queries the database and caches the result.
+func (m *Memo) Query(ctx context.Context, query string, args ...any) (*Rows, error) {
+	key := QueryKey(query, args...)
+	if r, ok, err := m.c.Get(key); ok && err == nil {
+		return &r, nil
+	}
+	r, err := m.query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.c.Put(key, *r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Forget the cached result of query with args and return whether it
+// was present.
+func (m *Memo) Forget(query string, args ...any) bool {
+	return m.c.Drop(QueryKey(query, args...))
+}
+
+// QueryKey returns the key that the result of query with args is cached
+// at: the query, with its whitespace normalized, followed by a digest
+// of the args, so that TTL rules, e.g., PrefixRule, can match queries.
+func QueryKey(query string, args ...any) string {
+	h := sha256.New()
+	for _, a := range args {
+		fmt.Fprintf(h, "%T:%v\x00", a, a)
+	}
+	return strings.Join(strings.Fields(query), " ") + " #" +
+		hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Internals.
+
+// query the database and read all of the rows of the result.
+func (m *Memo) query(ctx context.Context, query string, args []any) (*Rows, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	r := &Rows{Columns: cols}
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		r.Values = append(r.Values, vals)
+	}
+	return r, rows.Err()
+}
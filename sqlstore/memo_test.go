@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	"github.com/antichris/go-cache/codec"
+	. "github.com/antichris/go-cache/sqlstore"
+)
+
+func TestMemo(t *testing.T) {
+	db, conn := openFakeDBConnector(t)
+	s := New[string, int](db, Config{})
+	ctx := context.Background()
+	if err := s.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable() error: %v", err)
+	}
+	if err := s.Put("a", 1, time.Hour); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	conn.queries = 0
+
+	c := cache.New[string, []byte](time.Minute, cache.WithTTLRules(
+		cache.PrefixRule("SELECT v, x FROM cache", time.Hour),
+	))
+	defer c.Shutdown()
+	m := NewMemo(db, codec.New[string, Rows](c, codec.Gob))
+
+	const q = "SELECT v, x FROM cache\n\tWHERE k = ?"
+	const a = `"a"` // Keys are stored as their JSON.
+	for i := 0; i < 2; i++ {
+		r, err := m.Query(ctx, q, a)
+		if err != nil {
+			t.Fatalf("Query() error: %v", err)
+		}
+		if len(r.Values) != 1 || r.Values[0][0] != "1" || r.Columns[1] != "x" {
+			t.Errorf("Query() got=%+v", r)
+		}
+	}
+	if conn.queries != 1 {
+		t.Errorf("queries: got=%d, want=1", conn.queries)
+	}
+	if _, err := m.Query(ctx, q, "b"); err != nil || conn.queries != 2 {
+		t.Errorf("Query() with other args got %d queries, %v, want=2, nil",
+			conn.queries, err)
+	}
+
+	key := QueryKey(q, a)
+	if !strings.HasPrefix(key, "SELECT v, x FROM cache WHERE k = ? #") {
+		t.Errorf("QueryKey() got=%q, want the normalized query", key)
+	}
+	if e, ok := c.DropEntry(key); !ok || e.TTL != time.Hour {
+		t.Errorf("cached result TTL got=%v,%v, want=%v", e.TTL, ok, time.Hour)
+	}
+	if m.Forget(q, a) {
+		t.Error("Forget() should not find a dropped result")
+	}
+}
@@ -0,0 +1,200 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package sqlstore implements a cache.Store over a database/sql table.
+//
+// The table has a key column, a value column and an expiry column.
+// Both the keys and the values are stored as their JSON. Expired rows
+// are purged lazily, when they are read and periodically on writes.
+//
+// A Memo caches the results of queries to a database in a cache.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/antichris/go-cache"
+)
+
+// Config of a Store.
+type Config struct {
+	// Table name; "cache", if empty.
+	Table string
+	// Numbered placeholders ($1, $2, ...) are used in queries, as
+	// required by PostgreSQL, instead of the question marks (?).
+	Numbered bool
+	// PurgeEvery is the number of writes after which expired rows are
+	// purged; 1000, if zero. Negative values disable that.
+	PurgeEvery int
+}
+
+// Store keeps values in a database/sql table.
+type Store[K comparable, V any] struct {
+	n   int64 // Number of writes; accessed atomically.
+	db  *sql.DB
+	cfg Config
+	q   queries
+}
+
+var _ cache.Store[string, any] = (*Store[string, any])(nil)
+
+// New returns a new Store using db with the given configuration.
+func New[K comparable, V any](db *sql.DB, cfg Config) *Store[K, V] {
+	if cfg.Table == "" {
+		cfg.Table = "cache"
+	}
+	if cfg.PurgeEvery == 0 {
+		cfg.PurgeEvery = 1000
+	}
+	return &Store[K, V]{
+		db:  db,
+		cfg: cfg,
+		q:   newQueries(cfg.Table, cfg.Numbered),
+	}
+}
+
+// CreateTable creates the table for the store, if it does not exist.
+func (s *Store[K, V]) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.q.create)
+	return err
+}
+
+// Get the value at key. Any errors are treated as a miss.
+func (s *Store[K, V]) Get(key K) (value V, ok bool) {
+	value, ok, _ = s.GetContext(context.Background(), key)
+	return
+}
+
+// GetContext gets the value at key, deleting it, if expired.
+func (s *Store[K, V]) GetContext(
+	ctx context.Context,
+	key K,
+) (value V, ok bool, err error) {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return
+	}
+	var (
+		v []byte
+		x int64
+	)
+	err = s.db.QueryRowContext(ctx, s.q.get, string(k)).Scan(&v, &x)
+	if errors.Is(err, sql.ErrNoRows) {
+		return value, false, nil
+	}
+	if err != nil {
+		return
+	}
+	if x <= time.Now().UnixNano() {
+		_, err = s.db.ExecContext(ctx, s.q.drop, string(k))
+		return
+	}
+	if err = json.Unmarshal(v, &value); err != nil {
+		return
+	}
+	return value, true, nil
+}
+
+// Put value at key, to expire after ttl.
+func (s *Store[K, V]) Put(key K, value V, ttl time.Duration) error {
+	return s.PutContext(context.Background(), key, value, ttl)
+}
+
+// PutContext puts value at key, to expire after ttl.
+func (s *Store[K, V]) PutContext(
+	ctx context.Context,
+	key K,
+	value V,
+	ttl time.Duration,
+) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	x := time.Now().Add(ttl).UnixNano()
+
+	// A delete and an insert in a transaction is the most portable way
+	// to upsert.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, s.q.drop, string(k)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.q.put, string(k), string(v), x); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if n := int64(s.cfg.PurgeEvery); n > 0 && atomic.AddInt64(&s.n, 1)%n == 0 {
+		_, err = s.Purge(ctx)
+	}
+	return err
+}
+
+// Drop the value at key.
+func (s *Store[K, V]) Drop(key K) error {
+	return s.DropContext(context.Background(), key)
+}
+
+// DropContext drops the value at key.
+func (s *Store[K, V]) DropContext(ctx context.Context, key K) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.q.drop, string(k))
+	return err
+}
+
+// Purge deletes all expired rows and returns their number.
+func (s *Store[K, V]) Purge(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.q.purge, time.Now().UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Internals.
+
+type queries struct {
+	create string
+	get    string
+	put    string
+	drop   string
+	purge  string
+}
+
+func newQueries(table string, numbered bool) queries {
+	ph := func(n int) string { return "?" }
+	if numbered {
+		ph = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+	r := strings.NewReplacer("{t}", table,
+		"{1}", ph(1), "{2}", ph(2), "{3}", ph(3))
+	return queries{
+		create: r.Replace("CREATE TABLE IF NOT EXISTS {t} (" +
+			"k VARCHAR(255) PRIMARY KEY, v TEXT NOT NULL, x BIGINT NOT NULL)"),
+		get:   r.Replace("SELECT v, x FROM {t} WHERE k = {1}"),
+		put:   r.Replace("INSERT INTO {t} (k, v, x) VALUES ({1}, {2}, {3})"),
+		drop:  r.Replace("DELETE FROM {t} WHERE k = {1}"),
+		purge: r.Replace("DELETE FROM {t} WHERE x <= {1}"),
+	}
+}
@@ -0,0 +1,225 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/antichris/go-cache"
+	. "github.com/antichris/go-cache/sqlstore"
+)
+
+func TestStore(t *testing.T) {
+	db := openFakeDB(t)
+	s := New[string, []int](db, Config{Table: "test", PurgeEvery: 2})
+	ctx := context.Background()
+	if err := s.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable() error: %v", err)
+	}
+
+	if _, ok := s.Get("a"); ok {
+		t.Error("should not get 'a' from an empty store")
+	}
+	if err := s.Put("a", []int{1, 2}, time.Minute); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if v, ok := s.Get("a"); !ok || len(v) != 2 || v[1] != 2 {
+		t.Errorf("Get(a) got=%v,%v, want=[1 2],true", v, ok)
+	}
+
+	if err := s.Put("b", nil, -time.Second); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if _, ok := s.Get("b"); ok {
+		t.Error("should not get expired 'b'")
+	}
+
+	if err := s.Drop("a"); err != nil {
+		t.Fatalf("Drop() error: %v", err)
+	}
+	if _, ok := s.Get("a"); ok {
+		t.Error("should not get dropped 'a'")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	db := openFakeDB(t)
+	s := New[int, int](db, Config{PurgeEvery: -1})
+	ctx := context.Background()
+	s.CreateTable(ctx)
+	s.Put(1, 1, -time.Second)
+	s.Put(2, 2, -time.Second)
+	s.Put(3, 3, time.Minute)
+
+	n, err := s.Purge(ctx)
+	if err != nil || n != 2 {
+		t.Errorf("Purge() got=%d,%v, want=2,nil", n, err)
+	}
+}
+
+func TestNumbered(t *testing.T) {
+	db := openFakeDB(t)
+	s := New[int, int](db, Config{Numbered: true})
+	ctx := context.Background()
+	s.CreateTable(ctx)
+	if err := s.Put(1, 1, time.Minute); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if v, ok := s.Get(1); !ok || v != 1 {
+		t.Errorf("Get(1) got=%v,%v, want=1,true", v, ok)
+	}
+}
+
+func TestTiered(t *testing.T) {
+	db := openFakeDB(t)
+	l1 := cache.New[string, string](time.Minute)
+	defer l1.Shutdown()
+	s := New[string, string](db, Config{})
+	s.CreateTable(context.Background())
+	c := cache.NewTiered[string, string](l1, s)
+
+	if err := c.Put("a", "b"); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	l1.Drop("a")
+	if v, ok := c.Get("a"); !ok || v != "b" {
+		t.Errorf("Get(a) got=%q,%v, want=%q,true", v, ok, "b")
+	}
+}
+
+// A fake database/sql driver that understands just the queries that
+// the Store makes.
+
+func openFakeDB(t *testing.T) *sql.DB {
+	db, _ := openFakeDBConnector(t)
+	return db
+}
+
+func openFakeDBConnector(t *testing.T) (*sql.DB, *fakeConnector) {
+	t.Helper()
+	c := &fakeConnector{tables: map[string]map[string]fakeRow{}}
+	db := sql.OpenDB(c)
+	t.Cleanup(func() { db.Close() })
+	return db, c
+}
+
+type fakeRow struct {
+	v string
+	x int64
+}
+
+type fakeConnector struct {
+	m       sync.Mutex
+	tables  map[string]map[string]fakeRow
+	queries int
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	c *fakeConnector
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.c, query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error { return nil }
+
+func (c *fakeConn) Rollback() error { return nil }
+
+type fakeStmt struct {
+	c     *fakeConnector
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.m.Lock()
+	defer s.c.m.Unlock()
+	f := strings.Fields(s.query)
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS "):
+		if s.c.tables[f[5]] == nil {
+			s.c.tables[f[5]] = map[string]fakeRow{}
+		}
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT INTO "):
+		s.c.tables[f[2]][args[0].(string)] = fakeRow{
+			v: args[1].(string),
+			x: args[2].(int64),
+		}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE FROM ") && f[4] == "k":
+		rows := s.c.tables[f[2]]
+		_, ok := rows[args[0].(string)]
+		delete(rows, args[0].(string))
+		if ok {
+			return driver.RowsAffected(1), nil
+		}
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "DELETE FROM ") && f[4] == "x":
+		var n int64
+		for k, r := range s.c.tables[f[2]] {
+			if r.x <= args[0].(int64) {
+				delete(s.c.tables[f[2]], k)
+				n++
+			}
+		}
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.m.Lock()
+	defer s.c.m.Unlock()
+	f := strings.Fields(s.query)
+	if !strings.HasPrefix(s.query, "SELECT v, x FROM ") {
+		return nil, fmt.Errorf("unexpected query: %s", s.query)
+	}
+	s.c.queries++
+	rows := &fakeRows{}
+	if r, ok := s.c.tables[f[4]][args[0].(string)]; ok {
+		rows.rows = append(rows.rows, r)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	rows []fakeRow
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v", "x"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[0].v, r.rows[0].x
+	r.rows = r.rows[1:]
+	return nil
+}
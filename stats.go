@@ -0,0 +1,181 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Stats of cache usage.
+type Stats struct {
+	Items   int    // Number of items currently in the cache.
+	Bytes   int64  // Total size of the items currently in the cache.
+	Hits    uint64 // Number of lookups that found an item.
+	Misses  uint64 // Number of lookups that did not find an item.
+	Expired uint64 // Number of items dropped due to expiry.
+	Evicted uint64 // Number of items dropped due to the cache limits.
+	Invalid uint64 // Number of items dropped failing validation.
+
+	Throttled uint64 // Number of writes over quota, see WithWriteQuotas.
+	Corrupt   uint64 // Number of failed integrity checks.
+	Unsaved   uint64 // Number of failed snapshots, see SnapshotConfig.Path.
+
+	ExpiryLag    time.Duration // How long the soonest expiry is overdue.
+	MaxExpiryLag time.Duration // Longest delay of an expiry past its time.
+
+	Loads     uint64        // Number of provider calls on misses.
+	LoadTime  time.Duration // Total time spent in provider calls.
+	LoadTimes Histogram     // Distribution of provider call durations.
+
+	Coalesced  uint64 // Number of misses that waited for a load in flight.
+	Suppressed uint64 // Number of loads that spared waiters calling provider.
+
+	// Sampled waits for the cache lock, see WithContentionStats.
+	LockWaits    uint64        // Number of waits sampled.
+	LockWaitTime time.Duration // Total time of the waits sampled.
+	LockWaitDist Histogram     // Distribution of the waits sampled.
+}
+
+// A Histogram of durations in exponentially growing buckets: bucket i
+// counts durations under HistogramBound(i), the last one all the rest.
+type Histogram [histogramBuckets]uint64
+
+// HistogramBound returns the upper bound of bucket i of a Histogram,
+// which is 2^i microseconds.
+func HistogramBound(i int) time.Duration {
+	return time.Microsecond << i
+}
+
+// Count returns the total number of durations in the histogram.
+func (h *Histogram) Count() (n uint64) {
+	for _, c := range h {
+		n += c
+	}
+	return
+}
+
+// Quantile returns the upper bound of the bucket that the q-quantile
+// (0 < q <= 1) of the durations falls in, e.g., Quantile(0.99) for the
+// 99th percentile, or zero if the histogram is empty.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(n))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, c := range h {
+		if seen += c; seen >= rank {
+			return HistogramBound(i)
+		}
+	}
+	return HistogramBound(len(h) - 1)
+}
+
+// add d to the histogram.
+func (h *Histogram) add(d time.Duration) {
+	i := 0
+	for i < len(h)-1 && d >= HistogramBound(i) {
+		i++
+	}
+	h[i]++
+}
+
+// load records a provider call that took d.
+func (s *Stats) load(d time.Duration) {
+	s.Loads++
+	s.LoadTime += d
+	s.LoadTimes.add(d)
+}
+
+// lockWait records a sampled wait for the cache lock that took d.
+func (s *Stats) lockWait(d time.Duration) {
+	s.LockWaits++
+	s.LockWaitTime += d
+	s.LockWaitDist.add(d)
+}
+
+// Stats returns the usage statistics of the cache.
+func (c *Cache[K, V]) Stats() Stats {
+	c.lock()
+	defer c.m.Unlock()
+	s := c.stats
+	s.Hits += atomic.LoadUint64(&c.hits)
+	s.Misses += atomic.LoadUint64(&c.misses)
+	s.Items = len(c.d)
+	s.Bytes = c.bytes
+	s.ExpiryLag = c.overdue(time.Now())
+	return s
+}
+
+// ExpiryHistogram returns the numbers of cached items by remaining
+// lifetime, in buckets bounded by bounds, in ascending order: bucket i
+// counts the items expiring in under bounds[i], and the last one, at
+// len(bounds), all the rest, including the pinned ones.
+//
+// It shows, e.g., whether a cache is about to lose most of its items at
+// once, and should be warmed in advance.
+func (c *Cache[K, V]) ExpiryHistogram(bounds []time.Duration) []int {
+	h := make([]int, len(bounds)+1)
+	c.lock()
+	defer c.m.Unlock()
+	now := time.Now()
+	for _, t := range c.th {
+		left := t.x.Sub(now)
+		i := sort.Search(len(bounds), func(i int) bool {
+			return left < bounds[i]
+		})
+		h[i]++
+	}
+	return h
+}
+
+const histogramBuckets = 32
+
+// merge the counts of o into the stats.
+func (s *Stats) merge(o Stats) {
+	s.Items += o.Items
+	s.Bytes += o.Bytes
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Expired += o.Expired
+	s.Evicted += o.Evicted
+	s.Invalid += o.Invalid
+	s.Throttled += o.Throttled
+	s.Corrupt += o.Corrupt
+	s.Unsaved += o.Unsaved
+	if o.MaxExpiryLag > s.MaxExpiryLag {
+		s.MaxExpiryLag = o.MaxExpiryLag
+	}
+	if o.ExpiryLag > s.ExpiryLag {
+		s.ExpiryLag = o.ExpiryLag
+	}
+	s.Loads += o.Loads
+	s.LoadTime += o.LoadTime
+	for i, n := range o.LoadTimes {
+		s.LoadTimes[i] += n
+	}
+	s.Coalesced += o.Coalesced
+	s.Suppressed += o.Suppressed
+	s.LockWaits += o.LockWaits
+	s.LockWaitTime += o.LockWaitTime
+	for i, n := range o.LockWaitDist {
+		s.LockWaitDist[i] += n
+	}
+}
+
+// count a lookup as a hit or a miss.
+func (s *Stats) count(hit bool) {
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+}
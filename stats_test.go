@@ -0,0 +1,179 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestStats(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+
+	c.Put("a", 1)
+	c.PutWithTTL("b", 2, ttl)
+	c.Get("a")
+	c.Get("c")
+	c.GetOrPut("a", nil)
+	time.Sleep(2 * ttl)
+
+	want := Stats{
+		Items:   1,
+		Bytes:   8, // The size of an int.
+		Hits:    2,
+		Misses:  1,
+		Expired: 1,
+	}
+	got := c.Stats()
+	// The lag of the expiry varies.
+	got.MaxExpiryLag = 0
+	if got != want {
+		t.Errorf("Stats() got=%+v, want=%+v", got, want)
+	}
+}
+
+func TestLoadStats(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	const delay = 2 * time.Millisecond
+	slow := GetterFunc[int, int](func(k int) (int, bool) {
+		time.Sleep(delay)
+		return k, k > 0
+	})
+
+	c.GetOrPut(1, slow)
+	c.GetOrPut(1, slow) // A hit does not load.
+	c.GetOrPut(0, slow) // A failed load still counts.
+
+	s := c.Stats()
+	if s.Loads != 2 {
+		t.Errorf("Loads: got=%d, want=2", s.Loads)
+	}
+	if s.LoadTime < 2*delay {
+		t.Errorf("LoadTime: got=%v, want at least %v", s.LoadTime, 2*delay)
+	}
+	if n := s.LoadTimes.Count(); n != 2 {
+		t.Errorf("LoadTimes.Count(): got=%d, want=2", n)
+	}
+	if p := s.LoadTimes.Quantile(0.5); p < delay {
+		t.Errorf("LoadTimes.Quantile(0.5): got=%v, want at least %v", p, delay)
+	}
+}
+
+func TestCoalescedLoads(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	const waiters = 4
+	var calls int32
+	release := make(chan struct{})
+	slow := GetterFunc[int, int](func(k int) (int, bool) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return k, true
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i <= waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := c.GetOrPut(1, slow); !ok || v != 1 {
+				t.Errorf("GetOrPut(1) got=%d, %t, want=1, true", v, ok)
+			}
+		}()
+	}
+	for c.Stats().Coalesced < waiters {
+		time.Sleep(time.Millisecond)
+	}
+	// The cache is not locked while loading.
+	c.Put(2, 2)
+	close(release)
+	wg.Wait()
+
+	s := c.Stats()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("provider calls: got=%d, want=1", n)
+	}
+	if s.Loads != 1 {
+		t.Errorf("Loads: got=%d, want=1", s.Loads)
+	}
+	if s.Suppressed != 1 {
+		t.Errorf("Suppressed: got=%d, want=1", s.Suppressed)
+	}
+}
+
+func TestLoadPanics(t *testing.T) {
+	c := New[int, int](time.Minute)
+	defer c.Shutdown()
+	func() {
+		defer func() { recover() }()
+		c.GetOrPut(1, GetterFunc[int, int](func(int) (int, bool) {
+			panic("boom")
+		}))
+	}()
+	v := newAssert(t, c, true).GetOrPut(1, SimpleGetterFunc[int, int](func() int {
+		return 1
+	}))
+	if v != 1 {
+		t.Errorf("GetOrPut(1) after a panicking load got=%d, want=1", v)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	if q := h.Quantile(0.5); q != 0 {
+		t.Errorf("empty Quantile(0.5): got=%v, want=0", q)
+	}
+	last := len(h) - 1
+	h[1], h[11], h[last] = 90, 9, 1
+	for _, tt := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, 2 * time.Microsecond},
+		{0.9, 2 * time.Microsecond},
+		{0.99, 2048 * time.Microsecond},
+		{1, HistogramBound(last)},
+	} {
+		if got := h.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v): got=%v, want=%v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.Put("b", 2)
+	c.Put("a", 1)
+
+	got := c.Keys()
+	sort.Strings(got)
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Keys() got=%v, want=[a b]", got)
+	}
+}
+
+func TestExpiryHistogram(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.PutWithTTL("a", 1, time.Second)
+	c.PutWithTTL("b", 1, 2*time.Second)
+	c.PutWithTTL("c", 1, time.Hour)
+	c.Put("d", 1, Pin())
+
+	got := c.ExpiryHistogram([]time.Duration{time.Second, time.Minute})
+	want := []int{1, 1, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpiryHistogram() got=%v, want=%v", got, want)
+	}
+}
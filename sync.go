@@ -0,0 +1,189 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"time"
+	"unsafe"
+)
+
+// Diff compares the cache, as a follower, to other, as its leader, and
+// returns the keys of the items that are missing from the cache, stale
+// in it, i.e., put before the ones in other, and extra in it, i.e.,
+// not in other.
+//
+// The caches are compared as of slightly different times, as they are
+// not locked simultaneously.
+func (c *Cache[K, V]) Diff(other *Cache[K, V]) (missing, stale, extra []K) {
+	ours, theirs := c.Versions(), other.Versions()
+	for k, put := range theirs {
+		if our, found := ours[k]; !found {
+			missing = append(missing, k)
+		} else if our.Before(put) {
+			stale = append(stale, k)
+		}
+	}
+	for k := range ours {
+		if _, found := theirs[k]; !found {
+			extra = append(extra, k)
+		}
+	}
+	return
+}
+
+// SyncFrom reconciles the cache with other, as its leader, e.g.,
+// periodically, for a warm standby: the items that are missing or
+// stale in the cache are copied from other, with their insertion and
+// expiry times, and the extra ones are dropped, see Diff.
+//
+// The values are copied shallowly. Returns ErrQuiesced, if the cache is
+// quiesced.
+func (c *Cache[K, V]) SyncFrom(other *Cache[K, V]) error {
+	_, items, _ := other.copyModified(snapshotMark{})
+
+	c.lock()
+	defer c.unlock()
+	if c.quiet {
+		return ErrQuiesced
+	}
+	keep := make(map[K]struct{}, len(items))
+	now := time.Now()
+	for _, it := range items {
+		keep[it.Key] = struct{}{}
+		if val, found := c.d[it.Key]; !found || val.put.Before(it.Inserted) {
+			c.restore(it, now)
+		}
+	}
+	for k, val := range c.d {
+		if _, found := keep[k]; !found {
+			c.drop(k, val, ReasonDropped)
+		}
+	}
+	for c.processTimers() {
+	}
+	return nil
+}
+
+// PutEntry puts an item as described by e, e.g., one from another
+// cache, with its insertion and expiry times, time-to-live, tags, and
+// origin, unless it has expired, in which case the item at its key, if any, is
+// left as is.
+//
+// Returns an error if the item has not been put, like PutE does.
+func (c *Cache[K, V]) PutEntry(e Entry[K, V]) error {
+	if c.checkKey != nil {
+		if err := c.checkKey(e.Key); err != nil {
+			return err
+		}
+	}
+	c.lock()
+	defer c.unlock()
+	if c.quiet {
+		return ErrQuiesced
+	}
+	now := time.Now()
+	if !c.allowWrite(e.Origin, now) {
+		return &QuotaError{e.Origin}
+	}
+	x := e.Expires
+	if e.Pinned {
+		x = now.Add(indefinite)
+	}
+	restored := c.restore(snapshotItem[K, V]{
+		Key:      e.Key,
+		Value:    e.Value,
+		TTL:      e.TTL,
+		Expires:  x,
+		Tags:     e.Tags,
+		Pinned:   e.Pinned,
+		Inserted: e.Inserted,
+	}, now)
+	if val, found := c.d[e.Key]; restored && found {
+		val.origin = e.Origin
+		c.d[e.Key] = val
+	}
+	return nil
+}
+
+// TransferTo moves the items that pred reports true for from the cache
+// to other, with their remaining lifetimes, insertion times and tags,
+// at once, so that no other call sees any of them in both caches, or in
+// neither, e.g., to split a cache into smaller ones without them
+// starting cold. The items leave the cache with ReasonTransferred, and
+// the ones that other does not accept the keys of stay. Returns the
+// number of the items moved, not counting the ones that have expired
+// but not been dropped yet, which are only dropped, with ReasonExpired,
+// and ErrQuiesced, if other is quiesced.
+//
+// Both caches are locked while pred is called, so pred must not use
+// either.
+func (c *Cache[K, V]) TransferTo(
+	other *Cache[K, V],
+	pred func(key K, value V) bool,
+) (n int, err error) {
+	if other == c {
+		return 0, nil
+	}
+	// Lock the caches in the same order in concurrent transfers in
+	// both directions.
+	first, second := c, other
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+	first.lock()
+	second.lock()
+	defer func() {
+		// Neither cache is to be locked while the evictions are reported.
+		evicted := first.unlockOnly()
+		second.report(second.unlockOnly())
+		first.report(evicted)
+	}()
+	if other.quiet {
+		return 0, ErrQuiesced
+	}
+	now := time.Now()
+	for k, val := range c.d {
+		v := val.Value()
+		if !pred(k, v) || !other.keyOK(k) {
+			continue
+		}
+		x := val.t.x
+		if val.pin {
+			x = now.Add(indefinite)
+		} else if !x.After(now) {
+			c.drop(k, val, ReasonExpired)
+			c.stats.Expired++
+			continue
+		}
+		c.drop(k, val, ReasonTransferred)
+		moved := other.restore(snapshotItem[K, V]{
+			Key:      k,
+			Value:    v,
+			TTL:      val.ttl,
+			Expires:  x,
+			Tags:     val.tags,
+			Pinned:   val.pin,
+			Fixed:    val.fixed,
+			Inserted: val.put,
+		}, now)
+		if moved {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Versions returns the times the items in the cache were put at, which
+// tell which of the items at a key in different caches is newer, e.g.,
+// to replicate them.
+func (c *Cache[K, V]) Versions() map[K]time.Time {
+	c.lock()
+	defer c.unlock()
+	m := make(map[K]time.Time, len(c.d))
+	for k, val := range c.d {
+		m[k] = val.put
+	}
+	return m
+}
@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestDiffSyncFrom(t *testing.T) {
+	leader := New[string, int](time.Minute)
+	defer leader.Shutdown()
+	follower := New[string, int](time.Minute)
+	defer follower.Shutdown()
+	req := newAssert(t, follower, true)
+
+	leader.Put("same", 1)
+	if err := follower.SyncFrom(leader); err != nil {
+		t.Fatalf("SyncFrom() error: %v", err)
+	}
+	follower.Put("extra", 2)
+	leader.Put("missing", 3)
+	follower.Put("stale", 4)
+	time.Sleep(time.Millisecond)
+	leader.Put("stale", 5)
+
+	missing, stale, extra := follower.Diff(leader)
+	for name, got := range map[string][]string{
+		"missing": missing,
+		"stale":   stale,
+		"extra":   extra,
+	} {
+		if want := []string{name}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Diff() %s got=%v, want=%v", name, got, want)
+		}
+	}
+
+	if err := follower.SyncFrom(leader); err != nil {
+		t.Fatalf("SyncFrom() error: %v", err)
+	}
+	req.LengthIs(3)
+	req.HasNot("extra")
+	req.Assert(req.Get("missing") == 3, "missing should be 3")
+	req.Assert(req.Get("stale") == 5, "stale should be 5")
+	if m, s, e := follower.Diff(leader); len(m)+len(s)+len(e) > 0 {
+		t.Errorf("Diff() after SyncFrom got=%v, %v, %v, want none", m, s, e)
+	}
+}
+
+func TestPutEntry(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	inserted := time.Now().Add(-time.Hour)
+
+	err := c.PutEntry(Entry[string, int]{
+		Key:      "a",
+		Value:    1,
+		TTL:      time.Hour,
+		Inserted: inserted,
+		Expires:  time.Now().Add(time.Minute),
+		Tags:     []string{"t"},
+		Origin:   "peer",
+	})
+	if err != nil {
+		t.Fatalf("PutEntry() error: %v", err)
+	}
+	e, ok := c.GetEntry("a")
+	if !ok || e.Value != 1 || e.TTL != time.Hour || e.Origin != "peer" ||
+		!e.Inserted.Equal(inserted) || len(e.Tags) != 1 {
+		t.Errorf("GetEntry(a) got=%+v,%v", e, ok)
+	}
+	if v := c.Versions()["a"]; !v.Equal(inserted) {
+		t.Errorf("Versions()[a] got=%v, want=%v", v, inserted)
+	}
+
+	c.PutEntry(Entry[string, int]{Key: "b", Expires: time.Now()})
+	req.HasNot("b")
+	c.PutEntry(Entry[string, int]{Key: "c", Pinned: true})
+	req.Has("c")
+}
+
+func TestTransferTo(t *testing.T) {
+	var reasons []EvictReason
+	src := New[string, int](time.Minute, WithOnEvict(func(_ string, _ int, r EvictReason) {
+		reasons = append(reasons, r)
+	}))
+	defer src.Shutdown()
+	dst := New[string, int](time.Minute, WithoutZeroKeys())
+	defer dst.Shutdown()
+
+	src.PutWithTTL("user:1", 1, time.Hour)
+	src.Tag("user:1", "users")
+	src.Put("user:2", 2, Pin())
+	src.Put("", 3) // Not accepted.
+	src.Put("order:1", 4)
+	dst.Put("user:2", 0)
+
+	n, err := src.TransferTo(dst, func(k string, _ int) bool {
+		return !strings.HasPrefix(k, "order:")
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("TransferTo() got=%d,%v, want=2,nil", n, err)
+	}
+	if keys := src.Keys(); len(keys) != 2 {
+		t.Errorf("src.Keys() got=%v, want [ order:1]", keys)
+	}
+	e, ok := dst.GetEntry("user:1")
+	if !ok || e.Value != 1 || e.Remaining < 59*time.Minute || len(e.Tags) != 1 {
+		t.Errorf("dst.GetEntry(user:1) got=%+v,%v", e, ok)
+	}
+	if e, ok := dst.GetEntry("user:2"); !ok || e.Value != 2 || !e.Pinned {
+		t.Errorf("dst.GetEntry(user:2) got=%+v,%v", e, ok)
+	}
+	if len(reasons) != 2 || reasons[0] != ReasonTransferred {
+		t.Errorf("src evictions got=%v, want 2 transferred", reasons)
+	}
+
+	dst.Quiesce()
+	if _, err := src.TransferTo(dst, func(string, int) bool { return true }); err != ErrQuiesced {
+		t.Errorf("TransferTo() to a quiesced cache got=%v, want=%v", err, ErrQuiesced)
+	}
+	if n, _ := src.TransferTo(src, func(string, int) bool { return true }); n != 0 {
+		t.Errorf("TransferTo() to itself got=%d, want=0", n)
+	}
+}
+
+func TestTransferToReportsUnlocked(t *testing.T) {
+	var a, b *Cache[string, int]
+	release := make(chan struct{})
+	var reasons []EvictReason
+	onEvict := WithOnEvict(func(k string, _ int, r EvictReason) {
+		if k == "blocker" {
+			// Stalls the expiry of the other items.
+			<-release
+			return
+		}
+		// Either cache is locked before the other, by their addresses.
+		a.Keys()
+		b.Keys()
+		reasons = append(reasons, r)
+	})
+	a = New[string, int](time.Minute, onEvict)
+	defer a.Shutdown()
+	b = New[string, int](time.Minute, onEvict)
+	defer b.Shutdown()
+	defer close(release)
+
+	a.Put("moved", 1)
+	a.PutWithTTL("blocker", 0, ttl/4)
+	a.PutWithTTL("expired", 2, ttl/2)
+	time.Sleep(ttl)
+	all := func(string, int) bool { return true }
+	if n, err := a.TransferTo(b, all); err != nil || n != 1 {
+		t.Fatalf("TransferTo() got=%d,%v, want=1,nil", n, err)
+	}
+	if n, err := b.TransferTo(a, all); err != nil || n != 1 {
+		t.Fatalf("TransferTo() back got=%d,%v, want=1,nil", n, err)
+	}
+	want := []EvictReason{ReasonExpired, ReasonTransferred, ReasonTransferred}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("evictions got=%v, want=%v", reasons, want)
+	}
+}
@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "strings"
+
+// Tag the cached item at key with tags, to operate on it together with
+// the other items with any of the same tags. Returns false if the key
+// has not been found in the cache.
+//
+// The tags stay with the item until it is dropped, putting a new value
+// at the key does not remove them.
+func (c *Cache[K, V]) Tag(key K, tags ...string) bool {
+	c.lock()
+	defer c.unlock()
+	return c.tag(key, tags)
+}
+
+// Tags returns the tags of the cached item at key.
+func (c *Cache[K, V]) Tags(key K) []string {
+	c.lock()
+	defer c.m.Unlock()
+	return append([]string(nil), c.d[key].tags...)
+}
+
+// TouchTag touches all cached items tagged with tag, to extend their
+// lifetimes, and returns their number.
+func (c *Cache[K, V]) TouchTag(tag string) int {
+	c.lock()
+	defer c.m.Unlock()
+	for k := range c.tags[tag] {
+		c.touch(c.d[k])
+	}
+	return len(c.tags[tag])
+}
+
+// DropTag drops all cached items tagged with tag and returns their
+// number.
+func (c *Cache[K, V]) DropTag(tag string) int {
+	c.lock()
+	defer c.unlock()
+	keys := c.tags[tag]
+	n := len(keys)
+	for k := range keys {
+		c.drop(k, c.d[k], ReasonDropped)
+	}
+	return n
+}
+
+// TouchFunc touches all cached items with keys that match reports true
+// for, to extend their lifetimes, and returns their number.
+func (c *Cache[K, V]) TouchFunc(match func(key K) bool) (n int) {
+	c.lock()
+	defer c.m.Unlock()
+	for k, val := range c.d {
+		if match(k) {
+			c.touch(val)
+			n++
+		}
+	}
+	return
+}
+
+// TouchPrefix touches all items cached in c at keys that start with
+// prefix, to extend their lifetimes, and returns their number.
+func TouchPrefix[V any](c *Cache[string, V], prefix string) int {
+	return c.TouchFunc(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// Internals.
+
+func (c *Cache[K, V]) tagged(tag string, key K) bool {
+	_, ok := c.tags[tag][key]
+	return ok
+}
+
+func (c *Cache[K, V]) tag(key K, tags []string) bool {
+	val, found := c.d[key]
+	if !found {
+		return false
+	}
+	for _, tag := range tags {
+		if c.tagged(tag, key) {
+			continue
+		}
+		if c.tags == nil {
+			c.tags = make(map[string]map[K]struct{})
+		}
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[K]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+		val.tags = append(val.tags, tag)
+	}
+	c.store(key, val)
+	return true
+}
+
+// untag key of tags.
+func (c *Cache[K, V]) untag(key K, tags []string) {
+	for _, tag := range tags {
+		delete(c.tags[tag], key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
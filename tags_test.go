@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestTouchTag(t *testing.T) {
+	c := New[string, int](2 * ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	req.AssertNot(c.Tag("a", "t"), "should not tag an absent item")
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	req.Assert(c.Tag("a", "t", "u"), "should tag 'a'")
+	req.Assert(c.Tag("b", "t", "t"), "should tag 'b'")
+	tags := c.Tags("b")
+	req.Assert(len(tags) == 1 && tags[0] == "t", "Tags(b) got=%v, want=[t]", tags)
+
+	time.Sleep(3 * ttl / 2)
+	n := c.TouchTag("t")
+	req.Assert(n == 2, "TouchTag(t) got=%d, want=2", n)
+	time.Sleep(3 * ttl / 2)
+	req.Has("a")
+	req.Has("b")
+	req.HasNot("c")
+
+	c.Drop("a")
+	n = c.TouchTag("u")
+	req.Assert(n == 0, "TouchTag(u) got=%d after drop, want=0", n)
+}
+
+func TestTouchPrefix(t *testing.T) {
+	c := New[string, int](2 * ttl)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("tenant1/a", 1)
+	c.Put("tenant1/b", 2)
+	c.Put("tenant2/a", 3)
+
+	time.Sleep(3 * ttl / 2)
+	n := TouchPrefix(c, "tenant1/")
+	req.Assert(n == 2, "TouchPrefix() got=%d, want=2", n)
+	time.Sleep(3 * ttl / 2)
+	req.Has("tenant1/a")
+	req.Has("tenant1/b")
+	req.HasNot("tenant2/a")
+}
+
+func TestTagsSnapshot(t *testing.T) {
+	src := New[string, int](time.Minute)
+	defer src.Shutdown()
+	src.Put("a", 1)
+	src.Tag("a", "t")
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	dst := New[string, int](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if n := dst.TouchTag("t"); n != 1 {
+		t.Errorf("TouchTag(t) got=%d after restore, want=1", n)
+	}
+}
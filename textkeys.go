@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"encoding"
+	"encoding/gob"
+	"errors"
+)
+
+// ErrSnapshotKeys is returned when restoring a snapshot with keys in
+// their textual forms to a cache of keys that cannot be decoded from
+// text.
+var ErrSnapshotKeys = errors.New("cache: snapshot keys cannot be decoded from text")
+
+// Internals.
+
+// textKeyed returns whether keys of type K are written to snapshots in
+// their textual forms, as encoding.TextMarshalers, which gob does not
+// encode otherwise, unless they are gob.GobEncoders or
+// encoding.BinaryMarshalers.
+func textKeyed[K comparable]() bool {
+	var k K
+	_, text := any(k).(encoding.TextMarshaler)
+	_, unText := any(&k).(encoding.TextUnmarshaler)
+	_, gobbed := any(k).(gob.GobEncoder)
+	_, binary := any(k).(encoding.BinaryMarshaler)
+	return text && unText && !gobbed && !binary
+}
+
+// encodeKey k with e, in its textual form, if text.
+func encodeKey[K comparable](e *gob.Encoder, k K, text bool) error {
+	if !text {
+		return e.Encode(&k)
+	}
+	s, err := any(k).(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return err
+	}
+	return e.Encode(string(s))
+}
+
+// decodeKey into k with d, from its textual form, if text.
+func decodeKey[K comparable](d *gob.Decoder, k *K, text bool) error {
+	if !text {
+		return d.Decode(k)
+	}
+	var s string
+	if err := d.Decode(&s); err != nil {
+		return err
+	}
+	return unmarshalKey(k, s)
+}
+
+// encodeItem it with e, with its key in its textual form, if text.
+func encodeItem[K comparable, V any](
+	e *gob.Encoder,
+	it snapshotItem[K, V],
+	text bool,
+) error {
+	if !text {
+		return e.Encode(&it)
+	}
+	s, err := any(it.Key).(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return err
+	}
+	t := rekey(it, string(s))
+	return e.Encode(&t)
+}
+
+// decodeItem into it with d, with its key in its textual form, if text.
+func decodeItem[K comparable, V any](
+	d *gob.Decoder,
+	it *snapshotItem[K, V],
+	text bool,
+) error {
+	if !text {
+		return d.Decode(it)
+	}
+	var t snapshotItem[string, V]
+	if err := d.Decode(&t); err != nil {
+		return err
+	}
+	var k K
+	if err := unmarshalKey(&k, t.Key); err != nil {
+		return err
+	}
+	*it = rekey(t, k)
+	return nil
+}
+
+// unmarshalKey from its textual form s into k.
+func unmarshalKey[K comparable](k *K, s string) error {
+	u, ok := any(k).(encoding.TextUnmarshaler)
+	if !ok {
+		return ErrSnapshotKeys
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// rekey returns a copy of it with key k.
+func rekey[K1, K2 comparable, V any](
+	it snapshotItem[K1, V],
+	k K2,
+) snapshotItem[K2, V] {
+	return snapshotItem[K2, V]{
+		Key:      k,
+		Value:    it.Value,
+		TTL:      it.TTL,
+		Expires:  it.Expires,
+		Tags:     it.Tags,
+		Pinned:   it.Pinned,
+		Fixed:    it.Fixed,
+		Inserted: it.Inserted,
+	}
+}
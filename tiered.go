@@ -0,0 +1,225 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"math/rand"
+	"time"
+)
+
+// A Store is a more permanent tier of storage for cached values.
+type Store[K comparable, V any] interface {
+	Getter[K, V]
+	// Put value at key in store, to be kept for at least ttl.
+	Put(key K, value V, ttl time.Duration) error
+	// Drop the value at key from store.
+	Drop(key K) error
+}
+
+// A VersionedStore is a Store that keeps the versions of its values,
+// i.e., the times they were put at in the first tier, which read repair
+// compares, see WithReadRepair.
+type VersionedStore[K comparable, V any] interface {
+	Store[K, V]
+	// GetVersion returns the value at key in store with its version.
+	GetVersion(key K) (value V, version time.Time, ok bool)
+	// PutVersion puts value at key in store with version, to be kept
+	// for at least ttl.
+	PutVersion(key K, value V, version time.Time, ttl time.Duration) error
+}
+
+// A TieredOption configures a TieredCache.
+type TieredOption func(*tieredOptions)
+
+// WithAsyncFill makes a TieredCache put the values read from its second
+// tier in the first one asynchronously, without making the reads wait
+// for that. Concurrent reads of a value absent from the first tier then
+// all read it from the second one.
+func WithAsyncFill() TieredOption {
+	return func(o *tieredOptions) {
+		o.asyncFill = true
+	}
+}
+
+// WithFillTTL makes a TieredCache put the values read from its second
+// tier in the first one with fraction of the time-to-live the first
+// tier would put them with, e.g., to bound for how long it serves the
+// values that other processes change in the second tier.
+func WithFillTTL(fraction float64) TieredOption {
+	return func(o *tieredOptions) {
+		o.fillTTL = fraction
+	}
+}
+
+// WithReadRepair makes a TieredCache with a VersionedStore as its
+// second tier check the versions of rate (0 to 1) of the values read
+// from the first tier against the second one, asynchronously, and
+// repair the tier with the older version: values newer in the second
+// tier are put in the first one, and those newer in the first one are
+// written to the second one.
+//
+// The values are put in both tiers with the same versions, also when
+// read from the second tier into the first one.
+func WithReadRepair(rate float64) TieredOption {
+	return func(o *tieredOptions) {
+		o.repairRate = rate
+	}
+}
+
+// TieredCache is a Cache backed by a Store, which values are read
+// through from and written through to.
+type TieredCache[K comparable, V any] struct {
+	l1 *Cache[K, V]
+	l2 Store[K, V]
+	vs VersionedStore[K, V] // The second tier, if versions are kept.
+	o  tieredOptions
+}
+
+// NewTiered returns a new TieredCache that uses l1 as the first tier,
+// and l2 as the second one.
+func NewTiered[K comparable, V any](
+	l1 *Cache[K, V],
+	l2 Store[K, V],
+	opts ...TieredOption,
+) *TieredCache[K, V] {
+	c := &TieredCache[K, V]{
+		l1: l1,
+		l2: l2,
+	}
+	for _, opt := range opts {
+		opt(&c.o)
+	}
+	if vs, ok := l2.(VersionedStore[K, V]); ok && c.o.repairRate > 0 {
+		c.vs = vs
+	}
+	return c
+}
+
+// Get the value at key from the first tier or, if absent, from the
+// second one, putting it in the first tier with its default TTL, unless
+// configured otherwise, see WithAsyncFill, WithFillTTL, and
+// WithReadRepair.
+func (c *TieredCache[K, V]) Get(key K) (value V, ok bool) {
+	if c.o == (tieredOptions{}) {
+		return c.l1.GetOrPut(key, c.l2)
+	}
+	if value, ok = c.l1.Get(key); ok {
+		if c.vs != nil && rand.Float64() < c.o.repairRate {
+			go c.repair(key)
+		}
+		return
+	}
+	var version time.Time
+	if c.vs != nil {
+		value, version, ok = c.vs.GetVersion(key)
+	} else {
+		value, ok = c.l2.Get(key)
+	}
+	if !ok {
+		return
+	}
+	if c.o.asyncFill {
+		go c.fill(key, value, version)
+	} else {
+		c.fill(key, value, version)
+	}
+	return
+}
+
+// Put a value at the given key in both tiers, with the TTL the first
+// tier would put it with.
+func (c *TieredCache[K, V]) Put(key K, value V) error {
+	return c.PutWithTTL(key, value, c.l1.ttlFor(key))
+}
+
+// PutWithTTL puts a value at the given key in both tiers, with the
+// given time-to-live. The first tier is only written to after the
+// second one succeeds.
+func (c *TieredCache[K, V]) PutWithTTL(
+	key K,
+	value V,
+	ttl time.Duration,
+) error {
+	if c.vs == nil {
+		if err := c.l2.Put(key, value, ttl); err != nil {
+			return err
+		}
+		c.l1.PutWithTTL(key, value, ttl)
+		return nil
+	}
+	now := time.Now()
+	if err := c.vs.PutVersion(key, value, now, ttl); err != nil {
+		return err
+	}
+	return c.l1.PutEntry(Entry[K, V]{
+		Key:      key,
+		Value:    value,
+		TTL:      ttl,
+		Inserted: now,
+		Expires:  now.Add(ttl),
+	})
+}
+
+// Drop the value at key from both tiers.
+func (c *TieredCache[K, V]) Drop(key K) error {
+	c.l1.Drop(key)
+	return c.l2.Drop(key)
+}
+
+// Internals.
+
+type tieredOptions struct {
+	asyncFill  bool
+	fillTTL    float64 // Fraction of the TTL to fill the first tier with.
+	repairRate float64 // Of the reads from the first tier to repair.
+}
+
+// fill the first tier with value read from the second one at key, with
+// version, if known, unless it has a value at key already.
+func (c *TieredCache[K, V]) fill(key K, value V, version time.Time) {
+	ttl := c.l1.valueTTL(key, value, c.l1.callOptions(key, nil)).ttl
+	if c.o.fillTTL > 0 {
+		ttl = time.Duration(float64(ttl) * c.o.fillTTL)
+	}
+	if version.IsZero() {
+		c.l1.GetOrPutWithTTL(key, SimpleGetterFunc[K, V](func() V {
+			return value
+		}), ttl)
+		return
+	}
+	if c.l1.Has(key) {
+		return
+	}
+	c.l1.PutEntry(Entry[K, V]{
+		Key:      key,
+		Value:    value,
+		TTL:      ttl,
+		Inserted: version,
+		Expires:  time.Now().Add(ttl),
+	})
+}
+
+// repair the tier with the older version of the value at key.
+func (c *TieredCache[K, V]) repair(key K) {
+	e, ok := c.l1.GetEntry(key)
+	if !ok {
+		return
+	}
+	value, version, ok := c.vs.GetVersion(key)
+	if !ok {
+		return
+	}
+	switch {
+	case version.After(e.Inserted):
+		e.Value, e.Inserted = value, version
+		c.l1.PutEntry(e)
+	case e.Inserted.After(version):
+		ttl := e.Remaining
+		if e.Pinned {
+			ttl = e.TTL
+		}
+		c.vs.PutVersion(key, e.Value, e.Inserted, ttl)
+	}
+}
@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestTieredCache(t *testing.T) {
+	l1 := New[string, int](time.Minute)
+	defer l1.Shutdown()
+	l2 := mapStore[string, int]{}
+	c := NewTiered[string, int](l1, l2)
+	req := newAssert(t, l1, false)
+
+	if err := c.Put("a", 1); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	req.Has("a")
+	if _, ok := l2["a"]; !ok {
+		t.Error("should write through to the second tier")
+	}
+
+	l2["b"] = 2
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Errorf("Get(b) got=%v,%v, want=2,true", v, ok)
+	}
+	req.Has("b")
+
+	if err := c.Drop("a"); err != nil {
+		t.Fatalf("Drop() error: %v", err)
+	}
+	req.HasNot("a")
+	if _, ok := l2["a"]; ok {
+		t.Error("should drop from the second tier")
+	}
+
+	errStore := failingStore[string, int]{mapStore[string, int]{}}
+	c = NewTiered[string, int](l1, errStore)
+	if err := c.Put("c", 3); err == nil {
+		t.Error("Put() should fail when the second tier does")
+	}
+	req.HasNot("c")
+}
+
+// mapStore is a Store that keeps values indefinitely.
+type mapStore[K comparable, V any] map[K]V
+
+func (s mapStore[K, V]) Get(key K) (value V, ok bool) {
+	value, ok = s[key]
+	return
+}
+
+func (s mapStore[K, V]) Put(key K, value V, _ time.Duration) error {
+	s[key] = value
+	return nil
+}
+
+func (s mapStore[K, V]) Drop(key K) error {
+	delete(s, key)
+	return nil
+}
+
+// failingStore is a Store that fails to Put.
+type failingStore[K comparable, V any] struct {
+	mapStore[K, V]
+}
+
+func (failingStore[K, V]) Put(K, V, time.Duration) error {
+	return errors.New("failing store")
+}
+
+func TestTieredFill(t *testing.T) {
+	l1 := New[string, int](time.Minute)
+	defer l1.Shutdown()
+	l2 := mapStore[string, int]{"a": 1}
+	c := NewTiered[string, int](l1, l2, WithAsyncFill(), WithFillTTL(0.5))
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) got=%v,%v, want=1,true", v, ok)
+	}
+	waitFor(t, func() bool { return l1.Has("a") })
+	if e, _ := l1.GetEntry("a"); e.TTL != 30*time.Second {
+		t.Errorf("filled TTL got=%v, want=%v", e.TTL, 30*time.Second)
+	}
+}
+
+func TestTieredReadRepair(t *testing.T) {
+	l1 := New[string, int](time.Minute)
+	defer l1.Shutdown()
+	l2 := &versionedStore[string, int]{m: map[string]versioned[int]{}}
+	c := NewTiered[string, int](l1, l2, WithReadRepair(1))
+
+	c.Put("a", 1)
+	e, _ := l1.GetEntry("a")
+	if _, got, _ := l2.GetVersion("a"); !got.Equal(e.Inserted) {
+		t.Errorf("versions got=%v, %v, want them equal", got, e.Inserted)
+	}
+
+	// Newer in the second tier.
+	l2.PutVersion("a", 2, time.Now(), time.Minute)
+	c.Get("a")
+	waitFor(t, func() bool {
+		v, _ := l1.Get("a")
+		return v == 2
+	})
+
+	// Newer in the first tier.
+	l1.Put("a", 3)
+	c.Get("a")
+	waitFor(t, func() bool {
+		l2.mu.Lock()
+		defer l2.mu.Unlock()
+		return l2.m["a"].v == 3
+	})
+
+	// Read from the second tier with its version.
+	l2.PutVersion("b", 4, time.Now().Add(-time.Hour), time.Minute)
+	c.Get("b")
+	_, version, _ := l2.GetVersion("b")
+	if e, _ := l1.GetEntry("b"); !e.Inserted.Equal(version) {
+		t.Errorf("filled version got=%v, want=%v", e.Inserted, version)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type versioned[V any] struct {
+	v       V
+	version time.Time
+}
+
+// versionedStore is a VersionedStore that keeps values indefinitely.
+type versionedStore[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]versioned[V]
+}
+
+func (s *versionedStore[K, V]) Get(key K) (value V, ok bool) {
+	value, _, ok = s.GetVersion(key)
+	return
+}
+
+func (s *versionedStore[K, V]) GetVersion(key K) (V, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.m[key]
+	return e.v, e.version, ok
+}
+
+func (s *versionedStore[K, V]) Put(key K, value V, ttl time.Duration) error {
+	return s.PutVersion(key, value, time.Now(), ttl)
+}
+
+func (s *versionedStore[K, V]) PutVersion(
+	key K,
+	value V,
+	version time.Time,
+	_ time.Duration,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = versioned[V]{value, version}
+	return nil
+}
+
+func (s *versionedStore[K, V]) Drop(key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+	return nil
+}
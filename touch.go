@@ -0,0 +1,59 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "time"
+
+// A TouchPolicy determines how touching items extends their lifetimes.
+//
+// The zero value resets the remaining lifetimes of the items to their
+// time-to-live, like lookups do.
+type TouchPolicy struct {
+	// Extend the remaining lifetimes by this much instead, if positive.
+	Extend time.Duration
+	// MaxLifetime caps the lifetimes of the items since they were put,
+	// if positive.
+	MaxLifetime time.Duration
+}
+
+// WithTouchPolicy sets how Touch, TouchTag and TouchFunc extend the
+// lifetimes of the items in a Cache.
+func WithTouchPolicy(p TouchPolicy) Option {
+	return func(o *options) {
+		o.touch = p
+	}
+}
+
+// WithoutSliding makes lookups in a Cache not extend the lifetimes of
+// the items, which only touching them does, as if NoSlide was given to
+// every call. Items that are never touched then expire a fixed duration
+// after they were put, regardless of reads, e.g., for tokens that must
+// not outlive their grants.
+func WithoutSliding() Option {
+	return func(o *options) {
+		o.noSlide = true
+	}
+}
+
+// Internals.
+
+// touch an item to extend its lifetime according to the touch policy,
+// unless it is pinned or fixed.
+func (c *Cache[K, V]) touch(val entry[K, V]) {
+	if val.pin || val.fixed {
+		return
+	}
+	p := c.o.touch
+	now := time.Now()
+	x := now.Add(val.ttl)
+	if p.Extend > 0 {
+		x = val.t.x.Add(p.Extend)
+	}
+	if max := val.put.Add(p.MaxLifetime); p.MaxLifetime > 0 &&
+		!val.put.IsZero() && x.After(max) {
+		x = max
+	}
+	c.resetTimerAt(val.t, capped(x, val.limit))
+}
@@ -0,0 +1,57 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestTouchPolicyExtend(t *testing.T) {
+	c := New[string, int](2*ttl, WithTouchPolicy(TouchPolicy{Extend: 2 * ttl}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Touch("a")
+	c.Touch("a")
+	// Extended by 4 ttl, rather than reset to 2.
+	time.Sleep(3 * ttl)
+	req.Has("a")
+	time.Sleep(4 * ttl)
+	req.HasNot("a")
+}
+
+func TestTouchPolicyMaxLifetime(t *testing.T) {
+	c := New[string, int](10*ttl, WithTouchPolicy(TouchPolicy{MaxLifetime: 6 * ttl}))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	time.Sleep(2 * ttl)
+	c.Touch("a")
+	c.Get("b") // Lookups slide regardless of the policy.
+	time.Sleep(7 * ttl)
+	req.HasNot("a")
+	req.Has("b")
+}
+
+func TestWithoutSliding(t *testing.T) {
+	c := New[string, int](2*ttl, WithoutSliding())
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	time.Sleep(3 * ttl / 2)
+	c.Get("a")
+	c.Touch("b")
+	time.Sleep(ttl)
+	req.HasNot("a")
+	req.Has("b")
+}
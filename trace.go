@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import "context"
+
+// A Tracer starts spans for cache operations. It can be implemented as
+// an adapter for a tracing library, e.g., OpenTelemetry.
+type Tracer interface {
+	// Start a span for the named operation, as a child of the span in
+	// ctx, if any, returning a context that holds the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// A Span of a cache operation.
+type Span interface {
+	// SetAttribute of the span.
+	SetAttribute(key string, value any)
+	// End the span.
+	End()
+}
+
+// Span attribute keys.
+const (
+	// AttrHit is a bool of whether an operation found the item in
+	// cache.
+	AttrHit = "cache.hit"
+	// AttrLoadDuration is the time.Duration of a provider call.
+	AttrLoadDuration = "cache.load_duration"
+)
+
+// WithTracer makes the context-aware methods of a Cache start spans
+// with t, identifying the cache by the attributes of its name and
+// labels, if any, see WithName and WithLabels.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// GetContext gets a cached item, like Get does, with ctx, see Context,
+// in a span, if the cache has a Tracer.
+func (c *Cache[K, V]) GetContext(ctx context.Context, key K) (value V, ok bool) {
+	ctx, span := c.startSpan(ctx, "cache.Get")
+	defer span.End()
+	value, ok = c.get(ctx, key)
+	span.SetAttribute(AttrHit, ok)
+	return
+}
+
+// PutContext puts a value in cache, like Put does, with ctx, see
+// Context, in a span, if the cache has a Tracer, recording the origin
+// in ctx, if any, see ContextWithOrigin.
+func (c *Cache[K, V]) PutContext(ctx context.Context, key K, value V) {
+	ctx, span := c.startSpan(ctx, "cache.Put")
+	defer span.End()
+	c.Put(key, value, Origin(OriginFromContext(ctx)), Context(ctx))
+}
+
+// GetOrPutContext returns the value in cache at the given key or the
+// one returned by provider, like GetOrPut does, with ctx, see Context,
+// in a span, if the cache has a Tracer, recording the origin in ctx of
+// the value put, if any.
+func (c *Cache[K, V]) GetOrPutContext(
+	ctx context.Context,
+	key K,
+	provider Getter[K, V],
+) (value V, ok bool) {
+	ctx, span := c.startSpan(ctx, "cache.GetOrPut")
+	defer span.End()
+	o := c.callOptions(key, []CallOption{
+		Origin(OriginFromContext(ctx)),
+		Context(ctx),
+	})
+	value, ok, hit, load := c.getOrPut(key, provider, o)
+	span.SetAttribute(AttrHit, hit)
+	if !hit {
+		span.SetAttribute(AttrLoadDuration, load)
+	}
+	return
+}
+
+// Internals.
+
+func (c *Cache[K, V]) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.o.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := c.o.tracer.Start(ctx, name)
+	c.identify(span)
+	return ctx, span
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+
+func (noopSpan) End() {}
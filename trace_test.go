@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestTracer(t *testing.T) {
+	tr := &recordingTracer{}
+	c := New[string, int](time.Minute, WithTracer(tr))
+	defer c.Shutdown()
+	ctx := context.Background()
+	provider := SimpleGetterFunc[string, int](func() int { return 1 })
+
+	c.GetContext(ctx, "a")
+	c.PutContext(ctx, "a", 1)
+	c.GetOrPutContext(ctx, "a", provider)
+	c.GetOrPutContext(ctx, "b", provider)
+
+	want := []recordedSpan{
+		{"cache.Get", map[string]any{AttrHit: false}},
+		{"cache.Put", map[string]any{}},
+		{"cache.GetOrPut", map[string]any{AttrHit: true}},
+		{"cache.GetOrPut", map[string]any{AttrHit: false, AttrLoadDuration: nil}},
+	}
+	if len(tr.spans) != len(want) {
+		t.Fatalf("got %d spans, want %d", len(tr.spans), len(want))
+	}
+	for i, w := range want {
+		got := tr.spans[i]
+		if got.name != w.name || !got.ended {
+			t.Errorf("span %d: got=%s (ended: %v), want=%s ended",
+				i, got.name, got.ended, w.name)
+		}
+		if len(got.attrs) != len(w.attrs) {
+			t.Errorf("span %d: got attributes %v, want %v", i, got.attrs, w.attrs)
+		}
+		for k, v := range w.attrs {
+			if k == AttrLoadDuration {
+				if _, ok := got.attrs[k].(time.Duration); !ok {
+					t.Errorf("span %d: %s should be a time.Duration", i, k)
+				}
+				continue
+			}
+			if got.attrs[k] != v {
+				t.Errorf("span %d: %s got=%v, want=%v", i, k, got.attrs[k], v)
+			}
+		}
+	}
+}
+
+func TestNoTracer(t *testing.T) {
+	c := New[string, int](time.Minute)
+	defer c.Shutdown()
+	c.PutContext(context.Background(), "a", 1)
+	if v, ok := c.GetContext(context.Background(), "a"); !ok || v != 1 {
+		t.Errorf("GetContext(a) got=%v,%v, want=1,true", v, ok)
+	}
+}
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &recordingSpan{
+		recordedSpan: recordedSpan{
+			name:  name,
+			attrs: map[string]any{},
+		},
+	}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+}
+
+type recordingSpan struct {
+	recordedSpan
+	ended bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) {
+	s.attrs[key] = value
+}
+
+func (s *recordingSpan) End() {
+	s.ended = true
+}
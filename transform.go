@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+// WithReadTransform sets a function that transforms the values read
+// from a Cache of the same key and value types, e.g., to mask secrets,
+// or to decompress them, before they are returned by Get, GetWith,
+// GetAndTouch, the GetOrPut methods and passed to the Range callback.
+// Snapshots and dropped items are not transformed.
+//
+// The cache is not locked while the function is called.
+//
+// New panics if the types do not match those of the cache.
+func WithReadTransform[K comparable, V any](f func(key K, value V) V) Option {
+	return func(o *options) {
+		o.transform = f
+	}
+}
+
+// Internals.
+
+// transformOf returns the function set by WithReadTransform.
+func transformOf[K comparable, V any](o options) func(K, V) V {
+	if o.transform == nil {
+		return nil
+	}
+	f, ok := o.transform.(func(K, V) V)
+	if !ok {
+		panic("cache: WithReadTransform types do not match those of the cache")
+	}
+	return f
+}
+
+// read returns value, transformed, if it was found (ok).
+func (c *Cache[K, V]) read(key K, value V, ok bool) (V, bool) {
+	if ok && c.xform != nil {
+		value = c.xform(key, value)
+	}
+	return value, ok
+}
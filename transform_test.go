@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestReadTransform(t *testing.T) {
+	mask := func(key, value string) string {
+		if strings.HasPrefix(key, "secret/") {
+			return "***"
+		}
+		return value
+	}
+	c := New[string, string](time.Minute, WithReadTransform(mask))
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.Put("secret/a", "hunter2")
+	c.Put("b", "plain")
+	want := func(what, got, want string) {
+		t.Helper()
+		req.Assert(got == want, "%s got=%q, want=%q", what, got, want)
+	}
+	want("Get(secret/a)", req.Get("secret/a"), "***")
+	want("Get(b)", req.Get("b"), "plain")
+	v, _ := c.GetWith("secret/a", NoSlide())
+	want("GetWith(secret/a)", v, "***")
+	v, _ = c.GetAndTouch("secret/a", time.Minute)
+	want("GetAndTouch(secret/a)", v, "***")
+	want("GetOrPut(secret/a)", req.GetOrPut("secret/a", nil), "***")
+	v = req.GetOrPut("secret/c", SimpleGetterFunc[string, string](func() string {
+		return "loaded"
+	}))
+	want("GetOrPut(secret/c)", v, "***")
+	c.Range(func(k, v string) bool {
+		want("Range value of "+k, v, mask(k, "plain"))
+		return true
+	})
+
+	// Snapshots are not transformed.
+	var buf bytes.Buffer
+	if err := c.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+	dst := New[string, string](time.Minute)
+	defer dst.Shutdown()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	v, _ = dst.Get("secret/a")
+	want("restored Get(secret/a)", v, "hunter2")
+}
@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NewStringBytes returns a new cache of byte slices indexed by strings.
+func NewStringBytes(defaultTTL time.Duration, opts ...Option) *Cache[string, []byte] {
+	return New[string, []byte](defaultTTL, opts...)
+}
+
+// NewStringJSON returns a new cache of values indexed by strings that
+// are stored encoded as JSON.
+func NewStringJSON[T any](defaultTTL time.Duration, opts ...Option) *JSONCache[T] {
+	return &JSONCache[T]{c: NewStringBytes(defaultTTL, opts...)}
+}
+
+// A JSONCache stores values encoded as JSON in a string keyed cache.
+//
+// Values are decoded on every Get, so the ones returned never share
+// memory with the cache, or with each other.
+type JSONCache[T any] struct {
+	c *Cache[string, []byte]
+}
+
+// Get the value of a cached item. Returns an error if the item cannot
+// be decoded.
+func (j *JSONCache[T]) Get(key string) (value T, ok bool, err error) {
+	b, ok := j.c.Get(key)
+	if !ok {
+		return
+	}
+	if err = json.Unmarshal(b, &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Put a value in the cache with the time-to-live set by the rules, or
+// the cache-default one.
+func (j *JSONCache[T]) Put(key string, value T) error {
+	return j.PutWithTTL(key, value, j.c.ttlFor(key))
+}
+
+// PutWithTTL puts a value in the cache with the given time-to-live.
+func (j *JSONCache[T]) PutWithTTL(key string, value T, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	j.c.PutWithTTL(key, b, ttl)
+	return nil
+}
+
+// Drop a cached item and return whether it was present.
+func (j *JSONCache[T]) Drop(key string) bool {
+	_, ok := j.c.Drop(key)
+	return ok
+}
+
+// Cache returns the underlying cache of encoded values.
+func (j *JSONCache[T]) Cache() *Cache[string, []byte] {
+	return j.c
+}
+
+// Shutdown the underlying cache.
+func (j *JSONCache[T]) Shutdown() {
+	j.c.Shutdown()
+}
@@ -0,0 +1,44 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestJSONCache(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+	c := NewStringJSON[user](time.Minute)
+	defer c.Shutdown()
+
+	if err := c.Put("bob", user{"Bob", 42}); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	got, ok, err := c.Get("bob")
+	if err != nil || !ok || got != (user{"Bob", 42}) {
+		t.Errorf("Get(bob) got=%v, %t, %v", got, ok, err)
+	}
+	raw, _ := c.Cache().Get("bob")
+	if want := `{"Name":"Bob","Age":42}`; string(raw) != want {
+		t.Errorf("stored got=%s, want=%s", raw, want)
+	}
+
+	c.Cache().Put("bad", []byte("{"))
+	if _, ok, err := c.Get("bad"); ok || err == nil {
+		t.Errorf("Get(bad) got=%t, %v, want an error", ok, err)
+	}
+	if !c.Drop("bob") {
+		t.Error("Drop(bob) should report the item present")
+	}
+	if _, ok, _ := c.Get("bob"); ok {
+		t.Error("Get(bob) after Drop should not find it")
+	}
+}
@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// A ZeroPolicy determines what GetOrPut does with the zero values that
+// providers return as found.
+type ZeroPolicy int
+
+const (
+	// ZeroCached values are put in the cache like any other.
+	ZeroCached ZeroPolicy = iota
+	// ZeroRejected values are reported as not found and not cached.
+	ZeroRejected
+	// ZeroNegative values are cached with the negative time-to-live set
+	// by WithNegativeTTL, so that a missing item is not looked up again
+	// for a while, but not as long as a present one.
+	ZeroNegative
+)
+
+// WithZeroValues sets the policy for the zero values loaded by
+// GetOrPut, which caches them like any other by default.
+func WithZeroValues(p ZeroPolicy) Option {
+	return func(o *options) {
+		o.zero = p
+	}
+}
+
+// WithNegativeTTL sets the time-to-live of the zero values cached with
+// the ZeroNegative policy.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// Internals.
+
+// loaded applies the zero value policy to a value loaded by a provider
+// to be put with o, returning whether it should be kept and how.
+func (c *Cache[K, V]) loaded(v V, o callOptions) (bool, callOptions) {
+	if c.o.zero == ZeroCached || !isZero(v) {
+		return true, o
+	}
+	if c.o.zero == ZeroRejected {
+		return false, o
+	}
+	// Only the lifetime differs, the rest (e.g., the origin) applies.
+	o.ttl, o.ttlSet = c.o.negativeTTL, true
+	o.until = time.Time{}
+	return true, o
+}
+
+func isZero[V any](v V) bool {
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
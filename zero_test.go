@@ -0,0 +1,61 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/antichris/go-cache"
+)
+
+func TestZeroValues(t *testing.T) {
+	zero := SimpleGetterFunc[string, []int](func() []int { return nil })
+	one := SimpleGetterFunc[string, []int](func() []int { return []int{1} })
+
+	c := New[string, []int](time.Minute)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+	req.GetOrPut("a", zero)
+	req.Has("a")
+
+	c = New[string, []int](time.Minute, WithZeroValues(ZeroRejected))
+	defer c.Shutdown()
+	req = newAssert(t, c, true)
+	req.GetOrPutNot("a", zero)
+	req.HasNot("a")
+	req.GetOrPut("b", one)
+	req.Has("b")
+
+	c = New[string, []int](time.Minute,
+		WithZeroValues(ZeroNegative),
+		WithNegativeTTL(ttl),
+	)
+	defer c.Shutdown()
+	req = newAssert(t, c, true)
+	req.GetOrPut("a", zero)
+	req.GetOrPut("b", one)
+	req.Has("a")
+	time.Sleep(3 * ttl / 2)
+	req.HasNot("a")
+	req.Has("b")
+}
+
+func TestZeroNegativeKeepsOptions(t *testing.T) {
+	zero := SimpleGetterFunc[string, []int](func() []int { return nil })
+	c := New[string, []int](time.Minute,
+		WithZeroValues(ZeroNegative),
+		WithNegativeTTL(ttl),
+	)
+	defer c.Shutdown()
+	req := newAssert(t, c, true)
+
+	c.GetOrPut("a", zero, Origin("billing"), TTL(time.Hour))
+	e, ok := c.GetEntry("a")
+	req.Assert(ok && e.Origin == "billing",
+		"GetEntry(a) got origin %q, want billing", e.Origin)
+	req.Assert(e.TTL == ttl, "GetEntry(a) got TTL %v, want the negative %v",
+		e.TTL, ttl)
+}